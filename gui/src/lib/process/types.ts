@@ -8,6 +8,10 @@ export interface ProcessDescription {
   createTime: null | number;
   residentMemory: null | number;
   childrenExecutables: null | string[];
+  memoryUsage: null | number;
+  cpuUsageSeconds: null | number;
+  pids: null | number;
+  oomKills: null | number;
 }
 
 export interface CreateProcessResponse {
@@ -2,38 +2,56 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/api"
+	coreapi "github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/manifest"
+	composeimport "github.com/deref/exo/internal/manifest/compose"
+	"github.com/deref/exo/internal/util/osutil"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(applyCmd)
-	applyCmd.Flags().StringVar(&applyFlags.Format, "format", "", "exo, compose, procfile")
+	applyCmd.Flags().StringVar(&applyFlags.Format, "format", "", "cue, exohcl (or exo), compose, procfile, k8s")
+	applyCmd.Flags().BoolVar(&applyFlags.DryRun, "dry-run", false, "print the planned changes without applying them")
+	applyCmd.Flags().StringSliceVar(&applyFlags.Profiles, "profile", nil, "activate a profile; may be repeated")
+	applyCmd.Flags().StringArrayVarP(&applyFlags.Files, "file", "f", nil, "manifest file; may be repeated for compose files")
+	applyCmd.Flags().StringArrayVar(&applyFlags.Variables, "var", nil, "set a manifest variable as name=value; may be repeated")
 }
 
 var applyFlags struct {
-	Format string
+	Format    string
+	DryRun    bool
+	Profiles  []string
+	Files     []string
+	Variables []string
 }
 
 var applyCmd = &cobra.Command{
 	Use:   "apply [flags] [manifest-file]",
 	Short: "Applies a manifest in the current workspace",
 	Long: `Applies a manifest in the current workspace.
-	
+
+Components in the manifest are created or re-created in the workspace, and
+components that are absent from the manifest are deleted. The resulting job is
+watched until it completes.
+
 If no manifest file is specified, a search is conducted in the current
 directory in the following order of format preference:
 
-	1. exo (Cue)
-	2. exo (Legacy: HCL)
+	1. cue
+	2. exohcl
 	3. compose
 	4. procfile
 
-The default Exo filename is 'exo.cue' and legacy Exo files have filename
-'exo.hcl'.
+Exo manifests have filename 'exo.cue' or, for HCL, 'exo.hcl'.
 
 Compose files may have one of the following names in order of preference:
 
@@ -44,46 +62,254 @@ Compose files may have one of the following names in order of preference:
 
 The expected procfile name 'Procfile'.
 
+Kubernetes manifests are never found by searching, but files named with a
+'.k8s.yaml' or '.k8s.yml' suffix are recognized. Otherwise, use --format=k8s.
+
 If a manifest format will be guessed from the manifest filename.  This can be
-overidden explicitly with the --format flag.`,
+overidden explicitly with the --format flag.
+
+Compose projects may span multiple files given with repeated -f flags. Each
+file is merged in to the files before it, following the Compose specification.
+When a compose file is found by searching, an override file beside it, such as
+'docker-compose.override.yml', is merged in automatically. Services may use
+'extends' to inherit from services in the same or other files.
+
+Components may be assigned to profiles, such as with the 'profiles' key of
+compose services. Components outside the active profiles are created, but not
+started, including when they were started by a previous apply. Profiles are
+activated with --profile, which defaults to the profiles configured with
+'exo workspace profiles'. Components without profiles are always started.
+
+Exo manifests may declare variables with 'variable' blocks. A variable's
+default is overridden by an EXO_VAR_<name> environment variable, which is in
+turn overridden by --var <name>=<value>.
+
+With --dry-run, the components of the current workspace are compared to the
+manifest and the planned creates, updates, renames and deletes are printed.
+Nothing is changed.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
-
 		cl := newClient()
 		kernel := cl.Kernel()
 		workspace := requireCurrentWorkspace(ctx, cl)
 
-		return apply(ctx, kernel, workspace, args)
+		format := manifestFormatFlag()
+		manifestPaths := manifestArgs(args)
+		if len(manifestPaths) == 0 {
+			manifestPath := findManifest(format)
+			if manifestPath == "" {
+				return errors.New("could not find manifest file")
+			}
+			manifestPaths = withComposeOverride(format, manifestPath)
+		}
+		if applyFlags.DryRun {
+			return printApplyPlan(ctx, workspace, manifestPaths)
+		}
+		return apply(ctx, kernel, workspace, manifestPaths)
 	},
 }
 
-func apply(ctx context.Context, kernel api.Kernel, workspace api.Workspace, args []string) error {
-	input := &api.ApplyInput{
-		Format: applyFlags.Format,
+// Returns the format given with --format. For compatibility with the legacy
+// workspace API, "exo" is accepted as a synonym for "exohcl".
+func manifestFormatFlag() string {
+	if applyFlags.Format == "exo" {
+		return "exohcl"
+	}
+	return applyFlags.Format
+}
+
+type manifestCandidate struct {
+	Format   string
+	Filename string
+}
+
+var manifestCandidates = []manifestCandidate{
+	{"cue", "exo.cue"},
+	{"exohcl", "exo.hcl"},
+	{"compose", "compose.yaml"},
+	{"compose", "compose.yml"},
+	{"compose", "docker-compose.yaml"},
+	{"compose", "docker-compose.yml"},
+	{"procfile", "Procfile"},
+}
+
+func findManifest(format string) string {
+	for _, candidate := range manifestCandidates {
+		if format != "" && format != candidate.Format {
+			continue
+		}
+		if exists, _ := osutil.Exists(candidate.Filename); exists {
+			return candidate.Filename
+		}
+	}
+	return ""
+}
+
+// Returns the manifest files given with -f and as an argument.
+func manifestArgs(args []string) []string {
+	return append(append([]string{}, applyFlags.Files...), args...)
+}
+
+// Returns the given manifest, along with its compose override file, if the
+// manifest is a compose file and the override file exists.
+func withComposeOverride(format string, manifestPath string) []string {
+	if format == "" {
+		format = guessManifestFormat(manifestPath)
+	}
+	if format != "compose" {
+		return []string{manifestPath}
+	}
+	overridePath := composeimport.OverrideFilename(manifestPath)
+	if exists, _ := osutil.Exists(overridePath); exists {
+		return []string{manifestPath, overridePath}
+	}
+	return []string{manifestPath}
+}
+
+// Reads the manifests at the given paths. Only compose manifests may span
+// multiple files, which are merged in to a single manifest. If format is
+// empty, it is guessed from the first path.
+func readManifests(format string, paths []string) (string, []byte, error) {
+	if format == "" {
+		format = guessManifestFormat(paths[0])
+	}
+	if format == "compose" {
+		loader := &composeimport.Loader{
+			ReadFile: ioutil.ReadFile,
+		}
+		bs, err := loader.Load(paths...)
+		if err != nil {
+			return "", nil, fmt.Errorf("loading compose files: %w", err)
+		}
+		return format, bs, nil
+	}
+	if len(paths) > 1 {
+		return "", nil, fmt.Errorf("multiple manifest files are only supported for the compose format, got %s", format)
+	}
+	bs, err := ioutil.ReadFile(paths[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("reading manifest file: %w", err)
+	}
+	return format, bs, nil
+}
+
+func guessManifestFormat(path string) string {
+	switch format := manifest.GuessFormat(path); format {
+	case "exo", "":
+		return "exohcl"
+	default:
+		return format
+	}
+}
+
+// Applies a manifest through the workspace API, which supervises processes,
+// so that readiness checks, restart policies, file watches, terminals, and
+// resource limits take effect. Stack processes do not yet support these, so
+// manifests are not applied with the applyManifest mutation. If no manifest
+// paths are given, the workspace searches its root for a manifest.
+func apply(ctx context.Context, kernel coreapi.Kernel, workspace coreapi.Workspace, manifestPaths []string) error {
+	input, err := newApplyInput(ctx, manifestPaths)
+	if err != nil {
+		return err
+	}
+	output, err := workspace.Apply(ctx, input)
+	printApplyWarnings(output)
+	if err != nil {
+		return err
+	}
+	return watchJob(ctx, output.JobID)
+}
+
+func newApplyInput(ctx context.Context, manifestPaths []string) (*coreapi.ApplyInput, error) {
+	profiles, err := activeProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolving profiles: %w", err)
+	}
+	variables, err := manifestVariables()
+	if err != nil {
+		return nil, err
+	}
+	input := &coreapi.ApplyInput{
+		Format:    manifestFormatFlag(),
+		Profiles:  profiles,
+		Variables: variables,
+	}
+	if input.Format == "exohcl" {
+		// The legacy API calls HCL manifests "exo".
+		input.Format = "exo"
 	}
-	if len(args) > 0 {
-		manifestPath := args[0]
+	if len(manifestPaths) > 0 {
+		// Relative paths in the manifest are resolved by the daemon, which does
+		// not share the working directory of the CLI.
+		manifestPath, err := filepath.Abs(manifestPaths[0])
+		if err != nil {
+			return nil, fmt.Errorf("resolving manifest path: %w", err)
+		}
 		input.ManifestPath = &manifestPath
 
 		// We're not necessarily in the workspace root here,
 		// so send the file contents too.
-		bs, err := ioutil.ReadFile(manifestPath)
+		_, bs, err := readManifests(manifestFormatFlag(), manifestPaths)
 		if err != nil {
-			return fmt.Errorf("reading manifest file: %w", err)
+			return nil, err
 		}
 		s := string(bs)
 		input.Manifest = &s
 	}
+	return input, nil
+}
 
-	output, err := workspace.Apply(ctx, input)
-	if output != nil {
-		for _, warning := range output.Warnings {
-			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+const manifestVariableEnvPrefix = "EXO_VAR_"
+
+// Returns the values of manifest variables given in the environment and with
+// --var. Flags take precedence over the environment.
+func manifestVariables() (map[string]string, error) {
+	variables := make(map[string]string)
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, manifestVariableEnvPrefix) {
+			variables[strings.TrimPrefix(name, manifestVariableEnvPrefix)] = value
 		}
 	}
-	if err != nil {
-		return err
+	for _, flag := range applyFlags.Variables {
+		name, value, ok := strings.Cut(flag, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --var %q: expected name=value", flag)
+		}
+		variables[name] = value
+	}
+	return variables, nil
+}
+
+// Returns the profiles given with --profile, or else the profiles of the
+// current workspace. The legacy workspace API does not know about workspace
+// profiles, so they must be resolved by the client.
+func activeProfiles(ctx context.Context) ([]string, error) {
+	if applyFlags.Profiles != nil {
+		return applyFlags.Profiles, nil
+	}
+	var q struct {
+		Workspace *struct {
+			Profiles []string
+		} `graphql:"workspaceByRef(ref: $currentWorkspace)"`
+	}
+	if err := api.Query(ctx, svc, &q, map[string]any{
+		"currentWorkspace": currentWorkspaceRef(),
+	}); err != nil {
+		return nil, err
+	}
+	if q.Workspace == nil {
+		return nil, nil
+	}
+	return q.Workspace.Profiles, nil
+}
+
+func printApplyWarnings(output *coreapi.ApplyOutput) {
+	if output == nil {
+		return
+	}
+	for _, warning := range output.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
 	}
-	return watchJob(ctx, output.JobID)
 }
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/util/term"
+	"github.com/deref/rgbterm"
+)
+
+func printApplyPlan(ctx context.Context, workspace api.Workspace, manifestPaths []string) error {
+	input, err := newApplyInput(ctx, manifestPaths)
+	if err != nil {
+		return err
+	}
+	input.DryRun = true
+	output, err := workspace.Apply(ctx, input)
+	printApplyWarnings(output)
+	if err != nil {
+		return err
+	}
+
+	if len(output.Plan) == 0 {
+		fmt.Println("No changes.")
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, change := range output.Plan {
+		counts[change.Action]++
+		switch change.Action {
+		case "create":
+			fmt.Println(colorPlanLine("+ "+change.Name, planCreateColor))
+		case "delete":
+			fmt.Println(colorPlanLine("- "+change.Name, planDeleteColor))
+		case "rename":
+			fmt.Println(colorPlanLine(fmt.Sprintf("~ %s -> %s", *change.OldName, change.Name), planUpdateColor))
+		case "update":
+			if change.SpecDiff == "" {
+				fmt.Println(colorPlanLine("~ "+change.Name+" (recreated, spec unchanged)", planUpdateColor))
+				continue
+			}
+			fmt.Println(colorPlanLine("~ "+change.Name, planUpdateColor))
+			for _, line := range strings.Split(change.SpecDiff, "\n") {
+				switch {
+				case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+					continue
+				case strings.HasPrefix(line, "+"):
+					line = colorPlanLine(line, planCreateColor)
+				case strings.HasPrefix(line, "-"):
+					line = colorPlanLine(line, planDeleteColor)
+				}
+				fmt.Println("    " + line)
+			}
+		default:
+			fmt.Printf("? %s (%s)\n", change.Name, change.Action)
+		}
+	}
+	fmt.Printf("\nPlan: %d to create, %d to update, %d to rename, %d to delete.\n",
+		counts["create"], counts["update"], counts["rename"], counts["delete"])
+	return nil
+}
+
+type planColor struct {
+	R, G, B uint8
+}
+
+var (
+	planCreateColor = planColor{0, 205, 0}
+	planUpdateColor = planColor{205, 205, 0}
+	planDeleteColor = planColor{205, 0, 0}
+)
+
+func colorPlanLine(s string, c planColor) string {
+	if !useColor() {
+		return s
+	}
+	return rgbterm.FgString(s, c.R, c.G, c.B) + term.ResetCode
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/deref/exo/internal/supervise"
+	"github.com/deref/exo/internal/util/cmdutil"
+	"github.com/deref/exo/internal/util/term"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/websocket"
+)
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+}
+
+// Ctrl-], as in telnet.
+const attachDetachKey = 0x1d
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <ref>",
+	Short: "Attach to a process terminal",
+	Long: `Connects the current terminal to that of a running process.
+
+The process must have been started with a pseudo-terminal, by setting
+tty = true in its manifest. Input is sent to the process, and changes to the
+window size are forwarded to it.
+
+Only processes of workspaces started with 'exo run' or 'exo init' can be
+attached to. Processes created by 'exo apply' do not run under the process
+supervisor, so they have no terminal and tty is ignored.
+
+Press Ctrl-] to detach. The process continues to run.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !term.IsInteractive() {
+			return errors.New("attach requires an interactive terminal")
+		}
+		checkOrEnsureServer()
+
+		ws, err := dialAttach(currentWorkspaceRef(), args[0])
+		if err != nil {
+			return fmt.Errorf("attaching to %q: %w", args[0], err)
+		}
+		defer ws.Close()
+
+		beginExclusive()
+		defer endExclusive()
+
+		raw := &term.RawMode{}
+		if err := raw.Enter(); err != nil {
+			return fmt.Errorf("entering raw mode: %w", err)
+		}
+		defer func() {
+			if err := raw.Exit(); err != nil {
+				cmdutil.Fatalf("restoring terminal state: %w", err)
+			}
+		}()
+
+		var mu sync.Mutex
+		send := func(msg supervise.AttachMessage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return supervise.WriteAttachMessage(ws, msg)
+		}
+
+		sendSize := func() {
+			cols, rows := term.GetSize()
+			if cols > 0 && rows > 0 {
+				_ = send(supervise.AttachMessage{
+					Type: supervise.AttachResize,
+					Rows: uint16(rows),
+					Cols: uint16(cols),
+				})
+			}
+		}
+		winch := make(chan os.Signal, 1)
+		signal.Notify(winch, syscall.SIGWINCH)
+		defer signal.Stop(winch)
+		sendSize()
+		go func() {
+			for range winch {
+				sendSize()
+			}
+		}()
+
+		go func() {
+			defer ws.Close()
+			buf := make([]byte, 4096)
+			for {
+				n, err := os.Stdin.Read(buf)
+				input := buf[:n]
+				detach := false
+				if i := bytes.IndexByte(input, attachDetachKey); i >= 0 {
+					input = input[:i]
+					detach = true
+				}
+				if len(input) > 0 {
+					if err := send(supervise.AttachMessage{Type: supervise.AttachData, Data: input}); err != nil {
+						return
+					}
+				}
+				if detach || err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			msg, err := supervise.ReadAttachMessage(ws)
+			if err != nil {
+				// The connection is closed by detaching, or when the process exits.
+				return nil
+			}
+			if _, err := os.Stdout.Write(msg.Data); err != nil {
+				return err
+			}
+		}
+	},
+}
+
+func dialAttach(workspaceRef, componentRef string) (*websocket.Conn, error) {
+	origin := strings.TrimSuffix(effectiveServerURL(), "/")
+	query := url.Values{
+		"workspace": {workspaceRef},
+		"component": {componentRef},
+	}
+	location := "ws" + strings.TrimPrefix(origin, "http") + "/_exo/attach?" + query.Encode()
+	wsCfg, err := websocket.NewConfig(location, origin)
+	if err != nil {
+		return nil, err
+	}
+	wsCfg.Header.Set("Authorization", "Bearer "+mustGetToken())
+	ws, err := websocket.DialConfig(wsCfg)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
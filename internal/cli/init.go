@@ -71,7 +71,7 @@ Explore the CLI interface:
 		} else {
 			// Apply an existing manifest.
 			fmt.Println("Applying existing manifest...")
-			if err := apply(ctx, kernel, workspace, []string{}); err != nil {
+			if err := apply(ctx, kernel, workspace, nil); err != nil {
 				return fmt.Errorf("applying manifest: %w", err)
 			}
 
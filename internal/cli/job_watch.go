@@ -69,7 +69,7 @@ func watchJob(ctx context.Context, jobID string) error {
 	defer sub.Stop()
 
 	{
-		stopSignals := make(chan os.Signal)
+		stopSignals := make(chan os.Signal, 1)
 		signal.Notify(stopSignals, os.Interrupt, syscall.SIGTERM)
 		go func() {
 			<-stopSignals
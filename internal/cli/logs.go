@@ -20,12 +20,14 @@ import (
 func init() {
 	rootCmd.AddCommand(logsCmd)
 	logsCmd.Flags().BoolVarP(&logFlags.NoFollow, "no-follow", "", false, "stops tailing when caught up to the end of the log")
+	logsCmd.Flags().StringVar(&logFlags.Filter, "filter", "", "only show events with matching tags, such as 'level>=warn'")
 	// TODO: Flag for including system events.
 	// TODO: Elsewhere, add a dedicated command for viewing _just_ system events.
 }
 
 var logFlags struct {
 	NoFollow bool
+	Filter   string
 }
 
 var logsCmd = &cobra.Command{
@@ -34,7 +36,13 @@ var logsCmd = &cobra.Command{
 	Short:  "Tails process logs",
 	Long: `Tails and follows process logs.
 
-If refs are provided, filters for the logs of those processes.`,
+If refs are provided, filters for the logs of those processes.
+
+Processes with a structured log format record the fields of each line as tags.
+The --filter flag accepts space separated conditions on those tags, using the
+operators =, !=, <, <=, >, and >=. Levels are compared by severity:
+
+  exo logs --filter 'level>=warn request_id=abc'`,
 	DisableFlagsInUseLine: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -151,8 +159,9 @@ func runTailLogsWriter(ctx context.Context, workspace *joshclient.Workspace, str
 
 	limit := 500
 	in := &api.GetEventsInput{
-		Streams: streamNames,
-		Prev:    &limit,
+		Streams:   streamNames,
+		TagFilter: logFlags.Filter,
+		Prev:      &limit,
 	}
 	for {
 		output, err := workspace.GetEvents(ctx, in)
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/deref/exo/internal/chrono"
+	"github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/util/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	logsCmd.AddCommand(logsSearchCmd)
+	logsSearchCmd.Flags().StringVar(&logsSearchFlags.Since, "since", "", "only search events after a time or duration ago, such as '2021-10-01T12:00:00Z' or '1h'")
+	logsSearchCmd.Flags().StringVar(&logsSearchFlags.Until, "until", "", "only search events before a time or duration ago")
+	logsSearchCmd.Flags().IntVarP(&logsSearchFlags.Context, "context", "C", 0, "number of events to show before and after each match")
+	logsSearchCmd.Flags().IntVar(&logsSearchFlags.Limit, "limit", 100, "maximum number of matches to show")
+}
+
+var logsSearchFlags struct {
+	Since   string
+	Until   string
+	Context int
+	Limit   int
+}
+
+var logsSearchCmd = &cobra.Command{
+	Use:   "search <query> [refs...]",
+	Short: "Searches logs",
+	Long: `Searches the logs of all components in the workspace, or of only the
+components identified by refs, and prints the most recent matches.
+
+Words match whole words, case-insensitively. A trailing * matches a prefix.
+Quoted phrases match consecutive words, and terms delimited by slashes are
+regular expressions. Terms may be combined with AND, OR, NOT, and parentheses.
+Adjacent terms must all match.
+
+Examples:
+
+  exo logs search timeout
+  exo logs search 'conn* AND NOT "connection reset"' api worker
+  exo logs search -C 3 --since 1h '/status=5\d\d/'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		cl := newClient()
+		workspace := requireCurrentWorkspace(ctx, cl)
+
+		input := &api.SearchEventsInput{
+			Query:   args[0],
+			Context: logsSearchFlags.Context,
+			Limit:   &logsSearchFlags.Limit,
+		}
+		var err error
+		if input.Since, err = parseTimeFlag("since", logsSearchFlags.Since); err != nil {
+			return err
+		}
+		if input.Until, err = parseTimeFlag("until", logsSearchFlags.Until); err != nil {
+			return err
+		}
+
+		components, err := workspace.DescribeComponents(ctx, &api.DescribeComponentsInput{
+			Refs: args[1:],
+		})
+		if err != nil {
+			return fmt.Errorf("describing components: %w", err)
+		}
+		streamToLabel := make(map[string]string, 1+len(components.Components))
+		streamToLabel[workspace.ID()] = "EXO"
+		for _, component := range components.Components {
+			streamToLabel[component.ID] = component.Name
+		}
+		if len(args) > 1 {
+			input.Streams = make([]string, 0, len(components.Components))
+			for _, component := range components.Components {
+				input.Streams = append(input.Streams, component.ID)
+			}
+		}
+
+		output, err := workspace.SearchEvents(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		w := &EventWriter{
+			W: os.Stdout,
+		}
+		w.Init()
+		printEvent := func(event api.Event) {
+			t, err := time.Parse(chrono.RFC3339NanoUTC, event.Timestamp)
+			if err != nil {
+				cmdutil.Warnf("invalid event timestamp: %q", event.Timestamp)
+				return
+			}
+			label := streamToLabel[event.Stream]
+			if label == "" {
+				label = event.Stream
+			}
+			w.PrintEvent(event.Stream, t, label, event.Message)
+		}
+
+		// Context of nearby matches may overlap, so events are printed at most
+		// once. As with grep, non-adjacent groups are separated by "--".
+		printed := make(map[string]bool)
+		lastPrinted := ""
+		for _, match := range output.Matches {
+			group := append(append(match.Before, match.Event), match.After...)
+			for i, event := range group {
+				if printed[event.ID] {
+					continue
+				}
+				if input.Context > 0 && lastPrinted != "" && (i == 0 || group[i-1].ID != lastPrinted) {
+					fmt.Fprint(w.W, "--\r\n")
+				}
+				printEvent(event)
+				printed[event.ID] = true
+				lastPrinted = event.ID
+			}
+		}
+
+		if output.Truncated {
+			cmdutil.Warnf("showing only the most recent %d matches", len(output.Matches))
+		}
+		return nil
+	},
+}
+
+// Parses a flag value that is either an RFC 3339 time, or a duration before
+// the current time.
+func parseTimeFlag(name string, value string) (*string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var t time.Time
+	if d, err := time.ParseDuration(value); err == nil {
+		t = time.Now().Add(-d)
+	} else if t, err = time.Parse(time.RFC3339Nano, value); err != nil {
+		return nil, fmt.Errorf("invalid --%s: expected a time, such as 2021-10-01T12:00:00Z, or a duration, such as 1h", name)
+	}
+	s := chrono.IsoNano(t.UTC())
+	return &s, nil
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/logsink"
+	"github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	logsCmd.AddCommand(logsSinksCmd)
+}
+
+var logsSinksCmd = &cobra.Command{
+	Use:   "sinks",
+	Short: "Lists log sinks of the workspace",
+	Long: `Lists the sinks that the current workspace's events are exported to.
+
+Sinks for the events of all workspaces are configured in the log section of
+the exo config file.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		sinks := mustQueryLogSinks(ctx)
+		w := cmdutil.NewTableWriter("#", "TYPE", "DESTINATION")
+		for i, sink := range sinks {
+			w.WriteRow(strconv.Itoa(i+1), sink.Type, sink.destination())
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// Named for the GraphQL input type of setWorkspaceLogSinks, but also selects
+// the fields of LogSink.
+type LogSinkInput struct {
+	Type     string              `json:"type"`
+	Endpoint *string             `json:"endpoint"`
+	Headers  *scalars.JSONObject `json:"headers"`
+	Path     *string             `json:"path"`
+	MaxBytes *int                `json:"maxBytes"`
+	MaxFiles *int                `json:"maxFiles"`
+}
+
+func (sink LogSinkInput) destination() string {
+	switch {
+	case sink.Endpoint != nil:
+		return *sink.Endpoint
+	case sink.Path != nil:
+		return *sink.Path
+	case sink.Type == "otlp":
+		return logsink.DefaultOTLPEndpoint
+	default:
+		return ""
+	}
+}
+
+func mustQueryLogSinks(ctx context.Context) []LogSinkInput {
+	var q struct {
+		Workspace *struct {
+			LogSinks []LogSinkInput
+		} `graphql:"workspaceByRef(ref: $currentWorkspace)"`
+	}
+	mustQueryWorkspace(ctx, &q, nil)
+	return q.Workspace.LogSinks
+}
+
+func setLogSinks(ctx context.Context, sinks []LogSinkInput) error {
+	var m struct {
+		Workspace struct {
+			ID string
+		} `graphql:"setWorkspaceLogSinks(workspace: $workspace, sinks: $sinks)"`
+	}
+	if err := api.Mutate(ctx, svc, &m, map[string]any{
+		"workspace": currentWorkspaceRef(),
+		"sinks":     sinks,
+	}); err != nil {
+		return fmt.Errorf("setting log sinks: %w", err)
+	}
+	return nil
+}
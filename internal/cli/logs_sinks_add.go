@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deref/exo/internal/scalars"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	logsSinksCmd.AddCommand(logsSinksAddCmd)
+	logsSinksAddCmd.Flags().StringVar(&logsSinksAddFlags.Endpoint, "endpoint", "", "OTLP/HTTP logs endpoint")
+	logsSinksAddCmd.Flags().StringArrayVar(&logsSinksAddFlags.Headers, "header", nil, "header sent to the OTLP endpoint, as name=value")
+	logsSinksAddCmd.Flags().StringVar(&logsSinksAddFlags.Path, "path", "", "path of the JSON-lines file, relative to the workspace root")
+	logsSinksAddCmd.Flags().IntVar(&logsSinksAddFlags.MaxBytes, "max-bytes", 0, "size in bytes at which the file is rotated")
+	logsSinksAddCmd.Flags().IntVar(&logsSinksAddFlags.MaxFiles, "max-files", 0, "number of rotated files to keep")
+}
+
+var logsSinksAddFlags struct {
+	Endpoint string
+	Headers  []string
+	Path     string
+	MaxBytes int
+	MaxFiles int
+}
+
+var logsSinksAddCmd = &cobra.Command{
+	Use:   "add <type>",
+	Short: "Adds a log sink to the workspace",
+	Long: `Exports the current workspace's events to a new sink. The type is one of:
+
+  otlp    An OpenTelemetry collector, via OTLP/HTTP. Component, stack, and
+          workspace IDs are sent as resource attributes.
+  file    A JSON-lines file, rotated when it reaches --max-bytes.
+  stdout  JSON lines written to the daemon's standard output.
+
+Examples:
+
+  exo logs sinks add otlp --endpoint http://localhost:4318/v1/logs
+  exo logs sinks add file --path logs/exo.jsonl --max-files 3`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		sink := LogSinkInput{
+			Type: args[0],
+		}
+		if logsSinksAddFlags.Endpoint != "" {
+			sink.Endpoint = &logsSinksAddFlags.Endpoint
+		}
+		if len(logsSinksAddFlags.Headers) > 0 {
+			headers := make(scalars.JSONObject, len(logsSinksAddFlags.Headers))
+			for _, header := range logsSinksAddFlags.Headers {
+				name, value, ok := strings.Cut(header, "=")
+				if !ok {
+					return fmt.Errorf("invalid --header %q: expected name=value", header)
+				}
+				headers[name] = value
+			}
+			sink.Headers = &headers
+		}
+		if logsSinksAddFlags.Path != "" {
+			sink.Path = &logsSinksAddFlags.Path
+		}
+		if logsSinksAddFlags.MaxBytes != 0 {
+			sink.MaxBytes = &logsSinksAddFlags.MaxBytes
+		}
+		if logsSinksAddFlags.MaxFiles != 0 {
+			sink.MaxFiles = &logsSinksAddFlags.MaxFiles
+		}
+		sinks := append(mustQueryLogSinks(ctx), sink)
+		return setLogSinks(ctx, sinks)
+	},
+}
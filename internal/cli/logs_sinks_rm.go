@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	logsSinksCmd.AddCommand(logsSinksRMCmd)
+}
+
+var logsSinksRMCmd = &cobra.Command{
+	Use:   "rm <number>",
+	Short: "Removes a log sink from the workspace",
+	Long: `Removes a log sink from the current workspace, identified by its number in
+the output of 'exo logs sinks'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		sinks := mustQueryLogSinks(ctx)
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(sinks) {
+			return fmt.Errorf("no such log sink: %q", args[0])
+		}
+		sinks = append(sinks[:n-1], sinks[n:]...)
+		return setLogSinks(ctx, sinks)
+	},
+}
@@ -10,7 +10,7 @@ func init() {
 }
 
 func addManifestFormatFlag(cmd *cobra.Command, p *string) {
-	cmd.Flags().StringVar(p, "format", "", "exo, exohcl, compose, procfile")
+	cmd.Flags().StringVar(p, "format", "", "exo, exohcl, compose, procfile, k8s")
 }
 
 var manifestCmd = &cobra.Command{
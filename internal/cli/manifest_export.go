@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	manifestCmd.AddCommand(manifestExportCmd)
+	manifestExportCmd.Flags().StringVar(&manifestExportFlags.Format, "format", "compose", "compose, procfile")
+}
+
+var manifestExportFlags struct {
+	Format string
+}
+
+var manifestExportCmd = &cobra.Command{
+	Use:   "export [flags]",
+	Short: "Exports the workspace as a manifest",
+	Long: `Exports the components of the current workspace as a manifest of the given
+format and prints it to stdout.
+
+The compose format includes containers, volumes, and networks. Since compose
+has no equivalent of processes, they are exported to an 'x-exo' extension,
+which compose ignores.
+
+The procfile format includes only processes.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		switch manifestExportFlags.Format {
+		case "compose", "procfile":
+		default:
+			return fmt.Errorf("unsupported export format: %q", manifestExportFlags.Format)
+		}
+
+		var q struct {
+			Stack *struct {
+				ExportManifest string `graphql:"exportManifest(format: $format)"`
+			} `graphql:"stackByRef(ref: $currentStack)"`
+		}
+		mustQueryStack(ctx, &q, map[string]any{
+			"format": manifestExportFlags.Format,
+		})
+		fmt.Print(q.Stack.ExportManifest)
+		return nil
+	},
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/cmdutil"
+	"github.com/deref/exo/internal/util/term"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+	metricsCmd.Flags().DurationVar(&metricsFlags.Since, "since", time.Hour, "how far back to show usage")
+	metricsCmd.Flags().IntVar(&metricsFlags.Resolution, "resolution", 0, "seconds of usage per bar; defaults to fitting the terminal width")
+}
+
+var metricsFlags struct {
+	Since      time.Duration
+	Resolution int
+}
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics <ref>",
+	Short: "Show resource usage of a process over time",
+	Long: `Plots the CPU, memory, network, and disk usage of a process component, as
+recorded by the daemon. Usage of child processes is included. Time runs from left
+to right, and gaps are periods where the process was not running.
+
+CPU and throughput are averaged over the time covered by each bar. Memory is the
+peak within each bar, so memory that grows steadily over a session is a sign of
+a leak.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		ref := args[0]
+		if metricsFlags.Since <= 0 {
+			return errors.New("--since must be positive")
+		}
+		if metricsFlags.Resolution < 0 {
+			return errors.New("--resolution must not be negative")
+		}
+
+		bars := metricsPlotWidth()
+		resolution := metricsFlags.Resolution
+		if resolution == 0 {
+			resolution = int(math.Ceil(metricsFlags.Since.Seconds() / float64(bars)))
+		}
+		if n := int(math.Ceil(metricsFlags.Since.Seconds() / float64(resolution))); n < bars {
+			bars = n
+		}
+		span := time.Duration(resolution) * time.Second
+		start := time.Now().Add(-time.Duration(bars) * span).Truncate(span)
+
+		var q struct {
+			Component *struct {
+				AsProcess *struct {
+					Metrics []metricsSample `graphql:"metrics(since: $since, resolution: $resolution)"`
+				}
+			} `graphql:"componentByRef(ref: $ref, stack: $stack)"`
+		}
+		if err := api.Query(ctx, svc, &q, map[string]any{
+			"ref":        ref,
+			"stack":      currentStackRef(),
+			"since":      scalars.GoTimeToInstant(start),
+			"resolution": resolution,
+		}); err != nil {
+			return err
+		}
+		if q.Component == nil {
+			return fmt.Errorf("no such component: %q", ref)
+		}
+		if q.Component.AsProcess == nil {
+			return fmt.Errorf("component %q is not a process", ref)
+		}
+		samples := q.Component.AsProcess.Metrics
+		if len(samples) == 0 {
+			fmt.Println("No usage recorded.")
+			return nil
+		}
+
+		w := cmdutil.NewTableWriter("METRIC", "USAGE", "MIN", "MAX", "LAST")
+		for _, series := range metricsSeries {
+			columns := make([]float64, bars+1)
+			for i := range columns {
+				columns[i] = math.NaN()
+			}
+			var values []float64
+			for _, sample := range samples {
+				v := series.Value(&sample)
+				if v == nil {
+					continue
+				}
+				values = append(values, *v)
+				i := int(sample.Timestamp.GoTime().Sub(start) / span)
+				if i < 0 {
+					i = 0
+				} else if i >= len(columns) {
+					i = len(columns) - 1
+				}
+				columns[i] = *v
+			}
+			if len(values) == 0 {
+				continue
+			}
+			min, max := values[0], values[0]
+			for _, v := range values {
+				min = math.Min(min, v)
+				max = math.Max(max, v)
+			}
+			last := values[len(values)-1]
+			w.WriteRow(series.Label, term.Sparkline(columns), series.Format(min), series.Format(max), series.Format(last))
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+type metricsSample struct {
+	Timestamp           scalars.Instant
+	CPUPercent          float64
+	ResidentBytes       float64
+	NetworkReceiveRate  *float64
+	NetworkTransmitRate *float64
+	DiskReadRate        *float64
+	DiskWriteRate       *float64
+}
+
+var metricsSeries = []struct {
+	Label  string
+	Value  func(sample *metricsSample) *float64
+	Format func(v float64) string
+}{
+	{"cpu", func(s *metricsSample) *float64 { return &s.CPUPercent }, formatPercent},
+	{"memory", func(s *metricsSample) *float64 { return &s.ResidentBytes }, units.BytesSize},
+	{"net in", func(s *metricsSample) *float64 { return s.NetworkReceiveRate }, formatByteRate},
+	{"net out", func(s *metricsSample) *float64 { return s.NetworkTransmitRate }, formatByteRate},
+	{"disk read", func(s *metricsSample) *float64 { return s.DiskReadRate }, formatByteRate},
+	{"disk write", func(s *metricsSample) *float64 { return s.DiskWriteRate }, formatByteRate},
+}
+
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+func formatByteRate(v float64) string {
+	return units.BytesSize(v) + "/s"
+}
+
+// Number of bars that fit alongside the other columns of the table.
+func metricsPlotWidth() int {
+	const otherColumns = 60
+	w, _ := term.GetSize()
+	if w == 0 {
+		return 60
+	}
+	if w-otherColumns < 10 {
+		return 10
+	}
+	return w - otherColumns
+}
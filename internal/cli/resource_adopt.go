@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/util/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	resourceCmd.AddCommand(resourceAdoptCmd)
+	resourceAdoptCmd.Flags().StringVar(&resourceAdoptFlags.Owner, "owner", "stack", "component, stack, project, or none")
+	resourceAdoptCmd.Flags().StringVar(&resourceAdoptFlags.Component, "component", "", "component ref")
+}
+
+var resourceAdoptFlags struct {
+	Owner     string
+	Component string
+}
+
+var resourceAdoptCmd = &cobra.Command{
+	Use:   "adopt <type> <iri>",
+	Short: "Track an existing external resource",
+	Long: `Track an existing external resource without recreating it.
+
+The resource controller locates the external resource by IRI. Controllers also
+accept shorthand references in place of an IRI:
+
+  deref.io/docker/container   container name or ID
+  deref.io/docker/volume      volume name
+  deref.io/docker/network     network name or ID
+  process                     process id
+
+For example, to adopt a hand-started Postgres container:
+
+  exo resource adopt deref.io/docker/container postgres
+
+After adoption, the resource model is read from the external resource.
+
+Ownership is assigned as with 'exo resource new'.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		var m struct {
+			Resource struct {
+				ID     string
+				TaskID *string
+			} `graphql:"adoptResource(type: $type, iri: $iri, project: $project, stack: $stack, component: $component)"`
+		}
+		vars, err := resourceOwnerVariables(cmd, resourceAdoptFlags.Owner, resourceAdoptFlags.Component)
+		if err != nil {
+			return err
+		}
+		vars["type"] = args[0]
+		vars["iri"] = args[1]
+		if err := api.Mutate(ctx, svc, &m, vars); err != nil {
+			return err
+		}
+		cmdutil.PrintCueStruct(map[string]any{
+			"id": m.Resource.ID,
+		})
+		if rootPersistentFlags.Async || m.Resource.TaskID == nil {
+			return nil
+		}
+		return watchOwnJob(ctx, *m.Resource.TaskID)
+	},
+}
@@ -89,46 +89,19 @@ To set the owner to a component, supply '--component=ref', which implies
 			}
 		}
 
-		ownerType := resourceNewFlags.Owner
-		if resourceNewFlags.Component != "" {
-			if !cmd.Flag("owner").Changed {
-				ownerType = "component"
-			} else if ownerType != "component" {
-				return fmt.Errorf("--component conflicts with --owner=%q", ownerType)
-			}
-		}
-
 		var m struct {
 			Resource struct {
 				ID     string
 				TaskID *string
 			} `graphql:"createResource(type: $type, model: $model, project: $project, stack: $stack, component: $component, adopt: $adopt)"`
 		}
-		vars := map[string]any{
-			"type":  typ,
-			"model": scalars.JSONObject(model),
-			"adopt": resourceNewFlags.Adopt,
-		}
-		switch ownerType {
-		case "component":
-			vars["project"] = currentProjectRef()
-			vars["stack"] = currentStackRef()
-			vars["component"] = resourceNewFlags.Component
-		case "stack":
-			vars["project"] = currentProjectRef()
-			vars["stack"] = currentStackRef()
-			vars["component"] = (*string)(nil)
-		case "project":
-			vars["project"] = currentProjectRef()
-			vars["stack"] = (*string)(nil)
-			vars["component"] = (*string)(nil)
-		case "none":
-			vars["project"] = (*string)(nil)
-			vars["stack"] = (*string)(nil)
-			vars["component"] = (*string)(nil)
-		default:
-			return fmt.Errorf("unexpected value for --owner: %q", ownerType)
+		vars, err := resourceOwnerVariables(cmd, resourceNewFlags.Owner, resourceNewFlags.Component)
+		if err != nil {
+			return err
 		}
+		vars["type"] = typ
+		vars["model"] = scalars.JSONObject(model)
+		vars["adopt"] = resourceNewFlags.Adopt
 		if err := api.Mutate(ctx, svc, &m, vars); err != nil {
 			return err
 		}
@@ -141,3 +114,38 @@ To set the owner to a component, supply '--component=ref', which implies
 		return watchOwnJob(ctx, *m.Resource.TaskID)
 	},
 }
+
+// Returns the project, stack, and component variables of resource creating
+// mutations for the given --owner and --component flags.
+func resourceOwnerVariables(cmd *cobra.Command, ownerType string, component string) (map[string]any, error) {
+	if component != "" {
+		if !cmd.Flag("owner").Changed {
+			ownerType = "component"
+		} else if ownerType != "component" {
+			return nil, fmt.Errorf("--component conflicts with --owner=%q", ownerType)
+		}
+	}
+
+	vars := make(map[string]any)
+	switch ownerType {
+	case "component":
+		vars["project"] = currentProjectRef()
+		vars["stack"] = currentStackRef()
+		vars["component"] = component
+	case "stack":
+		vars["project"] = currentProjectRef()
+		vars["stack"] = currentStackRef()
+		vars["component"] = (*string)(nil)
+	case "project":
+		vars["project"] = currentProjectRef()
+		vars["stack"] = (*string)(nil)
+		vars["component"] = (*string)(nil)
+	case "none":
+		vars["project"] = (*string)(nil)
+		vars["stack"] = (*string)(nil)
+		vars["component"] = (*string)(nil)
+	default:
+		return nil, fmt.Errorf("unexpected value for --owner: %q", ownerType)
+	}
+	return vars, nil
+}
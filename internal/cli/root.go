@@ -117,6 +117,7 @@ func Main() {
 				VarDir:      cfg.VarDir,
 				GUIEndpoint: effectiveServerURL(),
 				Debug:       isDebugMode(),
+				LogSinks:    cfg.Log.Sinks,
 			}
 			if err := p.Init(ctx); err != nil {
 				cmdutil.Fatalf("initializing peer: %w", err)
@@ -15,6 +15,8 @@ import (
 func init() {
 	rootCmd.AddCommand(runCmd)
 	runCmd.Flags().StringVar(&applyFlags.Format, "format", "", "see `exo help apply`")
+	runCmd.Flags().StringSliceVar(&applyFlags.Profiles, "profile", nil, "see `exo help apply`")
+	runCmd.Flags().StringArrayVar(&applyFlags.Variables, "var", nil, "see `exo help apply`")
 }
 
 // TODO: Rework this for a world with stacks. If there is already a stack,
@@ -60,7 +62,7 @@ If a workspace does not exist, one will be created in the current directory.
 		}
 
 		// Apply manifest.
-		if err := apply(ctx, kernel, workspace, args); err != nil {
+		if err := apply(ctx, kernel, workspace, manifestArgs(args)); err != nil {
 			return fmt.Errorf("applying manifest: %w", err)
 		}
 
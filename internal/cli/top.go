@@ -0,0 +1,481 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Nerdmaster/terminal"
+	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/cmdutil"
+	"github.com/deref/exo/internal/util/mathutil"
+	"github.com/deref/exo/internal/util/term"
+	"github.com/deref/rgbterm"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show a live dashboard of the stack",
+	Long: `Shows the components of the current stack in a full screen dashboard, with
+the state, CPU, memory, and listening ports of each process. The dashboard is
+updated live as the stack changes.
+
+Keys:
+
+  j, k, arrows  select a component
+  s             start the selected component
+  x             stop the selected component
+  r             restart the selected component
+  l, enter      show or hide the logs of the selected component
+  q, ctrl-c     quit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if !isInteractive() {
+			return errors.New("exo top requires an interactive terminal")
+		}
+		return runTop(ctx)
+	},
+}
+
+type topStackFragment struct {
+	Name       string
+	Components []topComponentFragment `graphql:"components(recursive: true)"`
+}
+
+type topComponentFragment struct {
+	ID        string
+	ParentID  *string
+	Name      string
+	Type      string
+	AsProcess *struct {
+		Running       bool
+		Ports         *[]int
+		LatestMetrics *struct {
+			CPUPercent    float64
+			ResidentBytes float64
+		}
+	}
+}
+
+type topLogEvent struct {
+	Timestamp scalars.Instant
+	Message   string
+}
+
+// Number of log lines fetched for the log pane; more than fit on most screens.
+const topLogLimit = 200
+
+type topModel struct {
+	stack *topStackFragment
+	// Components in the order they are displayed.
+	components []*topComponentFragment
+	rows       []string
+	selectedID string
+	selected   int
+	scroll     int
+	showLogs   bool
+	logs       []topLogEvent
+	status     string
+}
+
+func runTop(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	beginExclusive()
+	defer endExclusive()
+
+	var res struct {
+		Stack topStackFragment `graphql:"watchStack(ref: $ref)"`
+	}
+	sub := api.Subscribe(ctx, svc, &res, map[string]any{
+		"ref": currentStackRef(),
+	})
+	defer sub.Stop()
+
+	raw := &term.RawMode{}
+	if err := raw.Enter(); err != nil {
+		return fmt.Errorf("entering raw mode: %w", err)
+	}
+	enterTopScreen()
+	defer func() {
+		exitTopScreen()
+		if err := raw.Exit(); err != nil {
+			cmdutil.Fatalf("restoring terminal state: %w", err)
+		}
+	}()
+
+	keys := make(chan terminal.Keypress)
+	go func() {
+		r := terminal.NewKeyReader(os.Stdin)
+		for {
+			press, err := r.ReadKeypress()
+			if err != nil {
+				cancel()
+				return
+			}
+			select {
+			case keys <- press:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+
+	logTicker := time.NewTicker(time.Second)
+	defer logTicker.Stop()
+
+	out := bufio.NewWriter(os.Stdout)
+	m := &topModel{
+		status: "Connecting...",
+	}
+	for {
+		m.render(out)
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-sub.Events():
+			if !ok {
+				if err := sub.Err(); err != nil && ctx.Err() == nil {
+					return err
+				}
+				return nil
+			}
+			stack := api.OperationData(event).(topStackFragment)
+			if m.stack == nil {
+				m.status = ""
+			}
+			m.setStack(&stack)
+
+		case <-resize:
+
+		case <-logTicker.C:
+			if m.showLogs {
+				m.refreshLogs(ctx)
+			}
+
+		case press := <-keys:
+			m.status = ""
+			switch press.Key {
+			case 'q', terminal.KeyCtrlC:
+				return nil
+
+			case 'k', terminal.KeyUp:
+				m.selectIndex(m.selected - 1)
+				m.refreshLogs(ctx)
+
+			case 'j', terminal.KeyDown:
+				m.selectIndex(m.selected + 1)
+				m.refreshLogs(ctx)
+
+			case 's':
+				m.control(ctx, "start", "startWorkspaceComponents")
+
+			case 'x':
+				m.control(ctx, "stop", "stopWorkspaceComponents")
+
+			case 'r':
+				m.control(ctx, "restart", "restartWorkspaceComponents")
+
+			case 'l', terminal.KeyEnter:
+				m.showLogs = !m.showLogs
+				m.refreshLogs(ctx)
+
+			case terminal.KeyCtrlL:
+				fmt.Printf("%c[2J", term.Esc)
+
+			case terminal.KeyCtrlZ:
+				exitTopScreen()
+				if err := raw.Suspend(); err != nil {
+					cmdutil.Fatalf("suspending: %w", err)
+				}
+				enterTopScreen()
+			}
+		}
+	}
+}
+
+var topSelectedCode = fmt.Sprintf("%c[7m", term.Esc)
+
+// Switches to the alternate screen buffer, so that the user's scrollback is
+// restored on exit.
+func enterTopScreen() {
+	fmt.Printf("%c[?1049h%c[?25l", term.Esc, term.Esc)
+}
+
+func exitTopScreen() {
+	fmt.Printf("%c[?25h%c[?1049l", term.Esc, term.Esc)
+}
+
+func (m *topModel) setStack(stack *topStackFragment) {
+	m.stack = stack
+
+	ids := make(map[string]bool, len(stack.Components))
+	for _, component := range stack.Components {
+		ids[component.ID] = true
+	}
+	b := term.NewTreeBuilder()
+	nodes := make(map[*term.TreeNode]*topComponentFragment, len(stack.Components))
+	for i := range stack.Components {
+		component := &stack.Components[i]
+		node := &term.TreeNode{
+			ID:      component.ID,
+			Label:   component.Name,
+			Content: formatTopColumns(component),
+		}
+		if component.ParentID != nil && ids[*component.ParentID] {
+			node.ParentID = *component.ParentID
+		}
+		b.AddNode(node)
+		nodes[node] = component
+	}
+	roots := b.Build()
+
+	// Align the columns of all trees, not just within each tree.
+	labelWidth := len("NAME  ")
+	for _, root := range roots {
+		if root.LabelWidth > labelWidth {
+			labelWidth = root.LabelWidth
+		}
+	}
+	header := "NAME" + strings.Repeat(" ", labelWidth-len("NAME")) + topColumnHeader
+
+	var buf bytes.Buffer
+	m.components = m.components[:0]
+	var visit func(node *term.TreeNode)
+	visit = func(node *term.TreeNode) {
+		m.components = append(m.components, nodes[node])
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		root.LabelWidth = labelWidth
+		term.PrintTree(&buf, root)
+		visit(root)
+	}
+	m.rows = append([]string{header}, strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")...)
+	if len(m.components) == 0 {
+		m.rows = []string{header}
+	}
+
+	// Preserve the selection across updates, even if components are added or
+	// removed.
+	selected := 0
+	for i, component := range m.components {
+		if component.ID == m.selectedID {
+			selected = i
+			break
+		}
+	}
+	m.selectIndex(selected)
+}
+
+const topColumnsFormat = "%-7s %7s %10s  %s"
+
+var topColumnHeader = fmt.Sprintf(topColumnsFormat, "STATE", "CPU", "MEMORY", "PORTS")
+
+func formatTopColumns(component *topComponentFragment) string {
+	process := component.AsProcess
+	if process == nil {
+		return fmt.Sprintf(topColumnsFormat, "-", "", "", "")
+	}
+	state := "stopped"
+	if process.Running {
+		state = "running"
+	}
+	cpu, memory := "-", "-"
+	if metrics := process.LatestMetrics; metrics != nil && process.Running {
+		cpu = formatPercent(metrics.CPUPercent)
+		memory = units.BytesSize(metrics.ResidentBytes)
+	}
+	var ports []string
+	if process.Ports != nil {
+		for _, port := range *process.Ports {
+			ports = append(ports, strconv.Itoa(port))
+		}
+	}
+	// Both states fill the column, so color codes need not be accounted for
+	// when padding.
+	if useColor() {
+		if process.Running {
+			state = rgbterm.FgString(state, 28, 196, 22) + term.ResetCode
+		} else {
+			state = rgbterm.FgString(state, 128, 128, 128) + term.ResetCode
+		}
+	}
+	return fmt.Sprintf("%s %7s %10s  %s", state, cpu, memory, strings.Join(ports, ","))
+}
+
+func (m *topModel) selectIndex(i int) {
+	if i >= len(m.components) {
+		i = len(m.components) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	m.selected = i
+	m.selectedID = ""
+	if component := m.selectedComponent(); component != nil {
+		m.selectedID = component.ID
+	}
+}
+
+func (m *topModel) selectedComponent() *topComponentFragment {
+	if m.selected < len(m.components) {
+		return m.components[m.selected]
+	}
+	return nil
+}
+
+func (m *topModel) control(ctx context.Context, verb string, mutation string) {
+	component := m.selectedComponent()
+	if component == nil {
+		return
+	}
+	jobID, err := api.CreateJob(ctx, svc, mutation, map[string]any{
+		"workspace":  currentWorkspaceRef(),
+		"components": []string{component.Name},
+	})
+	if err != nil {
+		m.status = fmt.Sprintf("error: %s %s: %v", verb, component.Name, err)
+		return
+	}
+	m.status = fmt.Sprintf("%s %s: job %s", verb, component.Name, jobID)
+}
+
+func (m *topModel) refreshLogs(ctx context.Context) {
+	m.logs = nil
+	component := m.selectedComponent()
+	if !m.showLogs || component == nil {
+		return
+	}
+	var q struct {
+		Component *struct {
+			Stream struct {
+				Events struct {
+					Items []topLogEvent
+				} `graphql:"events(prev: $limit)"`
+			}
+		} `graphql:"componentById(id: $id)"`
+	}
+	if err := api.Query(ctx, svc, &q, map[string]any{
+		"id":    component.ID,
+		"limit": topLogLimit,
+	}); err != nil {
+		m.status = fmt.Sprintf("error: reading logs: %v", err)
+		return
+	}
+	if q.Component != nil {
+		m.logs = q.Component.Stream.Events.Items
+	}
+}
+
+func (m *topModel) render(out *bufio.Writer) {
+	width, height := term.GetSize()
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	var lines []string
+	title := "exo top"
+	if m.stack != nil {
+		title += " - " + m.stack.Name
+	}
+	lines = append(lines, title)
+
+	// Split the screen between the components and the log pane, reserving
+	// the bottom line for status.
+	listEnd := height - 1
+	if m.showLogs {
+		listEnd = (height + 1) / 2
+	}
+	if len(m.rows) > 0 {
+		lines = append(lines, m.rows[0])
+		components := m.rows[1:]
+		listHeight := listEnd - len(lines)
+		if m.selected < m.scroll {
+			m.scroll = m.selected
+		}
+		if listHeight > 0 && m.selected >= m.scroll+listHeight {
+			m.scroll = m.selected - listHeight + 1
+		}
+		for i := m.scroll; i < len(components) && i < m.scroll+listHeight; i++ {
+			line := term.TrimToVisualLength(components[i], width)
+			if i == m.selected {
+				// Reverse video, reapplied after any color resets in the line.
+				line = strings.ReplaceAll(line, term.ResetCode, term.ResetCode+topSelectedCode)
+				line = topSelectedCode + line + strings.Repeat(" ", width-term.VisualLength(line)) + term.ResetCode
+			}
+			lines = append(lines, line)
+		}
+	}
+	for len(lines) < listEnd {
+		lines = append(lines, "")
+	}
+
+	// Lines below the separator and above the status line. On short screens,
+	// or with many components, there may be no room for the log pane.
+	logHeight := mathutil.IntMax(height-2-len(lines), 0)
+	if m.showLogs && logHeight > 0 {
+		name := ""
+		if component := m.selectedComponent(); component != nil {
+			name = component.Name
+		}
+		separator := "── logs: " + name + " "
+		if n := width - term.VisualLength(separator); n > 0 {
+			separator += strings.Repeat("─", n)
+		}
+		lines = append(lines, separator)
+		logs := m.logs
+		if len(logs) > logHeight {
+			logs = logs[len(logs)-logHeight:]
+		}
+		for _, event := range logs {
+			timestamp := event.Timestamp.GoTime().Local().Format("15:04:05")
+			message := strings.ReplaceAll(event.Message, "\t", "  ")
+			line := timestamp + " " + message
+			lines = append(lines, term.TrimToVisualLength(line, width)+term.ResetCode)
+		}
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	status := m.status
+	if status == "" {
+		status = "s start  x stop  r restart  l logs  q quit"
+	}
+	lines = append(lines, term.TrimToVisualLength(status, width))
+
+	fmt.Fprintf(out, "%c[H", term.Esc)
+	for i, line := range lines {
+		if i > 0 {
+			out.WriteString("\r\n")
+		}
+		fmt.Fprintf(out, "%s%c[K", line, term.Esc)
+	}
+	fmt.Fprintf(out, "%c[J", term.Esc)
+	out.Flush()
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/deref/exo/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	workspaceCmd.AddCommand(workspaceProfilesCmd)
+	workspaceProfilesCmd.Flags().BoolVar(&workspaceProfilesFlags.Clear, "clear", false, "deactivate all profiles")
+}
+
+var workspaceProfilesFlags struct {
+	Clear bool
+}
+
+var workspaceProfilesCmd = &cobra.Command{
+	Use:   "profiles [flags] [profile...]",
+	Short: "Shows or sets the active profiles of the workspace",
+	Long: `Shows or sets the profiles that are active when applying manifests to the
+current workspace.
+
+If no profiles are given, the active profiles are printed, one per line.
+Otherwise, the given profiles replace the active profiles.
+
+Components outside the active profiles are created, but not started. See
+'exo help apply' for details.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if len(args) == 0 && !workspaceProfilesFlags.Clear {
+			var q struct {
+				Workspace *struct {
+					Profiles []string
+				} `graphql:"workspaceByRef(ref: $currentWorkspace)"`
+			}
+			mustQueryWorkspace(ctx, &q, nil)
+			for _, profile := range q.Workspace.Profiles {
+				fmt.Println(profile)
+			}
+			return nil
+		}
+		if len(args) > 0 && workspaceProfilesFlags.Clear {
+			return fmt.Errorf("--clear may not be combined with profile arguments")
+		}
+
+		profiles := args
+		if profiles == nil {
+			profiles = []string{}
+		}
+		var m struct {
+			Workspace struct {
+				ID string
+			} `graphql:"setWorkspaceProfiles(workspace: $workspace, profiles: $profiles)"`
+		}
+		if err := api.Mutate(ctx, svc, &m, map[string]any{
+			"workspace": currentWorkspaceRef(),
+			"profiles":  profiles,
+		}); err != nil {
+			return fmt.Errorf("setting profiles: %w", err)
+		}
+		return nil
+	},
+}
@@ -20,6 +20,52 @@ type GUIConfig struct {
 
 type LogConfig struct {
 	SyslogPort uint
+	// Default retention policy, applied to each log stream individually.
+	Retention RetentionConfig
+	// Retention policy overrides, keyed by stream name.
+	Streams map[string]RetentionConfig
+	// Destinations that events of all workspaces are exported to.
+	Sinks []LogSinkConfig `toml:"sinks"`
+}
+
+// Limits on the events retained per log stream. Zero values disable the
+// corresponding limit.
+type RetentionConfig struct {
+	// Go duration string, such as "72h".
+	MaxAge    string
+	MaxEvents int
+	MaxBytes  int64
+}
+
+// Destination that log events are exported to. Which other fields apply
+// depends on the type.
+type LogSinkConfig struct {
+	// One of "otlp", "file", or "stdout".
+	Type string `toml:"type" json:"type"`
+	// OTLP/HTTP logs endpoint. Defaults to "http://localhost:4318/v1/logs".
+	Endpoint string `toml:"endpoint" json:"endpoint,omitempty"`
+	// Additional headers sent to the OTLP endpoint, such as for authorization.
+	Headers map[string]string `toml:"headers" json:"headers,omitempty"`
+	// Path of the JSON-lines file.
+	Path string `toml:"path" json:"path,omitempty"`
+	// Size at which the file is rotated. Defaults to 10 MiB.
+	MaxBytes int64 `toml:"maxBytes" json:"maxBytes,omitempty"`
+	// Number of rotated files kept. Defaults to 5.
+	MaxFiles int `toml:"maxFiles" json:"maxFiles,omitempty"`
+}
+
+// Plugins are executables that serve component and resource controllers.
+type PluginConfig struct {
+	Path string   `toml:"path"`
+	Args []string `toml:"args"`
+}
+
+// Recording of the resource usage of running processes.
+type MetricsConfig struct {
+	Disable bool
+	// Go duration strings. Default to "5s" and "24h" respectively.
+	Interval string
+	MaxAge   string
 }
 
 type TelemetryConfig struct {
@@ -42,6 +88,8 @@ type Config struct {
 	Client    ClientConfig
 	GUI       GUIConfig `toml:"gui"`
 	Log       LogConfig
+	Metrics   MetricsConfig
+	Plugins   []PluginConfig `toml:"plugins"`
 	Telemetry TelemetryConfig
 }
 
@@ -119,6 +167,17 @@ func setDefaults(cfg *Config) {
 	if cfg.Log.SyslogPort == 0 {
 		cfg.Log.SyslogPort = 43550
 	}
+	if cfg.Log.Retention == (RetentionConfig{}) {
+		cfg.Log.Retention.MaxEvents = 10000
+	}
+
+	// Metrics
+	if cfg.Metrics.Interval == "" {
+		cfg.Metrics.Interval = "5s"
+	}
+	if cfg.Metrics.MaxAge == "" {
+		cfg.Metrics.MaxAge = "24h"
+	}
 
 	// GUI
 	if cfg.GUI.Port == 0 {
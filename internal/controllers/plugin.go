@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/config"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/deref/exo/sdk"
+)
+
+// Plugin is a running plugin executable whose controllers have been
+// registered. Plugins are started by the daemon. Other peers connect to the
+// daemon's plugins, rather than starting their own.
+type Plugin struct {
+	Config      config.PluginConfig
+	Description sdk.PluginDescription
+	SocketPath  string
+
+	// Nil for plugins that were connected to, rather than started.
+	cmd       *exec.Cmd
+	socketDir string
+	exited    chan struct{}
+	registry  *Registry
+
+	mu sync.Mutex
+	// Types registered by this plugin.
+	components []string
+	resources  []string
+}
+
+const pluginStartTimeout = 10 * time.Second
+
+// StartPlugin runs a plugin executable and registers its controllers. Resource
+// types are also registered as component types, backed by the given service.
+// If the plugin exits, its controllers are unregistered.
+func StartPlugin(ctx context.Context, reg *Registry, svc api.Service, cfg config.PluginConfig) (*Plugin, error) {
+	// Socket paths have a short maximum length, so avoid deep directories.
+	socketDir, err := os.MkdirTemp("", "exo-plugin-")
+	if err != nil {
+		return nil, fmt.Errorf("creating socket directory: %w", err)
+	}
+	socketPath := filepath.Join(socketDir, "plugin.sock")
+
+	cmd := exec.Command(cfg.Path, cfg.Args...)
+	cmd.Env = append(os.Environ(), sdk.PluginSocketEnvVar+"="+socketPath)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(socketDir)
+		return nil, fmt.Errorf("starting: %w", err)
+	}
+	p := &Plugin{
+		Config:     cfg,
+		SocketPath: socketPath,
+		cmd:        cmd,
+		socketDir:  socketDir,
+		exited:     make(chan struct{}),
+		registry:   reg,
+	}
+	go p.wait(logging.CurrentLogger(ctx))
+
+	client := newPluginClient(socketPath)
+	if err := p.awaitDescription(ctx, client); err != nil {
+		p.Stop()
+		return nil, err
+	}
+	if err := p.register(client, svc); err != nil {
+		p.Stop()
+		return nil, err
+	}
+	select {
+	case <-p.exited:
+		// Exited while registering, so the controllers may have been registered
+		// after the exit was handled.
+		p.unregister()
+		return nil, errors.New("plugin exited")
+	default:
+		return p, nil
+	}
+}
+
+// Unregisters the plugin's controllers when the plugin process exits.
+func (p *Plugin) wait(logger logging.Logger) {
+	err := p.cmd.Wait()
+	p.unregister()
+	close(p.exited)
+	if err != nil {
+		logger.Infof("plugin %q exited: %v", p.Config.Path, err)
+	}
+}
+
+// ConnectPlugin registers the controllers of a plugin that was started by
+// another peer and is listening on the given socket.
+func ConnectPlugin(ctx context.Context, reg *Registry, svc api.Service, cfg config.PluginConfig, socketPath string) (*Plugin, error) {
+	p := &Plugin{
+		Config:     cfg,
+		SocketPath: socketPath,
+		registry:   reg,
+	}
+	client := newPluginClient(socketPath)
+	if err := client.invoke(ctx, "describe", struct{}{}, &p.Description); err != nil {
+		return nil, fmt.Errorf("describing: %w", err)
+	}
+	if err := p.register(client, svc); err != nil {
+		p.Stop()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Plugin) awaitDescription(ctx context.Context, client *pluginClient) error {
+	ctx, cancel := context.WithTimeout(ctx, pluginStartTimeout)
+	defer cancel()
+	for {
+		err := client.invoke(ctx, "describe", struct{}{}, &p.Description)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("awaiting plugin: %w", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// Registers the plugin's controllers. On failure, the caller is responsible
+// for calling Stop, which unregisters any controllers that were registered.
+// Only types that the plugin itself registered are recorded, so that stopping
+// the plugin never removes a builtin controller.
+func (p *Plugin) register(client *pluginClient, svc api.Service) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, typ := range p.Description.ComponentTypes {
+		if err := p.registry.RegisterComponent(typ, &remoteComponentController{
+			client: client,
+			typ:    typ,
+		}); err != nil {
+			return err
+		}
+		p.components = append(p.components, typ)
+	}
+	for _, typ := range p.Description.ResourceTypes {
+		ctrl := &remoteResourceController{
+			client: client,
+			typ:    typ,
+		}
+		if err := p.registry.RegisterResource(typ, ctrl); err != nil {
+			return err
+		}
+		p.resources = append(p.resources, typ)
+		if p.registry.ComponentController(typ) == nil {
+			if err := p.registry.RegisterComponent(typ, sdk.NewResourceComponentController[RawJSON](svc, ctrl)); err != nil {
+				return err
+			}
+			p.components = append(p.components, typ)
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) unregister() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, typ := range p.components {
+		p.registry.UnregisterComponent(typ)
+	}
+	for _, typ := range p.resources {
+		p.registry.UnregisterResource(typ)
+	}
+	p.components = nil
+	p.resources = nil
+}
+
+// Stop unregisters the plugin's controllers and, if the plugin was started
+// rather than connected to, kills the plugin process.
+func (p *Plugin) Stop() {
+	p.unregister()
+	if p.cmd == nil {
+		return
+	}
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+		<-p.exited
+	}
+	os.RemoveAll(p.socketDir)
+}
+
+// StartPlugins starts each configured plugin. Plugins that fail to start are
+// logged and skipped, so that a broken plugin does not prevent startup.
+func StartPlugins(ctx context.Context, reg *Registry, svc api.Service, cfgs []config.PluginConfig) []*Plugin {
+	logger := logging.CurrentLogger(ctx)
+	var plugins []*Plugin
+	for _, cfg := range cfgs {
+		p, err := StartPlugin(ctx, reg, svc, cfg)
+		if err != nil {
+			logger.Infof("error starting plugin %q: %v", cfg.Path, err)
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins
+}
@@ -0,0 +1,146 @@
+// Package controllers maintains the set of component and resource controllers
+// available to the reconciler, including those served by plugins.
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/sdk"
+)
+
+// Registry maps qualified type names, such as "deref.io/os/process", to
+// controllers. Unqualified aliases, such as "process", are supported for
+// component types that predate qualified names.
+type Registry struct {
+	mu         sync.RWMutex
+	components map[string]sdk.AComponentController
+	resources  map[string]sdk.AResourceController
+	aliases    map[string]string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		components: make(map[string]sdk.AComponentController),
+		resources:  make(map[string]sdk.AResourceController),
+		aliases:    make(map[string]string),
+	}
+}
+
+// ValidateTypeName checks that a type name is qualified by a domain name, as
+// in "example.com/widgets/widget".
+func ValidateTypeName(typ string) error {
+	parts := strings.Split(typ, "/")
+	if len(parts) < 2 || !strings.Contains(parts[0], ".") {
+		return errutil.HTTPErrorf(http.StatusBadRequest, "type name must be qualified by a domain name: %q", typ)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return errutil.HTTPErrorf(http.StatusBadRequest, "invalid type name: %q", typ)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) RegisterComponent(typ string, ctrl sdk.AComponentController) error {
+	if err := ValidateTypeName(typ); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.components[typ]; exists {
+		return fmt.Errorf("component controller already registered for type: %q", typ)
+	}
+	r.components[typ] = ctrl
+	return nil
+}
+
+func (r *Registry) RegisterResource(typ string, ctrl sdk.AResourceController) error {
+	if err := ValidateTypeName(typ); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.resources[typ]; exists {
+		return fmt.Errorf("resource controller already registered for type: %q", typ)
+	}
+	r.resources[typ] = ctrl
+	return nil
+}
+
+func (r *Registry) Alias(alias string, typ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[alias] = typ
+}
+
+// UnregisterComponent removes the component controller for a type. Aliases of
+// the type are removed once it has no controllers.
+func (r *Registry) UnregisterComponent(typ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.components, typ)
+	r.pruneAliases(typ)
+}
+
+// UnregisterResource removes the resource controller for a type. Aliases of
+// the type are removed once it has no controllers.
+func (r *Registry) UnregisterResource(typ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.resources, typ)
+	r.pruneAliases(typ)
+}
+
+func (r *Registry) pruneAliases(typ string) {
+	if r.components[typ] != nil || r.resources[typ] != nil {
+		return
+	}
+	for alias, target := range r.aliases {
+		if target == typ {
+			delete(r.aliases, alias)
+		}
+	}
+}
+
+// QualifyType resolves an alias to its qualified type name. Other names are
+// returned unchanged.
+func (r *Registry) QualifyType(typ string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.qualifyType(typ)
+}
+
+func (r *Registry) qualifyType(typ string) string {
+	if qualified, ok := r.aliases[typ]; ok {
+		return qualified
+	}
+	return typ
+}
+
+// Returns nil if there is no controller for the given type.
+func (r *Registry) ComponentController(typ string) sdk.AComponentController {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.components[r.qualifyType(typ)]
+}
+
+// Returns nil if there is no controller for the given type.
+func (r *Registry) ResourceController(typ string) sdk.AResourceController {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resources[r.qualifyType(typ)]
+}
+
+func (r *Registry) ComponentTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.components))
+	for typ := range r.components {
+		types = append(types, typ)
+	}
+	return types
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTypeName(t *testing.T) {
+	assert.NoError(t, ValidateTypeName("deref.io/os/process"))
+	assert.NoError(t, ValidateTypeName("example.com/widget"))
+	assert.Error(t, ValidateTypeName("process"))
+	assert.Error(t, ValidateTypeName("os/process"))
+	assert.Error(t, ValidateTypeName("example.com//widget"))
+}
+
+func TestRegistryAliases(t *testing.T) {
+	reg := NewRegistry()
+	ctrl := &remoteComponentController{typ: "deref.io/os/process"}
+	assert.NoError(t, reg.RegisterComponent("deref.io/os/process", ctrl))
+	assert.Error(t, reg.RegisterComponent("deref.io/os/process", ctrl))
+	reg.Alias("process", "deref.io/os/process")
+
+	assert.Equal(t, ctrl, reg.ComponentController("process"))
+	assert.Equal(t, ctrl, reg.ComponentController("deref.io/os/process"))
+	assert.Nil(t, reg.ComponentController("container"))
+	assert.Nil(t, reg.ResourceController("process"))
+
+	reg.UnregisterComponent("deref.io/os/process")
+	assert.Nil(t, reg.ComponentController("process"))
+	assert.Equal(t, "process", reg.QualifyType("process"))
+}
+
+func TestRegistryUnregisterKinds(t *testing.T) {
+	reg := NewRegistry()
+	component := &remoteComponentController{typ: "deref.io/os/daemon"}
+	resource := &remoteResourceController{typ: "deref.io/os/daemon"}
+	assert.NoError(t, reg.RegisterComponent("deref.io/os/daemon", component))
+	assert.NoError(t, reg.RegisterResource("deref.io/os/daemon", resource))
+	reg.Alias("daemon", "deref.io/os/daemon")
+
+	// Removing the resource controller leaves the component controller and its
+	// alias in place.
+	reg.UnregisterResource("deref.io/os/daemon")
+	assert.Nil(t, reg.ResourceController("daemon"))
+	assert.Equal(t, component, reg.ComponentController("daemon"))
+
+	reg.UnregisterComponent("deref.io/os/daemon")
+	assert.Nil(t, reg.ComponentController("deref.io/os/daemon"))
+	assert.Equal(t, "daemon", reg.QualifyType("daemon"))
+}
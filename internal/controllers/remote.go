@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/sdk"
+)
+
+// pluginClient invokes controller methods of a plugin over its unix socket.
+type pluginClient struct {
+	http *http.Client
+}
+
+func newPluginClient(socketPath string) *pluginClient {
+	return &pluginClient{
+		http: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+func (c *pluginClient) invoke(ctx context.Context, method string, input any, output any) error {
+	inputB, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("marshalling input: %w", err)
+	}
+	// The host is ignored by the dialer.
+	req, err := http.NewRequestWithContext(ctx, "POST", "http://plugin/"+method, bytes.NewReader(inputB))
+	if err != nil {
+		return fmt.Errorf("forming request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting: %w", err)
+	}
+	defer resp.Body.Close()
+	bs, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Preserve the status, since controllers use it to signal conditions
+		// such as sdk.ErrResourceGone.
+		var obj struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(bs, &obj)
+		if obj.Message == "" {
+			obj.Message = string(bytes.TrimSpace(bs))
+		}
+		return errutil.NewHTTPError(resp.StatusCode, obj.Message)
+	}
+	if err := json.Unmarshal(bs, output); err != nil {
+		return fmt.Errorf("unmarshalling output: %w", err)
+	}
+	return nil
+}
+
+type remoteComponentController struct {
+	client *pluginClient
+	typ    string
+}
+
+func (ctrl *remoteComponentController) invoke(ctx context.Context, method string, cfg *sdk.ComponentConfig, model *RawJSON) (*sdk.PluginOutput, error) {
+	wireCfg, err := sdk.EncodePluginComponentConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var output sdk.PluginOutput
+	if err := ctrl.client.invoke(ctx, method, sdk.PluginInput{
+		Type:            ctrl.typ,
+		ComponentConfig: wireCfg,
+		Model:           *model,
+	}, &output); err != nil {
+		return nil, err
+	}
+	*model = output.Model
+	return &output, nil
+}
+
+func (ctrl *remoteComponentController) RenderComponent(ctx context.Context, cfg *sdk.ComponentConfig, model *RawJSON) ([]sdk.RenderedComponent, error) {
+	output, err := ctrl.invoke(ctx, "render-component", cfg, model)
+	if err != nil {
+		return nil, err
+	}
+	return output.Children, nil
+}
+
+func (ctrl *remoteComponentController) RefreshComponent(ctx context.Context, cfg *sdk.ComponentConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "refresh-component", cfg, model)
+	return err
+}
+
+func (ctrl *remoteComponentController) ComponentUpdated(ctx context.Context, cfg *sdk.ComponentConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "component-updated", cfg, model)
+	return err
+}
+
+func (ctrl *remoteComponentController) ChildrenUpdated(ctx context.Context, cfg *sdk.ComponentConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "children-updated", cfg, model)
+	return err
+}
+
+func (ctrl *remoteComponentController) ShutdownComponent(ctx context.Context, cfg *sdk.ComponentConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "shutdown-component", cfg, model)
+	return err
+}
+
+func (ctrl *remoteComponentController) DeleteComponent(ctx context.Context, cfg *sdk.ComponentConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "delete-component", cfg, model)
+	return err
+}
+
+type remoteResourceController struct {
+	client *pluginClient
+	typ    string
+}
+
+func (ctrl *remoteResourceController) invoke(ctx context.Context, method string, cfg *sdk.ResourceConfig, model *RawJSON) (*sdk.PluginOutput, error) {
+	var output sdk.PluginOutput
+	if err := ctrl.client.invoke(ctx, method, sdk.PluginInput{
+		Type:           ctrl.typ,
+		ResourceConfig: cfg,
+		Model:          *model,
+	}, &output); err != nil {
+		return nil, err
+	}
+	*model = output.Model
+	return &output, nil
+}
+
+func (ctrl *remoteResourceController) IdentifyResource(ctx context.Context, cfg *sdk.ResourceConfig, model *RawJSON) (string, error) {
+	output, err := ctrl.invoke(ctx, "identify-resource", cfg, model)
+	if err != nil {
+		return "", err
+	}
+	return output.IRI, nil
+}
+
+func (ctrl *remoteResourceController) LocateResource(ctx context.Context, cfg *sdk.ResourceConfig, iri string, model *RawJSON) error {
+	var output sdk.PluginOutput
+	if err := ctrl.client.invoke(ctx, "locate-resource", sdk.PluginInput{
+		Type:           ctrl.typ,
+		ResourceConfig: cfg,
+		Model:          *model,
+		IRI:            iri,
+	}, &output); err != nil {
+		return err
+	}
+	*model = output.Model
+	return nil
+}
+
+func (ctrl *remoteResourceController) CreateResource(ctx context.Context, cfg *sdk.ResourceConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "create-resource", cfg, model)
+	return err
+}
+
+func (ctrl *remoteResourceController) ReadResource(ctx context.Context, cfg *sdk.ResourceConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "read-resource", cfg, model)
+	return err
+}
+
+func (ctrl *remoteResourceController) UpdateResource(ctx context.Context, cfg *sdk.ResourceConfig, prev *RawJSON, next *RawJSON) error {
+	var output sdk.PluginOutput
+	if err := ctrl.client.invoke(ctx, "update-resource", sdk.PluginInput{
+		Type:           ctrl.typ,
+		ResourceConfig: cfg,
+		Model:          *next,
+		Previous:       *prev,
+	}, &output); err != nil {
+		return err
+	}
+	*prev = output.Previous
+	*next = output.Model
+	return nil
+}
+
+func (ctrl *remoteResourceController) ShutdownResource(ctx context.Context, cfg *sdk.ResourceConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "shutdown-resource", cfg, model)
+	return err
+}
+
+func (ctrl *remoteResourceController) DeleteResource(ctx context.Context, cfg *sdk.ResourceConfig, model *RawJSON) error {
+	_, err := ctrl.invoke(ctx, "delete-resource", cfg, model)
+	return err
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+type widgetModel struct {
+	Size     int  `json:"size"`
+	Rendered bool `json:"rendered"`
+}
+
+type widgetController struct {
+	sdk.PureComponentController[widgetModel]
+}
+
+func (ctrl *widgetController) RenderComponent(ctx context.Context, cfg *sdk.ComponentConfig, m *widgetModel) ([]sdk.RenderedComponent, error) {
+	m.Rendered = true
+	return []sdk.RenderedComponent{
+		{
+			Type: "example.com/gadget",
+			Name: cfg.Name + "-gadget",
+			Spec: map[string]any{"size": m.Size},
+		},
+	}, nil
+}
+
+type goneController struct{}
+
+func (ctrl *goneController) IdentifyResource(context.Context, *sdk.ResourceConfig, *RawJSON) (string, error) {
+	return "example:gone", nil
+}
+func (ctrl *goneController) CreateResource(context.Context, *sdk.ResourceConfig, *RawJSON) error {
+	return nil
+}
+func (ctrl *goneController) ReadResource(context.Context, *sdk.ResourceConfig, *RawJSON) error {
+	return sdk.ErrResourceGone
+}
+func (ctrl *goneController) UpdateResource(context.Context, *sdk.ResourceConfig, *RawJSON, *RawJSON) error {
+	return nil
+}
+func (ctrl *goneController) ShutdownResource(context.Context, *sdk.ResourceConfig, *RawJSON) error {
+	return nil
+}
+func (ctrl *goneController) DeleteResource(context.Context, *sdk.ResourceConfig, *RawJSON) error {
+	return nil
+}
+
+func startTestPlugin(t *testing.T, p *sdk.Plugin) *pluginClient {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "exo-plugin-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "plugin.sock")
+	go func() {
+		_ = p.ListenAndServe(socketPath)
+	}()
+	client := newPluginClient(socketPath)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		var desc sdk.PluginDescription
+		err := client.invoke(ctx, "describe", struct{}{}, &desc)
+		if err == nil {
+			return client
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal(err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestRemoteComponentController(t *testing.T) {
+	client := startTestPlugin(t, &sdk.Plugin{
+		Components: map[string]sdk.AComponentController{
+			"example.com/widget": sdk.NewComponentControllerAdapater[widgetModel](&widgetController{}),
+		},
+	})
+	ctrl := &remoteComponentController{
+		client: client,
+		typ:    "example.com/widget",
+	}
+	cfg := &sdk.ComponentConfig{
+		ID:   "abc",
+		Type: "example.com/widget",
+		Name: "thing",
+	}
+	model := RawJSON(`{"size": 3}`)
+	children, err := ctrl.RenderComponent(context.Background(), cfg, &model)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.JSONEq(t, `{"size": 3, "rendered": true}`, string(model))
+	if assert.Len(t, children, 1) {
+		assert.Equal(t, "thing-gadget", children[0].Name)
+		assert.Equal(t, "example.com/gadget", children[0].Type)
+		spec, _ := json.Marshal(children[0].Spec)
+		assert.JSONEq(t, `{"size": 3}`, string(spec))
+	}
+
+	unknown := &remoteComponentController{
+		client: client,
+		typ:    "example.com/unknown",
+	}
+	_, err = unknown.RenderComponent(context.Background(), cfg, &model)
+	assert.Error(t, err)
+}
+
+func TestRemoteResourceControllerErrorStatus(t *testing.T) {
+	client := startTestPlugin(t, &sdk.Plugin{
+		Resources: map[string]sdk.AResourceController{
+			"example.com/gone": &goneController{},
+		},
+	})
+	ctrl := &remoteResourceController{
+		client: client,
+		typ:    "example.com/gone",
+	}
+	cfg := &sdk.ResourceConfig{
+		ID:   "abc",
+		Type: "example.com/gone",
+	}
+	model := RawJSON(`{}`)
+
+	iri, err := ctrl.IdentifyResource(context.Background(), cfg, &model)
+	assert.NoError(t, err)
+	assert.Equal(t, "example:gone", iri)
+
+	err = ctrl.ReadResource(context.Background(), cfg, &model)
+	var httpErr errutil.HTTPError
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, http.StatusGone, httpErr.HTTPStatus())
+	}
+}
@@ -25,6 +25,8 @@ type DependenciesOutput struct {
 
 type InitializeInput struct {
 	Spec string `json:"spec"`
+	// If true, the component is created, but not started.
+	Stopped bool `json:"stopped"`
 }
 
 type InitializeOutput struct {
@@ -10,6 +10,9 @@ interface "lifecycle" {
 
   method "initialize" {
     input "spec" "string" {}
+    input "stopped" "bool" {
+      doc = "If true, the component is created, but not started."
+    }
   }
 
   method "refresh" {
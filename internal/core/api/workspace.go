@@ -55,6 +55,17 @@ type BuildOutput struct {
 	JobID string `json:"jobId"`
 }
 
+type Readiness interface {
+	// Blocks until the component is ready to serve its dependents.
+	AwaitReady(context.Context, *AwaitReadyInput) (*AwaitReadyOutput, error)
+}
+
+type AwaitReadyInput struct {
+}
+
+type AwaitReadyOutput struct {
+}
+
 type Workspace interface {
 	Process
 	Builder
@@ -87,6 +98,8 @@ type Workspace interface {
 	SetComponentState(context.Context, *SetComponentStateInput) (*SetComponentStateOutput, error)
 	// Returns pages of events for some set of streams. If `cursor` is specified, standard pagination behavior is used. Otherwise the cursor is assumed to represent the current tail of the stream.
 	GetEvents(context.Context, *GetEventsInput) (*GetEventsOutput, error)
+	// Searches the messages of events in a set of streams. Returns the most recent matches, in chronological order.
+	SearchEvents(context.Context, *SearchEventsInput) (*SearchEventsOutput, error)
 	StartComponents(context.Context, *StartComponentsInput) (*StartComponentsOutput, error)
 	StopComponents(context.Context, *StopComponentsInput) (*StopComponentsOutput, error)
 	SignalComponents(context.Context, *SignalComponentsInput) (*SignalComponentsOutput, error)
@@ -95,6 +108,8 @@ type Workspace interface {
 	DescribeVolumes(context.Context, *DescribeVolumesInput) (*DescribeVolumesOutput, error)
 	DescribeNetworks(context.Context, *DescribeNetworksInput) (*DescribeNetworksOutput, error)
 	ExportProcfile(context.Context, *ExportProcfileInput) (*ExportProcfileOutput, error)
+	// Exports containers, volumes, and networks as a compose file. Processes are exported to an x-exo extension.
+	ExportCompose(context.Context, *ExportComposeInput) (*ExportComposeOutput, error)
 	// Read a file from disk.
 	ReadFile(context.Context, *ReadFileInput) (*ReadFileOutput, error)
 	// Writes a file to disk.
@@ -147,11 +162,19 @@ type ApplyInput struct {
 	ManifestPath *string `json:"manifestPath"`
 	// Contents of the manifest file. Not required if manifest-path is provided.
 	Manifest *string `json:"manifest"`
+	// If true, the planned changes are reported, but not performed.
+	DryRun bool `json:"dryRun"`
+	// Active profiles. Components assigned to other profiles are created, but not started.
+	Profiles []string `json:"profiles"`
+	// Values of manifest variables, overriding their defaults.
+	Variables map[string]string `json:"variables"`
 }
 
 type ApplyOutput struct {
 	Warnings []string `json:"warnings"`
-	JobID    string   `json:"jobId"`
+	// Empty for dry runs.
+	JobID string          `json:"jobId"`
+	Plan  []PlannedChange `json:"plan"`
 }
 
 type ResolveInput struct {
@@ -269,8 +292,10 @@ type GetEventsInput struct {
 	Streams   []string `json:"streams"`
 	Cursor    *string  `json:"cursor"`
 	FilterStr string   `json:"filterStr"`
-	Prev      *int     `json:"prev"`
-	Next      *int     `json:"next"`
+	// Space separated tag conditions, such as `level>=warn request_id=abc`. Operators are =, !=, <, <=, >, and >=.
+	TagFilter string `json:"tagFilter"`
+	Prev      *int   `json:"prev"`
+	Next      *int   `json:"next"`
 }
 
 type GetEventsOutput struct {
@@ -279,6 +304,22 @@ type GetEventsOutput struct {
 	NextCursor string  `json:"nextCursor"`
 }
 
+type SearchEventsInput struct {
+
+	// If omitted, searches the streams of the workspace and all of its components.
+	Streams []string `json:"streams"`
+	Query   string   `json:"query"`
+	Since   *string  `json:"since"`
+	Until   *string  `json:"until"`
+	Context int      `json:"context"`
+	Limit   *int     `json:"limit"`
+}
+
+type SearchEventsOutput struct {
+	Matches   []SearchMatch `json:"matches"`
+	Truncated bool          `json:"truncated"`
+}
+
 type StartComponentsInput struct {
 	Refs []string `json:"refs"`
 }
@@ -342,6 +383,13 @@ type ExportProcfileOutput struct {
 	Procfile string `json:"procfile"`
 }
 
+type ExportComposeInput struct {
+}
+
+type ExportComposeOutput struct {
+	Compose string `json:"compose"`
+}
+
 type ReadFileInput struct {
 
 	// Relative to the workspace directory. May not traverse higher in the filesystem.
@@ -401,6 +449,18 @@ type ComponentDescription struct {
 	DependsOn []string `json:"dependsOn"`
 }
 
+type PlannedChange struct {
+
+	// One of 'create', 'update', 'rename', or 'delete'.
+	Action string `json:"action"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	// Previous name of a renamed component.
+	OldName *string `json:"oldName"`
+	// Unified diff of an updated component's spec.
+	SpecDiff string `json:"specDiff"`
+}
+
 type StreamDescription struct {
 	Name        string  `json:"name"`
 	LastEventAt *string `json:"lastEventAt"`
@@ -414,6 +474,12 @@ type Event struct {
 	Tags      map[string]string `json:"tags"`
 }
 
+type SearchMatch struct {
+	Event  Event   `json:"event"`
+	Before []Event `json:"before"`
+	After  []Event `json:"after"`
+}
+
 type ProcessDescription struct {
 	ID                  string            `json:"id"`
 	Provider            string            `json:"provider"`
@@ -426,6 +492,11 @@ type ProcessDescription struct {
 	ResidentMemory      *uint64           `json:"residentMemory"`
 	Ports               []uint32          `json:"ports"`
 	ChildrenExecutables []string          `json:"childrenExecutables"`
+	Restarts            int               `json:"restarts"`
+	MemoryUsage         *uint64           `json:"memoryUsage"`
+	CPUUsageSeconds     *float64          `json:"cpuUsageSeconds"`
+	Pids                *uint64           `json:"pids"`
+	OomKills            *uint64           `json:"oomKills"`
 }
 
 type VolumeDescription struct {
@@ -26,6 +26,13 @@ interface "builder" {
   }
 }
 
+# XXX Same story as above "process" interface.
+interface "readiness" {
+  method "await-ready" {
+    doc = "Blocks until the component is ready to serve its dependents."
+  }
+}
+
 interface "workspace" {
   # XXX This isn't quite right, since these interfaces return job-ids, but
   # the underlying controller methods are expected to be synchronous.
@@ -66,9 +73,21 @@ interface "workspace" {
     input "manifest" "*string" {
       doc = "Contents of the manifest file. Not required if manifest-path is provided."
     }
+    input "dry-run" "bool" {
+      doc = "If true, the planned changes are reported, but not performed."
+    }
+    input "profiles" "[]string" {
+      doc = "Active profiles. Components assigned to other profiles are created, but not started."
+    }
+    input "variables" "map[string]string" {
+      doc = "Values of manifest variables, overriding their defaults."
+    }
 
     output "warnings" "[]string" {}
-    output "job-id" "string" {}
+    output "job-id" "string" {
+      doc = "Empty for dry runs."
+    }
+    output "plan" "[]PlannedChange" {}
   }
 
   method "resolve" {
@@ -188,6 +207,9 @@ interface "workspace" {
 
     input "cursor" "*string" {}
     input "filter-str" "string" {}
+    input "tag-filter" "string" {
+      doc = "Space separated tag conditions, such as `level>=warn request_id=abc`. Operators are =, !=, <, <=, >, and >=."
+    }
     input "prev" "*int" {}
     input "next" "*int" {}
 
@@ -196,6 +218,22 @@ interface "workspace" {
     output "nextCursor" "string" {}
   }
 
+  method "search-events" {
+    doc = "Searches the messages of events in a set of streams. Returns the most recent matches, in chronological order."
+
+    input "streams" "[]string" {
+      doc = "If omitted, searches the streams of the workspace and all of its components."
+    }
+    input "query" "string" {}
+    input "since" "*string" {}
+    input "until" "*string" {}
+    input "context" "int" {}
+    input "limit" "*int" {}
+
+    output "matches" "[]SearchMatch" {}
+    output "truncated" "bool" {}
+  }
+
   method "start-components" {
     input "refs" "[]string" {}
     output "job-id" "string" {}
@@ -236,6 +274,12 @@ interface "workspace" {
     output "procfile" "string" {}
   }
 
+  method "export-compose" {
+    doc = "Exports containers, volumes, and networks as a compose file. Processes are exported to an x-exo extension."
+
+    output "compose" "string" {}
+  }
+
   method "read-file" {
     doc = "Read a file from disk."
 
@@ -285,6 +329,20 @@ struct "component-description" {
   field "depends-on" "[]string" {}
 }
 
+struct "planned-change" {
+  field "action" "string" {
+    doc = "One of 'create', 'update', 'rename', or 'delete'."
+  }
+  field "type" "string" {}
+  field "name" "string" {}
+  field "old-name" "*string" {
+    doc = "Previous name of a renamed component."
+  }
+  field "spec-diff" "string" {
+    doc = "Unified diff of an updated component's spec."
+  }
+}
+
 struct "stream-description" {
   field "name" "string" {}
   field "last-event-at" "*string" {}
@@ -298,6 +356,12 @@ struct "event" {
   field "tags" "map[string]string" {}
 }
 
+struct "search-match" {
+  field "event" "Event" {}
+  field "before" "[]Event" {}
+  field "after" "[]Event" {}
+}
+
 struct "process-description" {
   field "id" "string" {}
   field "provider" "string" {}
@@ -310,6 +374,13 @@ struct "process-description" {
   field "resident-memory" "*uint64" {}
   field "ports" "[]uint32" {}
   field "children-executables" "[]string" {}
+  field "restarts" "int" {}
+
+  # Usage of processes with resource limits, as accounted by their cgroup.
+  field "memory-usage" "*uint64" {}
+  field "cpu-usage-seconds" "*float64" {}
+  field "pids" "*uint64" {}
+  field "oom-kills" "*uint64" {}
 }
 
 struct "volume-description" {
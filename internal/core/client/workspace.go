@@ -58,6 +58,23 @@ func (c *Builder) Build(ctx context.Context, input *api.BuildInput) (output *api
 	return
 }
 
+type Readiness struct {
+	client *josh.Client
+}
+
+var _ api.Readiness = (*Readiness)(nil)
+
+func GetReadiness(client *josh.Client) *Readiness {
+	return &Readiness{
+		client: client,
+	}
+}
+
+func (c *Readiness) AwaitReady(ctx context.Context, input *api.AwaitReadyInput) (output *api.AwaitReadyOutput, err error) {
+	err = c.client.Invoke(ctx, "await-ready", input, &output)
+	return
+}
+
 type Workspace struct {
 	client *josh.Client
 }
@@ -185,6 +202,11 @@ func (c *Workspace) GetEvents(ctx context.Context, input *api.GetEventsInput) (o
 	return
 }
 
+func (c *Workspace) SearchEvents(ctx context.Context, input *api.SearchEventsInput) (output *api.SearchEventsOutput, err error) {
+	err = c.client.Invoke(ctx, "search-events", input, &output)
+	return
+}
+
 func (c *Workspace) StartComponents(ctx context.Context, input *api.StartComponentsInput) (output *api.StartComponentsOutput, err error) {
 	err = c.client.Invoke(ctx, "start-components", input, &output)
 	return
@@ -225,6 +247,11 @@ func (c *Workspace) ExportProcfile(ctx context.Context, input *api.ExportProcfil
 	return
 }
 
+func (c *Workspace) ExportCompose(ctx context.Context, input *api.ExportComposeInput) (output *api.ExportComposeOutput, err error) {
+	err = c.client.Invoke(ctx, "export-compose", input, &output)
+	return
+}
+
 func (c *Workspace) ReadFile(ctx context.Context, input *api.ReadFileInput) (output *api.ReadFileOutput, err error) {
 	err = c.client.Invoke(ctx, "read-file", input, &output)
 	return
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	state "github.com/deref/exo/internal/core/state/api"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/internal/util/httputil"
+	"github.com/deref/exo/internal/util/jsonutil"
+	"golang.org/x/net/websocket"
+)
+
+// AttachHandler relays the terminal of a process component over a websocket.
+// The component is identified by the "workspace" and "component" query
+// parameters. Binary messages are passed through unchanged to and from the
+// process supervisor, so use the framing of supervise.AttachMessage.
+type AttachHandler struct {
+	Store state.Store
+}
+
+func (h *AttachHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	query := req.URL.Query()
+	workspaceRef := query.Get("workspace")
+	componentRef := query.Get("component")
+	if workspaceRef == "" || componentRef == "" {
+		httputil.WriteError(w, req, errutil.NewHTTPError(http.StatusBadRequest, "workspace and component are required"))
+		return
+	}
+
+	resolved, err := h.Store.ResolveWorkspace(ctx, &state.ResolveWorkspaceInput{
+		Ref: workspaceRef,
+	})
+	if err != nil {
+		httputil.WriteError(w, req, fmt.Errorf("resolving workspace: %w", err))
+		return
+	}
+	if resolved.ID == nil {
+		httputil.WriteError(w, req, errutil.HTTPErrorf(http.StatusNotFound, "no such workspace: %q", workspaceRef))
+		return
+	}
+	described, err := h.Store.DescribeComponents(ctx, &state.DescribeComponentsInput{
+		WorkspaceID: *resolved.ID,
+		Refs:        []string{componentRef},
+	})
+	if err != nil {
+		httputil.WriteError(w, req, fmt.Errorf("describing components: %w", err))
+		return
+	}
+	if len(described.Components) == 0 {
+		// Components of stacks are not in this store, and their processes run
+		// without a terminal to attach to.
+		httputil.WriteError(w, req, errutil.HTTPErrorf(http.StatusNotFound, "no such workspace process: %q", componentRef))
+		return
+	}
+
+	var componentState struct {
+		AttachSocket string `json:"attachSocket"`
+	}
+	if err := jsonutil.UnmarshalString(described.Components[0].State, &componentState); err != nil {
+		httputil.WriteError(w, req, fmt.Errorf("unmarshalling component state: %w", err))
+		return
+	}
+	if componentState.AttachSocket == "" {
+		httputil.WriteError(w, req, errutil.HTTPErrorf(http.StatusConflict, "component %q is not running with a tty", componentRef))
+		return
+	}
+	// Dial before upgrading, so that stopped processes are reported as errors.
+	conn, err := net.Dial("unix", componentState.AttachSocket)
+	if err != nil {
+		httputil.WriteError(w, req, errutil.HTTPErrorf(http.StatusConflict, "component %q is not running", componentRef))
+		return
+	}
+
+	srv := websocket.Server{
+		Handshake: checkSameOrigin,
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			ws.PayloadType = websocket.BinaryFrame
+			go func() {
+				_, _ = io.Copy(conn, ws)
+				_ = conn.Close()
+			}()
+			_, _ = io.Copy(ws, conn)
+		},
+	}
+	srv.ServeHTTP(w, req)
+	_ = conn.Close()
+}
+
+// Browsers send the origin of the page opening a websocket, which must be
+// the daemon itself. Other clients need not send an origin.
+func checkSameOrigin(cfg *websocket.Config, req *http.Request) error {
+	origin, err := websocket.Origin(cfg, req)
+	if err != nil {
+		return err
+	}
+	if origin != nil && origin.Host != req.Host {
+		return fmt.Errorf("cross-origin request from %s", origin)
+	}
+	cfg.Origin = origin
+	return nil
+}
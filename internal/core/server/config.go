@@ -12,6 +12,7 @@ import (
 
 	"github.com/deref/exo/internal/core/api"
 	"github.com/deref/exo/internal/manifest"
+	"github.com/deref/exo/internal/manifest/compose"
 	"github.com/deref/exo/internal/manifest/exohcl"
 	"github.com/deref/exo/internal/manifest/exohcl/hclgen"
 	"github.com/deref/exo/internal/util/errutil"
@@ -26,6 +27,7 @@ type manifestCandidate struct {
 }
 
 var manifestCandidates = []manifestCandidate{
+	{"cue", "exo.cue"},
 	{"exo", "exo.hcl"},
 	{"compose", "compose.yaml"},
 	{"compose", "compose.yml"},
@@ -44,6 +46,39 @@ func (ws *Workspace) tryLoadManifest(ctx context.Context) *exohcl.Manifest {
 	return m
 }
 
+// Reads a manifest file. Compose files are merged with their override file, if
+// any, and extended services are resolved.
+func readManifestFile(rootDir string, format string, manifestPath string) ([]byte, error) {
+	if format == "" {
+		format = manifest.GuessFormat(manifestPath)
+	}
+	if format != "compose" {
+		bs, err := ioutil.ReadFile(manifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading manifest file: %w", err)
+		}
+		return bs, nil
+	}
+	manifestPaths := []string{manifestPath}
+	overridePath := compose.OverrideFilename(manifestPath)
+	if exists, _ := osutil.Exists(overridePath); exists {
+		manifestPaths = append(manifestPaths, overridePath)
+	}
+	loader := &compose.Loader{
+		ReadFile: func(name string) ([]byte, error) {
+			if !pathutil.HasFilePathPrefix(name, rootDir) {
+				return nil, fmt.Errorf("cannot read %s outside of workspace root", name)
+			}
+			return ioutil.ReadFile(name)
+		},
+	}
+	bs, err := loader.Load(manifestPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("loading compose files: %w", err)
+	}
+	return bs, nil
+}
+
 func (ws *Workspace) loadManifest(ctx context.Context, rootDir string, input *api.ApplyInput) (*exohcl.Manifest, error) {
 	manifestString := ""
 	manifestPath := ""
@@ -66,9 +101,9 @@ func (ws *Workspace) loadManifest(ctx context.Context, rootDir string, input *ap
 			return nil, errors.New("cannot read manifest outside of workspace root")
 		}
 
-		bs, err := ioutil.ReadFile(manifestPath)
+		bs, err := readManifestFile(rootDir, input.Format, manifestPath)
 		if err != nil {
-			return nil, fmt.Errorf("reading manifest file: %w", err)
+			return nil, err
 		}
 		manifestString = string(bs)
 	} else {
@@ -80,7 +115,8 @@ func (ws *Workspace) loadManifest(ctx context.Context, rootDir string, input *ap
 	workspaceName = exohcl.MangleName(workspaceName)
 
 	analysisContext := &exohcl.AnalysisContext{
-		Context: ctx,
+		Context:   ctx,
+		Variables: input.Variables,
 	}
 	loader := &manifest.Loader{
 		WorkspaceName: workspaceName,
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/deref/exo/internal/core/api"
+	state "github.com/deref/exo/internal/core/state/api"
+	"github.com/deref/exo/internal/filewatch"
+	"github.com/deref/exo/internal/providers/unix/components/process"
+	"github.com/deref/exo/internal/util/contextutil"
+	"github.com/deref/exo/internal/util/jsonutil"
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/deref/exo/internal/util/osutil"
+)
+
+// FileWatchManager owns the file watches of process components. Watches
+// outlive the requests that create them, so they are tracked here, keyed by
+// component ID, rather than on any one Workspace.
+type FileWatchManager struct {
+	Logger logging.Logger
+
+	mu      sync.Mutex
+	watches map[string]*fileWatch
+}
+
+type fileWatch struct {
+	spec   string
+	cancel context.CancelFunc
+}
+
+func NewFileWatchManager(logger logging.Logger) *FileWatchManager {
+	return &FileWatchManager{
+		Logger:  logger,
+		watches: make(map[string]*fileWatch),
+	}
+}
+
+// watch starts running w for the given component, replacing any existing
+// watch. Watches for an unchanged spec are left running, so that restarts
+// triggered by a watch do not interrupt it.
+func (m *FileWatchManager) watch(ctx context.Context, componentID string, spec string, w *filewatch.Watcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing := m.watches[componentID]; existing != nil {
+		if existing.spec == spec {
+			return
+		}
+		existing.cancel()
+	}
+	if w.Logger == nil {
+		w.Logger = m.Logger
+	}
+	ctx, cancel := context.WithCancel(contextutil.WithoutCancel(ctx))
+	m.watches[componentID] = &fileWatch{
+		spec:   spec,
+		cancel: cancel,
+	}
+	go func() {
+		if err := w.Run(ctx); err != nil {
+			m.Logger.Infof("file watch for %s failed: %v", componentID, err)
+		}
+	}()
+}
+
+func (m *FileWatchManager) unwatch(componentID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing := m.watches[componentID]; existing != nil {
+		existing.cancel()
+		delete(m.watches, componentID)
+	}
+}
+
+// RestoreFileWatches resumes watching files for the running process
+// components of every workspace. Watches are held in memory, so must be
+// restored when the daemon starts.
+func RestoreFileWatches(ctx context.Context, cfg *Config) error {
+	if cfg.FileWatches == nil {
+		return nil
+	}
+	output, err := cfg.Store.DescribeWorkspaces(ctx, &state.DescribeWorkspacesInput{})
+	if err != nil {
+		return fmt.Errorf("describing workspaces: %w", err)
+	}
+	for _, workspace := range output.Workspaces {
+		ws := &Workspace{
+			ID:          workspace.ID,
+			VarDir:      cfg.VarDir,
+			Store:       cfg.Store,
+			SyslogPort:  cfg.SyslogPort,
+			Logger:      cfg.Logger,
+			Docker:      cfg.Docker,
+			TaskTracker: cfg.TaskTracker,
+			EsvClient:   cfg.EsvClient,
+			FileWatches: cfg.FileWatches,
+		}
+		components, err := ws.DescribeComponents(ctx, &api.DescribeComponentsInput{
+			Types: []string{"process"},
+		})
+		if err != nil {
+			return fmt.Errorf("describing components of workspace %s: %w", workspace.ID, err)
+		}
+		for _, desc := range components.Components {
+			var processState process.State
+			if err := jsonutil.UnmarshalStringOrEmpty(desc.State, &processState); err != nil {
+				cfg.Logger.Infof("unmarshalling state of %s: %v", desc.Name, err)
+				continue
+			}
+			if !osutil.IsValidPid(processState.Pid) {
+				continue
+			}
+			ws.syncFileWatch(ctx, desc, &api.StartInput{})
+		}
+	}
+	return nil
+}
+
+// syncFileWatch starts or stops the file watch for a process component after
+// it has been sent a control message.
+func (ws *Workspace) syncFileWatch(ctx context.Context, desc api.ComponentDescription, input any) {
+	if ws.FileWatches == nil || desc.Type != "process" {
+		return
+	}
+	switch input.(type) {
+	case *api.InitializeInput, *api.StartInput, *api.RestartInput:
+	case *api.StopInput, *api.DisposeInput:
+		ws.FileWatches.unwatch(desc.ID)
+		return
+	default:
+		return
+	}
+
+	var spec process.Spec
+	if err := jsonutil.UnmarshalStringOrEmpty(desc.Spec, &spec); err != nil {
+		ws.Logger.Infof("unmarshalling spec of %s: %v", desc.Name, err)
+		return
+	}
+	if spec.Watch == nil {
+		ws.FileWatches.unwatch(desc.ID)
+		return
+	}
+	workspace, err := ws.describe(ctx)
+	if err != nil {
+		ws.Logger.Infof("describing workspace: %v", err)
+		return
+	}
+	watcher, err := process.NewWatcher(workspace.Root, &spec, func(paths []string) {
+		ws.handleWatchedChange(contextutil.WithoutCancel(ctx), desc.ID, spec.Watch, paths)
+	})
+	if err != nil {
+		ws.logEventf(ctx, "error watching files for %s: %v", desc.Name, err)
+		return
+	}
+	ws.FileWatches.watch(ctx, desc.ID, desc.Spec, watcher)
+}
+
+func (ws *Workspace) handleWatchedChange(ctx context.Context, id string, watch *process.Watch, paths []string) {
+	describeOutput, err := ws.DescribeComponents(ctx, &api.DescribeComponentsInput{
+		Refs: []string{id},
+	})
+	if err != nil || len(describeOutput.Components) == 0 {
+		// The component is gone, so the watch is no longer needed.
+		ws.FileWatches.unwatch(id)
+		return
+	}
+	desc := describeOutput.Components[0]
+
+	changed := strings.Join(paths, ", ")
+	if len(paths) > 3 {
+		changed = fmt.Sprintf("%s and %d more", strings.Join(paths[:3], ", "), len(paths)-3)
+	}
+	var input any
+	if watch.Signal != "" {
+		ws.logEventf(ctx, "%s changed; signalling %s with %s", changed, desc.Name, watch.Signal)
+		input = &api.SignalInput{Signal: watch.Signal}
+	} else {
+		ws.logEventf(ctx, "%s changed; restarting %s", changed, desc.Name)
+		input = &api.RestartInput{}
+	}
+	if err := ws.control(ctx, desc, input); err != nil {
+		ws.logEventf(ctx, "error restarting %s: %v", desc.Name, err)
+	}
+}
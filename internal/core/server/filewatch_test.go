@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	state "github.com/deref/exo/internal/core/state/api"
+	"github.com/deref/exo/internal/core/state/statefile"
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreFileWatches(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := statefile.New(filepath.Join(dir, "state.json"))
+	_, err := store.AddWorkspace(ctx, &state.AddWorkspaceInput{ID: "ws", Root: dir})
+	require.NoError(t, err)
+
+	watchSpec := `{"program":"server","watch":{"include":["*.go"]}}`
+	for _, component := range []struct {
+		ID   string
+		Spec string
+		Pid  int
+	}{
+		{"running", watchSpec, os.Getpid()},
+		{"stopped", watchSpec, 0},
+		{"unwatched", `{"program":"server"}`, os.Getpid()},
+	} {
+		_, err := store.AddComponent(ctx, &state.AddComponentInput{
+			WorkspaceID: "ws",
+			ID:          component.ID,
+			Name:        component.ID,
+			Type:        "process",
+			Spec:        component.Spec,
+		})
+		require.NoError(t, err)
+		_, err = store.PatchComponent(ctx, &state.PatchComponentInput{
+			ID:    component.ID,
+			State: fmt.Sprintf(`{"pid":%d}`, component.Pid),
+		})
+		require.NoError(t, err)
+	}
+
+	watches := NewFileWatchManager(logging.Default())
+	require.NoError(t, RestoreFileWatches(ctx, &Config{
+		Store:       store,
+		Logger:      logging.Default(),
+		FileWatches: watches,
+	}))
+	defer watches.unwatch("running")
+
+	watches.mu.Lock()
+	defer watches.mu.Unlock()
+	assert.Len(t, watches.watches, 1)
+	assert.Contains(t, watches.watches, "running")
+}
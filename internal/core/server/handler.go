@@ -21,6 +21,7 @@ type Config struct {
 	Docker      *docker.Client
 	Logger      logging.Logger
 	TaskTracker *task.TaskTracker
+	FileWatches *FileWatchManager
 	TokenClient token.TokenClient
 	EsvClient   esv.EsvClient
 	ExoVersion  string
@@ -52,5 +53,9 @@ func BuildRootMux(prefix string, cfg *Config) *http.ServeMux {
 		Service: cfg.Service,
 	}, version, auth))
 
+	mux.Handle(prefix+"attach", applyMiddleware(&AttachHandler{
+		Store: cfg.Store,
+	}, version, auth))
+
 	return mux
 }
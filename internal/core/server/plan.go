@@ -0,0 +1,107 @@
+package server
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/deref/exo/internal/util/yamlutil"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// planApply describes the changes that applying a manifest would make to the
+// existing components. Components that keep their name are always recreated
+// by Apply, so they are reported as updates even when their spec is unchanged.
+// A removed component and an added component with the same type and spec are
+// reported as a rename.
+func planApply(oldComponents map[string]api.ComponentDescription, newComponents []*exohcl.Component) []api.PlannedChange {
+	newNames := make(map[string]bool, len(newComponents))
+	for _, newComponent := range newComponents {
+		newNames[newComponent.Name] = true
+	}
+
+	var removedNames []string
+	for name := range oldComponents {
+		if !newNames[name] {
+			removedNames = append(removedNames, name)
+		}
+	}
+	sort.Strings(removedNames)
+	renamed := make(map[string]bool)
+
+	var plan []api.PlannedChange
+	for _, newComponent := range newComponents {
+		change := api.PlannedChange{
+			Type: newComponent.Type,
+			Name: newComponent.Name,
+		}
+		if oldComponent, exists := oldComponents[newComponent.Name]; exists {
+			change.Action = "update"
+			change.SpecDiff = diffSpecs(oldComponent.Spec, newComponent.Spec)
+		} else if oldName := findRenamedComponent(oldComponents, removedNames, renamed, newComponent); oldName != "" {
+			renamed[oldName] = true
+			change.Action = "rename"
+			change.OldName = &oldName
+		} else {
+			change.Action = "create"
+		}
+		plan = append(plan, change)
+	}
+
+	for _, name := range removedNames {
+		if renamed[name] {
+			continue
+		}
+		plan = append(plan, api.PlannedChange{
+			Action: "delete",
+			Type:   oldComponents[name].Type,
+			Name:   name,
+		})
+	}
+	return plan
+}
+
+func findRenamedComponent(oldComponents map[string]api.ComponentDescription, removedNames []string, renamed map[string]bool, newComponent *exohcl.Component) string {
+	newSpec := normalizeSpec(newComponent.Spec)
+	for _, name := range removedNames {
+		oldComponent := oldComponents[name]
+		if renamed[name] || oldComponent.Type != newComponent.Type {
+			continue
+		}
+		if normalizeSpec(oldComponent.Spec) == newSpec {
+			return name
+		}
+	}
+	return ""
+}
+
+// Specs may be stored as JSON or YAML. Normalizing to YAML yields one line per
+// field, which makes for readable diffs.
+func normalizeSpec(spec string) string {
+	var v any
+	if err := yamlutil.UnmarshalString(spec, &v); err != nil {
+		return spec
+	}
+	normalized, err := yamlutil.MarshalString(v)
+	if err != nil {
+		return spec
+	}
+	return normalized
+}
+
+// Returns a unified diff of two component specs, or the empty string if they
+// are equivalent.
+func diffSpecs(oldSpec, newSpec string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(normalizeSpec(oldSpec)),
+		B:        difflib.SplitLines(normalizeSpec(newSpec)),
+		FromFile: "old",
+		ToFile:   "new",
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(diff, "\n")
+}
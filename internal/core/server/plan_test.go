@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanApply(t *testing.T) {
+	oldComponents := map[string]api.ComponentDescription{
+		"web":    {Name: "web", Type: "process", Spec: `{"program":"node","arguments":["server.js"]}`},
+		"worker": {Name: "worker", Type: "process", Spec: `{"program":"worker"}`},
+		"cache":  {Name: "cache", Type: "container", Spec: "image: redis\n"},
+	}
+	newComponents := []*exohcl.Component{
+		{Name: "web", Type: "process", Spec: `{"program":"node","arguments":["index.js"]}`},
+		{Name: "jobs", Type: "process", Spec: `{"program":"worker"}`},
+		{Name: "db", Type: "container", Spec: "image: postgres\n"},
+	}
+	plan := planApply(oldComponents, newComponents)
+	if !assert.Len(t, plan, 4) {
+		return
+	}
+
+	assert.Equal(t, "update", plan[0].Action)
+	assert.Equal(t, "web", plan[0].Name)
+	assert.Contains(t, plan[0].SpecDiff, "-  - server.js")
+	assert.Contains(t, plan[0].SpecDiff, "+  - index.js")
+
+	assert.Equal(t, "rename", plan[1].Action)
+	assert.Equal(t, "jobs", plan[1].Name)
+	if assert.NotNil(t, plan[1].OldName) {
+		assert.Equal(t, "worker", *plan[1].OldName)
+	}
+
+	assert.Equal(t, "create", plan[2].Action)
+	assert.Equal(t, "db", plan[2].Name)
+
+	assert.Equal(t, "delete", plan[3].Action)
+	assert.Equal(t, "cache", plan[3].Name)
+}
+
+func TestDiffSpecsEquivalent(t *testing.T) {
+	assert.Equal(t, "", diffSpecs(`{"program":"node"}`, "program: node\n"))
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/deref/exo/internal/core/api"
+	josh "github.com/deref/exo/internal/josh/server"
+	"github.com/deref/exo/internal/util/errutil"
+)
+
+// readinessGate tracks components that failed to start or to become ready,
+// so that the components which depend on them are not started.
+type readinessGate struct {
+	mu       sync.Mutex
+	notReady map[string]error
+}
+
+func newReadinessGate() *readinessGate {
+	return &readinessGate{
+		notReady: make(map[string]error),
+	}
+}
+
+func (g *readinessGate) markNotReady(name string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.notReady[name] = err
+}
+
+func (g *readinessGate) checkDependencies(dependencies []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, dependency := range dependencies {
+		if err := g.notReady[dependency]; err != nil {
+			return fmt.Errorf("dependency %q not ready: %w", dependency, err)
+		}
+	}
+	return nil
+}
+
+// awaitsReadiness reports whether a control message leaves a component
+// running, and so should be followed by awaiting the component's readiness.
+func awaitsReadiness(msg any) bool {
+	switch msg.(type) {
+	case *api.InitializeInput, *api.StartInput, *api.RestartInput:
+		return true
+	default:
+		return false
+	}
+}
+
+// awaitReady blocks until the identified component reports that it is ready.
+// Components that do not implement api.Readiness are considered ready
+// immediately.
+func (ws *Workspace) awaitReady(ctx context.Context, id string) error {
+	describeOutput, err := ws.DescribeComponents(ctx, &api.DescribeComponentsInput{
+		Refs: []string{id},
+	})
+	if err != nil {
+		return fmt.Errorf("describing components: %w", err)
+	}
+	if len(describeOutput.Components) == 0 {
+		return errutil.HTTPErrorf(http.StatusNotFound, "component not found: %q", id)
+	}
+	ctrl := ws.newController(ctx, describeOutput.Components[0])
+	if _, ok := ctrl.(api.Readiness); !ok {
+		return nil
+	}
+	if err := ctrl.InitResource(); err != nil {
+		return err
+	}
+	_, err = josh.Send(ctx, ctrl, &api.AwaitReadyInput{})
+	return err
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/deref/exo/internal/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessGate(t *testing.T) {
+	gate := newReadinessGate()
+	assert.NoError(t, gate.checkDependencies([]string{"db", "cache"}))
+
+	gate.markNotReady("db", errors.New("timed out"))
+	assert.NoError(t, gate.checkDependencies([]string{"cache"}))
+	assert.EqualError(t, gate.checkDependencies([]string{"cache", "db"}), `dependency "db" not ready: timed out`)
+}
+
+func TestAwaitsReadiness(t *testing.T) {
+	assert.True(t, awaitsReadiness(&api.StartInput{}))
+	assert.True(t, awaitsReadiness(&api.RestartInput{}))
+	assert.False(t, awaitsReadiness(&api.StopInput{}))
+}
@@ -20,6 +20,7 @@ import (
 	eventd "github.com/deref/exo/internal/eventd/api"
 	"github.com/deref/exo/internal/gensym"
 	josh "github.com/deref/exo/internal/josh/server"
+	composeimport "github.com/deref/exo/internal/manifest/compose"
 	"github.com/deref/exo/internal/manifest/exohcl"
 	"github.com/deref/exo/internal/manifest/procfile"
 	"github.com/deref/exo/internal/providers/core"
@@ -29,6 +30,7 @@ import (
 	"github.com/deref/exo/internal/providers/docker/components/container"
 	"github.com/deref/exo/internal/providers/docker/components/network"
 	"github.com/deref/exo/internal/providers/docker/components/volume"
+	"github.com/deref/exo/internal/providers/docker/compose"
 	"github.com/deref/exo/internal/providers/unix/components/process"
 	"github.com/deref/exo/internal/task"
 	"github.com/deref/exo/internal/util/errutil"
@@ -38,6 +40,7 @@ import (
 	dockerclient "github.com/docker/docker/client"
 	"github.com/hashicorp/hcl/v2"
 	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
 )
 
 type Workspace struct {
@@ -49,6 +52,7 @@ type Workspace struct {
 	Docker      *dockerclient.Client
 	TaskTracker *task.TaskTracker
 	EsvClient   esv.EsvClient
+	FileWatches *FileWatchManager
 }
 
 var _ api.Workspace = &Workspace{}
@@ -161,10 +165,6 @@ func (ws *Workspace) Apply(ctx context.Context, input *api.ApplyInput) (*api.App
 		oldComponents[oldComponent.Name] = oldComponent
 	}
 
-	// TODO: Handle partial failures.
-	job := ws.TaskTracker.StartTask(ctx, "applying")
-	ws.logEventf(ctx, "applying manifest... %s", job.JobID())
-
 	// The algorithm for applying a new manifest is as follows:
 	// 1. Build dependency graph for the new manifest, allComponents.
 	// 2. Create empty dependency graphs for deletions, deleteGraph, and for creations, createGraph.
@@ -195,6 +195,28 @@ func (ws *Workspace) Apply(ctx context.Context, input *api.ApplyInput) (*api.App
 		}
 	}
 
+	// 4. Checked before any work is started, so that a failed check does not
+	// leave behind a dangling job.
+	unmetDeps := unmetDeletionDependencies(oldComponents, allComponents)
+	if len(unmetDeps) > 0 {
+		return nil, fmt.Errorf("would remove components that are still depended on: %s", strings.Join(unmetDeps, ", "))
+	}
+
+	warnings := make([]string, len(diags))
+	for i, diag := range diags {
+		warnings[i] = diag.Error()
+	}
+	if input.DryRun {
+		return &api.ApplyOutput{
+			Warnings: warnings,
+			Plan:     planApply(oldComponents, manifestComponents),
+		}, nil
+	}
+
+	// TODO: Handle partial failures.
+	job := ws.TaskTracker.StartTask(ctx, "applying")
+	ws.logEventf(ctx, "applying manifest... %s", job.JobID())
+
 	// 2.
 	createGraph := deps.New()
 	deleteGraph := deps.New()
@@ -219,14 +241,26 @@ func (ws *Workspace) Apply(ctx context.Context, input *api.ApplyInput) (*api.App
 		}
 	}
 
-	// 4.
-	unmetDeps := deleteGraph.UnmetDependencies()
-	if len(unmetDeps) > 0 {
-		return nil, fmt.Errorf("would remove components that are still depended on: %s", strings.Join(unmetDeps, ", "))
-	}
-
 	// 5.
 	updateSet := make(map[string]struct{})
+	gate := newReadinessGate()
+	createAndAwaitReady := func(t *task.Task, c *exohcl.Component) error {
+		if err := gate.checkDependencies(c.DependsOn); err != nil {
+			gate.markNotReady(c.Name, err)
+			return err
+		}
+		id := gensym.RandomBase32()
+		// Components outside the active profiles are created, but not started.
+		enabled := c.Enabled(input.Profiles)
+		err := ws.createComponent(t, manifestComponentToCreate(c), id, !enabled)
+		if err == nil && enabled {
+			err = ws.awaitReady(t, id)
+		}
+		if err != nil {
+			gate.markNotReady(c.Name, err)
+		}
+		return err
+	}
 
 	recreateComponentOnce := func(name string, oldComponent api.ComponentDescription, newComponent *exohcl.Component) {
 		// 6.1.1.
@@ -250,7 +284,7 @@ func (ws *Workspace) Apply(ctx context.Context, input *api.ApplyInput) (*api.App
 			task: job.CreateChild("re-creating " + name),
 			run: func(t *task.Task) error {
 				// Should the replacement component get the old component's ID?
-				return ws.createComponent(t, manifestComponentToCreate(newComponent), gensym.RandomBase32())
+				return createAndAwaitReady(t, newComponent)
 			},
 		})
 		for _, dependency := range newComponent.DependsOn {
@@ -281,7 +315,7 @@ func (ws *Workspace) Apply(ctx context.Context, input *api.ApplyInput) (*api.App
 				name: name,
 				task: job.CreateChild("adding " + name),
 				run: func(t *task.Task) error {
-					return ws.createComponent(t, manifestComponentToCreate(newComponent), gensym.RandomBase32())
+					return createAndAwaitReady(t, newComponent)
 				},
 			})
 			for _, dependency := range newComponent.DependsOn {
@@ -298,14 +332,28 @@ func (ws *Workspace) Apply(ctx context.Context, input *api.ApplyInput) (*api.App
 		executeRunTasks(createGraph)
 	}()
 
-	output := api.ApplyOutput{
-		Warnings: make([]string, len(diags)),
+	return &api.ApplyOutput{
+		Warnings: warnings,
 		JobID:    job.ID(),
+	}, nil
+}
+
+// Builds the inverted deletion graph described by Apply's algorithm and returns
+// its unmet dependencies.
+func unmetDeletionDependencies(oldComponents map[string]api.ComponentDescription, allComponents *deps.Graph) []string {
+	deleteGraph := deps.New()
+	for name, oldComponent := range oldComponents {
+		if allComponents.HasNode(name) {
+			continue
+		}
+		deleteGraph.AddNode(&runTaskNode{
+			name: name,
+		})
+		for _, dependency := range oldComponent.DependsOn {
+			deleteGraph.AddEdge(dependency, name)
+		}
 	}
-	for i, diag := range diags {
-		output.Warnings[i] = diag.Error()
-	}
-	return &output, nil
+	return deleteGraph.UnmetDependencies()
 }
 
 func executeRunTasks(g *deps.Graph) {
@@ -405,6 +453,7 @@ func (ws *Workspace) newController(ctx context.Context, desc api.ComponentDescri
 		return &process.Process{
 			ComponentBase: base,
 			SyslogPort:    ws.SyslogPort,
+			VarDir:        ws.VarDir,
 		}
 
 	case "container":
@@ -456,7 +505,7 @@ func (ws *Workspace) CreateComponent(ctx context.Context, input *api.CreateCompo
 	go func() {
 		defer job.Finish()
 
-		err := ws.createComponent(ctx, input, id)
+		err := ws.createComponent(ctx, input, id, false)
 		if err != nil {
 			ws.logEventf(ctx, "error creating %s: %v", input.Name, err)
 			job.Fail(err)
@@ -471,7 +520,7 @@ func (ws *Workspace) CreateComponent(ctx context.Context, input *api.CreateCompo
 	}, nil
 }
 
-func (ws *Workspace) createComponent(ctx context.Context, input *api.CreateComponentInput, id string) error {
+func (ws *Workspace) createComponent(ctx context.Context, input *api.CreateComponentInput, id string, stopped bool) error {
 	if err := exohcl.ValidateName(input.Name); err != nil {
 		return errutil.HTTPErrorf(http.StatusBadRequest, "component name %q invalid: %w", input.Name, err)
 	}
@@ -499,7 +548,8 @@ func (ws *Workspace) createComponent(ctx context.Context, input *api.CreateCompo
 		DependsOn: input.DependsOn,
 	}
 	return ws.control(ctx, desc, &api.InitializeInput{
-		Spec: input.Spec,
+		Spec:    input.Spec,
+		Stopped: stopped,
 	})
 }
 
@@ -692,25 +742,36 @@ func (ws *Workspace) SetComponentState(ctx context.Context, input *api.SetCompon
 	return &api.SetComponentStateOutput{}, nil
 }
 
+// Returns the given stream names, or if nil, the streams of the workspace and
+// all of its components.
+func (ws *Workspace) eventStreams(ctx context.Context, streamNames []string) ([]string, error) {
+	if streamNames != nil {
+		return streamNames, nil
+	}
+	describe := allComponentsQuery.describeComponentsInput(ws)
+	components, err := ws.DescribeComponents(ctx, describe)
+	if err != nil {
+		return nil, fmt.Errorf("describing components: %w", err)
+	}
+	streamNames = make([]string, 1+len(components.Components))
+	streamNames[0] = ws.ID
+	for i, component := range components.Components {
+		streamNames[i+1] = component.ID
+	}
+	return streamNames, nil
+}
+
 func (ws *Workspace) GetEvents(ctx context.Context, input *api.GetEventsInput) (*api.GetEventsOutput, error) {
-	streamNames := input.Streams
-	if streamNames == nil {
-		describe := allComponentsQuery.describeComponentsInput(ws)
-		components, err := ws.DescribeComponents(ctx, describe)
-		if err != nil {
-			return nil, fmt.Errorf("describing components: %w", err)
-		}
-		streamNames = make([]string, 1+len(components.Components))
-		streamNames[0] = ws.ID
-		for i, component := range components.Components {
-			streamNames[i+1] = component.ID
-		}
+	streamNames, err := ws.eventStreams(ctx, input.Streams)
+	if err != nil {
+		return nil, err
 	}
 	eventStore := log.CurrentEventStore(ctx)
 	storeOutput, err := eventStore.GetEvents(ctx, &eventd.GetEventsInput{
 		Streams:   streamNames,
 		Cursor:    input.Cursor,
 		FilterStr: input.FilterStr,
+		TagFilter: input.TagFilter,
 		Prev:      input.Prev,
 		Next:      input.Next,
 	})
@@ -718,17 +779,57 @@ func (ws *Workspace) GetEvents(ctx context.Context, input *api.GetEventsInput) (
 		return nil, err
 	}
 	output := api.GetEventsOutput{
-		Items:      make([]api.Event, len(storeOutput.Items)),
+		Items:      eventsFromStore(storeOutput.Items),
 		PrevCursor: storeOutput.PrevCursor,
 		NextCursor: storeOutput.NextCursor,
 	}
-	for i, storeEvent := range storeOutput.Items {
-		output.Items[i] = api.Event{
-			ID:        storeEvent.ID,
-			Stream:    storeEvent.Stream,
-			Timestamp: storeEvent.Timestamp,
-			Message:   storeEvent.Message,
-			Tags:      storeEvent.Tags,
+	return &output, nil
+}
+
+func eventFromStore(storeEvent eventd.Event) api.Event {
+	return api.Event{
+		ID:        storeEvent.ID,
+		Stream:    storeEvent.Stream,
+		Timestamp: storeEvent.Timestamp,
+		Message:   storeEvent.Message,
+		Tags:      storeEvent.Tags,
+	}
+}
+
+func eventsFromStore(storeEvents []eventd.Event) []api.Event {
+	events := make([]api.Event, len(storeEvents))
+	for i, storeEvent := range storeEvents {
+		events[i] = eventFromStore(storeEvent)
+	}
+	return events
+}
+
+func (ws *Workspace) SearchEvents(ctx context.Context, input *api.SearchEventsInput) (*api.SearchEventsOutput, error) {
+	streamNames, err := ws.eventStreams(ctx, input.Streams)
+	if err != nil {
+		return nil, err
+	}
+	eventStore := log.CurrentEventStore(ctx)
+	storeOutput, err := eventStore.SearchEvents(ctx, &eventd.SearchEventsInput{
+		Streams: streamNames,
+		Query:   input.Query,
+		Since:   input.Since,
+		Until:   input.Until,
+		Context: input.Context,
+		Limit:   input.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	output := api.SearchEventsOutput{
+		Matches:   make([]api.SearchMatch, len(storeOutput.Matches)),
+		Truncated: storeOutput.Truncated,
+	}
+	for i, match := range storeOutput.Matches {
+		output.Matches[i] = api.SearchMatch{
+			Event:  eventFromStore(match.Event),
+			Before: eventsFromStore(match.Before),
+			After:  eventsFromStore(match.After),
 		}
 	}
 	return &output, nil
@@ -873,7 +974,7 @@ func (ws *Workspace) DescribeProcesses(ctx context.Context, input *api.DescribeP
 			// XXX Violates component state encapsulation.
 			switch component.Type {
 			case "process":
-				desc, err = process.GetProcessDescription(ctx, component)
+				desc, err = process.GetProcessDescription(ctx, ws.Logger, component)
 			case "container":
 				desc, err = container.GetProcessDescription(ctx, ws.Docker, component)
 			}
@@ -969,6 +1070,69 @@ func (ws *Workspace) ExportProcfile(ctx context.Context, input *api.ExportProcfi
 	}, nil
 }
 
+func (ws *Workspace) ExportCompose(ctx context.Context, input *api.ExportComposeInput) (*api.ExportComposeOutput, error) {
+	description, err := ws.describe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("describing workspace: %w", err)
+	}
+	components, err := ws.DescribeComponents(ctx, &api.DescribeComponentsInput{
+		Types: []string{"container", "volume", "network", "process"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing components: %w", err)
+	}
+
+	var project compose.Project
+	var processes []composeimport.Process
+	for _, component := range components.Components {
+		// Specs are decoded without interpolation, so that variable references
+		// are preserved in the export.
+		var err error
+		switch component.Type {
+		case "container":
+			var spec container.Spec
+			err = yaml.Unmarshal([]byte(component.Spec), &spec)
+			spec.Key = component.Name
+			project.Services = append(project.Services, spec)
+		case "volume":
+			var spec volume.Spec
+			err = yaml.Unmarshal([]byte(component.Spec), &spec)
+			spec.Key = component.Name
+			project.Volumes = append(project.Volumes, spec)
+		case "network":
+			var spec network.Spec
+			err = yaml.Unmarshal([]byte(component.Spec), &spec)
+			spec.Key = component.Name
+			project.Networks = append(project.Networks, spec)
+		case "process":
+			var spec process.Spec
+			err = jsonutil.UnmarshalStringOrEmpty(component.Spec, &spec)
+			processes = append(processes, composeimport.Process{
+				Name:        component.Name,
+				Directory:   spec.Directory,
+				Program:     spec.Program,
+				Arguments:   spec.Arguments,
+				Environment: spec.Environment,
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding spec of %s %q: %w", component.Type, component.Name, err)
+		}
+	}
+
+	// TODO: Get official name from workspace description.
+	exporter := &composeimport.Exporter{
+		ProjectName: exohcl.MangleName(path.Base(description.Root)),
+	}
+	var export bytes.Buffer
+	if err := exporter.Export(&export, &project, processes); err != nil {
+		return nil, fmt.Errorf("generating compose file: %w", err)
+	}
+	return &api.ExportComposeOutput{
+		Compose: export.String(),
+	}, nil
+}
+
 func (ws *Workspace) ReadFile(ctx context.Context, input *api.ReadFileInput) (*api.ReadFileOutput, error) {
 	resolvedPath, err := ws.resolveWorkspacePath(ctx, input.Path)
 	if err != nil {
@@ -1038,7 +1202,9 @@ func (ws *Workspace) goControlComponents(t *task.Task, query componentQuery, mak
 		return
 	}
 
-	// Build graph of tasks to run.
+	// Build graph of tasks to run. When starting components in dependency
+	// order, each component must become ready before its dependents start.
+	gate := newReadinessGate()
 	runGraph := deps.New()
 	for _, component := range components.Components {
 		component := component
@@ -1050,8 +1216,21 @@ func (ws *Workspace) goControlComponents(t *task.Task, query componentQuery, mak
 				if msg == nil {
 					return nil
 				}
-				err := ws.control(t, component, msg)
+				gated := query.DependencyOrder == dependencyOrderNatural && awaitsReadiness(msg)
+				var err error
+				if gated {
+					err = gate.checkDependencies(component.DependsOn)
+				}
+				if err == nil {
+					err = ws.control(t, component, msg)
+				}
+				if err == nil && gated {
+					err = ws.awaitReady(t, component.ID)
+				}
 				if err != nil {
+					if gated {
+						gate.markNotReady(component.Name, err)
+					}
 					for _, f := range onErr {
 						f(&component, err)
 					}
@@ -1111,6 +1290,9 @@ func (ws *Workspace) control(ctx context.Context, desc api.ComponentDescription,
 		input = &api.DisposeInput{}
 	}
 	_, fErr := josh.Send(ctx, ctrl, input)
+	if fErr == nil {
+		ws.syncFileWatch(ctx, desc, input)
+	}
 	// Try to save state even if f fails.
 	newState, err := ctrl.MarshalState()
 	if err == nil {
@@ -4,3 +4,25 @@ package api
 // 64k) and reserve space for Syslog event headers. The message itself always
 // includes a newline terminator as well.
 const MaxMessageSize = 48 * 1024
+
+// Syslog structured data element describing the message of an event. The
+// enterprise number is reserved for documentation by RFC 5612.
+const LogSDID = "log@32473"
+
+// Max length of a log line. Lines longer than MaxMessageSize are sent as
+// multiple partial messages, which are reassembled in to a single event.
+// Any remainder beyond this length is discarded.
+const MaxLineSize = 1024 * 1024
+
+// Structured data parameter numbering messages sequentially within each
+// stream and MSGID, starting from 1. Gaps in the sequence are counted as
+// dropped events.
+const LogSeqSDParam = "seq"
+
+// Structured data parameter marking a message as partial. A partial message
+// is continued by the next message of the same stream and MSGID.
+const LogPartialSDParam = "partial"
+
+// Structured data parameter naming the logparse format of the message, so that
+// fields of structured log lines are recorded as event tags.
+const LogFormatSDParam = "format"
@@ -13,6 +13,9 @@ type Store interface {
 	AddEvent(context.Context, *AddEventInput) (*AddEventOutput, error)
 	// Returns pages of events for some set of streams. If `cursor` is specified, standard pagination behavior is used. Otherwise the cursor is assumed to represent the current tail of the stream.
 	GetEvents(context.Context, *GetEventsInput) (*GetEventsOutput, error)
+	// Searches the messages of events in a set of streams. See the logsearch package for the query syntax. Returns the most recent matches, in chronological order.
+	SearchEvents(context.Context, *SearchEventsInput) (*SearchEventsOutput, error)
+	// Applies retention policies, removing events that exceed the maximum age, count, or size of their stream.
 	RemoveOldEvents(context.Context, *RemoveOldEventsInput) (*RemoveOldEventsOutput, error)
 }
 
@@ -36,6 +39,8 @@ type AddEventInput struct {
 	Timestamp string            `json:"timestamp"`
 	Message   string            `json:"message"`
 	Tags      map[string]string `json:"tags"`
+	// Number of events lost from the stream immediately before this one.
+	DroppedCount int `json:"droppedCount"`
 }
 
 type AddEventOutput struct {
@@ -45,8 +50,10 @@ type GetEventsInput struct {
 	Streams   []string `json:"streams"`
 	Cursor    *string  `json:"cursor"`
 	FilterStr string   `json:"filterStr"`
-	Prev      *int     `json:"prev"`
-	Next      *int     `json:"next"`
+	// Space separated tag conditions, such as `level>=warn request_id=abc`. Operators are =, !=, <, <=, >, and >=.
+	TagFilter string `json:"tagFilter"`
+	Prev      *int   `json:"prev"`
+	Next      *int   `json:"next"`
 }
 
 type GetEventsOutput struct {
@@ -55,15 +62,40 @@ type GetEventsOutput struct {
 	NextCursor string  `json:"nextCursor"`
 }
 
+type SearchEventsInput struct {
+	Streams []string `json:"streams"`
+	Query   string   `json:"query"`
+	// If supplied, only events at or after this time are searched.
+	Since *string `json:"since"`
+	// If supplied, only events before this time are searched.
+	Until *string `json:"until"`
+	// Number of events from the same stream to include before and after each match.
+	Context int `json:"context"`
+	// Maximum number of matches. Absent or non-positive limits use the default.
+	Limit *int `json:"limit"`
+}
+
+type SearchEventsOutput struct {
+	Matches []SearchMatch `json:"matches"`
+	// True if there were more matches than the limit.
+	Truncated bool `json:"truncated"`
+}
+
 type RemoveOldEventsInput struct {
 }
 
 type RemoveOldEventsOutput struct {
+	RemovedCount int `json:"removedCount"`
 }
 
 type StreamDescription struct {
 	Name        string  `json:"name"`
 	LastEventAt *string `json:"lastEventAt"`
+	EventCount  int     `json:"eventCount"`
+	// Approximate storage used by the stream's events.
+	ByteCount int64 `json:"byteCount"`
+	// Number of events known to have been lost in transit to the stream.
+	DroppedCount int `json:"droppedCount"`
 }
 
 type Event struct {
@@ -73,3 +105,9 @@ type Event struct {
 	Message   string            `json:"message"`
 	Tags      map[string]string `json:"tags"`
 }
+
+type SearchMatch struct {
+	Event  Event   `json:"event"`
+	Before []Event `json:"before"`
+	After  []Event `json:"after"`
+}
@@ -16,6 +16,9 @@ interface "store" {
     input "timestamp" "string" {}
     input "message" "string" {}
     input "tags" "map[string]string" {}
+    input "dropped-count" "int" {
+      doc = "Number of events lost from the stream immediately before this one."
+    }
   }
 
   method "get-events" {
@@ -26,6 +29,9 @@ interface "store" {
 
     input "cursor" "*string" {}
     input "filter-str" "string" {}
+    input "tag-filter" "string" {
+      doc = "Space separated tag conditions, such as `level>=warn request_id=abc`. Operators are =, !=, <, <=, >, and >=."
+    }
     input "prev" "*int" {}
     input "next" "*int" {}
 
@@ -34,13 +40,46 @@ interface "store" {
     output "nextCursor" "string" {}
   }
 
-  method "remove-old-events" {}
+  method "search-events" {
+    doc = "Searches the messages of events in a set of streams. See the logsearch package for the query syntax. Returns the most recent matches, in chronological order."
+
+    input "streams" "[]string" {}
+    input "query" "string" {}
+    input "since" "*string" {
+      doc = "If supplied, only events at or after this time are searched."
+    }
+    input "until" "*string" {
+      doc = "If supplied, only events before this time are searched."
+    }
+    input "context" "int" {
+      doc = "Number of events from the same stream to include before and after each match."
+    }
+    input "limit" "*int" {}
+
+    output "matches" "[]SearchMatch" {}
+    output "truncated" "bool" {
+      doc = "True if there were more matches than the limit."
+    }
+  }
+
+  method "remove-old-events" {
+    doc = "Applies retention policies, removing events that exceed the maximum age, count, or size of their stream."
+
+    output "removed-count" "int" {}
+  }
 
 }
 
 struct "stream-description" {
   field "name" "string" {}
   field "last-event-at" "*string" {}
+  field "event-count" "int" {}
+  field "byte-count" "int64" {
+    doc = "Approximate storage used by the stream's events."
+  }
+  field "dropped-count" "int" {
+    doc = "Number of events known to have been lost in transit to the stream."
+  }
 }
 
 struct "event" {
@@ -50,3 +89,9 @@ struct "event" {
   field "message" "string" {}
   field "tags" "map[string]string" {}
 }
+
+struct "search-match" {
+  field "event" "Event" {}
+  field "before" "[]Event" {}
+  field "after" "[]Event" {}
+}
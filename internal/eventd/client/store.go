@@ -41,6 +41,11 @@ func (c *Store) GetEvents(ctx context.Context, input *api.GetEventsInput) (outpu
 	return
 }
 
+func (c *Store) SearchEvents(ctx context.Context, input *api.SearchEventsInput) (output *api.SearchEventsOutput, err error) {
+	err = c.client.Invoke(ctx, "search-events", input, &output)
+	return
+}
+
 func (c *Store) RemoveOldEvents(ctx context.Context, input *api.RemoveOldEventsInput) (output *api.RemoveOldEventsOutput, err error) {
 	err = c.client.Invoke(ctx, "remove-old-events", input, &output)
 	return
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func (sto *Store) Migrate(ctx context.Context) error {
+	if _, err := sto.DB.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS event (
+			stream TEXT NOT NULL,
+			id TEXT NOT NULL,
+			timestamp INTEGER NOT NULL,
+			message TEXT NOT NULL,
+			tags TEXT NOT NULL,
+			PRIMARY KEY ( stream, id )
+		)
+	`); err != nil {
+		return fmt.Errorf("creating event table: %w", err)
+	}
+
+	// Supports retention by age.
+	if _, err := sto.DB.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS
+		event_stream_timestamp ON event ( stream, timestamp )
+	`); err != nil {
+		return fmt.Errorf("creating event_stream_timestamp index: %w", err)
+	}
+
+	// Counts events lost before reaching the store. Dropped events have no
+	// rows of their own, so counts are kept per stream.
+	if _, err := sto.DB.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS stream_drops (
+			stream TEXT NOT NULL PRIMARY KEY,
+			dropped_count INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("creating stream_drops table: %w", err)
+	}
+
+	if err := sto.migrateFullText(ctx); err != nil {
+		return fmt.Errorf("migrating full-text index: %w", err)
+	}
+
+	return nil
+}
+
+var fullTextTriggers = map[string]string{
+	"event_fts_insert": `
+		AFTER INSERT ON event BEGIN
+			INSERT INTO event_fts ( rowid, message )
+			VALUES ( new.rowid, new.message );
+		END
+	`,
+	"event_fts_delete": `
+		AFTER DELETE ON event BEGIN
+			INSERT INTO event_fts ( event_fts, rowid, message )
+			VALUES ( 'delete', old.rowid, old.message );
+		END
+	`,
+}
+
+// The full-text index requires SQLite's FTS5 extension, which is enabled by
+// the sqlite_fts5 build tag. Without it, searches scan all events in range.
+//
+// The index is maintained by triggers. The triggers are dropped when FTS5 is
+// unavailable, so that the event table remains writable, and the index is
+// rebuilt when the triggers are next created.
+func (sto *Store) migrateFullText(ctx context.Context) error {
+	sto.fullText = false
+	_, err := sto.DB.ExecContext(ctx, `
+		CREATE VIRTUAL TABLE IF NOT EXISTS
+		event_fts USING fts5 ( message, content = 'event', content_rowid = 'rowid' )
+	`)
+	if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+		for name := range fullTextTriggers {
+			if _, err := sto.DB.ExecContext(ctx, `DROP TRIGGER IF EXISTS `+name); err != nil {
+				return fmt.Errorf("dropping %s trigger: %w", name, err)
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("creating event_fts table: %w", err)
+	}
+
+	var triggerCount int
+	if err := sto.DB.GetContext(ctx, &triggerCount, `
+		SELECT count(*)
+		FROM sqlite_master
+		WHERE type = 'trigger'
+		AND tbl_name = 'event'
+		AND name LIKE 'event_fts_%'
+	`); err != nil {
+		return fmt.Errorf("querying triggers: %w", err)
+	}
+	if triggerCount < len(fullTextTriggers) {
+		for name, body := range fullTextTriggers {
+			if _, err := sto.DB.ExecContext(ctx, `CREATE TRIGGER IF NOT EXISTS `+name+body); err != nil {
+				return fmt.Errorf("creating %s trigger: %w", name, err)
+			}
+		}
+		if _, err := sto.DB.ExecContext(ctx, `
+			INSERT INTO event_fts ( event_fts ) VALUES ( 'rebuild' )
+		`); err != nil {
+			return fmt.Errorf("rebuilding: %w", err)
+		}
+	}
+
+	sto.fullText = true
+	return nil
+}
@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deref/exo/internal/chrono"
+	"github.com/deref/exo/internal/config"
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/util/logging"
+)
+
+// Limits on the events retained per stream. Zero values disable the
+// corresponding limit.
+type RetentionPolicy struct {
+	MaxAge    time.Duration
+	MaxEvents int
+	MaxBytes  int64
+}
+
+func ParseRetentionConfig(cfg config.RetentionConfig) (RetentionPolicy, error) {
+	policy := RetentionPolicy{
+		MaxEvents: cfg.MaxEvents,
+		MaxBytes:  cfg.MaxBytes,
+	}
+	if cfg.MaxAge != "" {
+		var err error
+		policy.MaxAge, err = time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return policy, fmt.Errorf("parsing max age: %w", err)
+		}
+	}
+	if policy.MaxAge < 0 || policy.MaxEvents < 0 || policy.MaxBytes < 0 {
+		return policy, fmt.Errorf("retention limits must not be negative")
+	}
+	return policy, nil
+}
+
+// Configures the store's retention policies from the log config.
+func (sto *Store) ConfigureRetention(cfg config.LogConfig) error {
+	var err error
+	sto.Retention, err = ParseRetentionConfig(cfg.Retention)
+	if err != nil {
+		return fmt.Errorf("default retention: %w", err)
+	}
+	sto.StreamRetention = make(map[string]RetentionPolicy, len(cfg.Streams))
+	for stream, streamCfg := range cfg.Streams {
+		sto.StreamRetention[stream], err = ParseRetentionConfig(streamCfg)
+		if err != nil {
+			return fmt.Errorf("retention of stream %q: %w", stream, err)
+		}
+	}
+	return nil
+}
+
+// Streams without any configured limits keep this many of their most recent
+// events, as they did before retention was configurable.
+const DefaultMaxEvents = 10000
+
+func (sto *Store) retentionPolicy(stream string) RetentionPolicy {
+	policy, ok := sto.StreamRetention[stream]
+	if !ok {
+		policy = sto.Retention
+	}
+	if policy == (RetentionPolicy{}) {
+		policy.MaxEvents = DefaultMaxEvents
+	}
+	return policy
+}
+
+// SQL expression approximating the number of bytes stored for an event row.
+const eventSizeExpr = `length(CAST(message AS BLOB)) + length(tags)`
+
+func (sto *Store) RemoveOldEvents(ctx context.Context, input *api.RemoveOldEventsInput) (*api.RemoveOldEventsOutput, error) {
+	var streams []string
+	if err := sto.DB.SelectContext(ctx, &streams, `
+		SELECT DISTINCT stream
+		FROM event
+	`); err != nil {
+		return nil, fmt.Errorf("querying streams: %w", err)
+	}
+
+	var output api.RemoveOldEventsOutput
+	for _, stream := range streams {
+		removed, err := sto.compactStream(ctx, stream, sto.retentionPolicy(stream))
+		output.RemovedCount += removed
+		if err != nil {
+			return &output, fmt.Errorf("compacting stream %q: %w", stream, err)
+		}
+	}
+	return &output, nil
+}
+
+func (sto *Store) compactStream(ctx context.Context, stream string, policy RetentionPolicy) (removed int, err error) {
+	exec := func(query string, args ...any) error {
+		res, err := sto.DB.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		removed += int(n)
+		return err
+	}
+
+	if policy.MaxAge > 0 {
+		cutoff := chrono.Now(ctx).Add(-policy.MaxAge).UnixNano()
+		if err := exec(`
+			DELETE FROM event
+			WHERE stream = ?
+			AND timestamp < ?
+		`, stream, cutoff); err != nil {
+			return removed, fmt.Errorf("removing expired events: %w", err)
+		}
+	}
+
+	// The subqueries find the newest event that exceeds the limit, which is
+	// removed along with all older events. If no event exceeds the limit, the
+	// subquery yields NULL, and nothing is removed.
+
+	if policy.MaxEvents > 0 {
+		if err := exec(`
+			DELETE FROM event
+			WHERE stream = ?
+			AND id <= (
+				SELECT id
+				FROM event
+				WHERE stream = ?
+				ORDER BY id DESC
+				LIMIT 1 OFFSET ?
+			)
+		`, stream, stream, policy.MaxEvents); err != nil {
+			return removed, fmt.Errorf("removing excess events: %w", err)
+		}
+	}
+
+	if policy.MaxBytes > 0 {
+		if err := exec(`
+			DELETE FROM event
+			WHERE stream = ?
+			AND id <= (
+				SELECT id
+				FROM (
+					SELECT id, sum(`+eventSizeExpr+`) OVER (ORDER BY id DESC) AS total
+					FROM event
+					WHERE stream = ?
+				)
+				WHERE total > ?
+				ORDER BY id DESC
+				LIMIT 1
+			)
+		`, stream, stream, policy.MaxBytes); err != nil {
+			return removed, fmt.Errorf("removing events exceeding size limit: %w", err)
+		}
+	}
+
+	return removed, nil
+}
+
+// Periodically removes old events until the context is cancelled.
+func (sto *Store) RunCompaction(ctx context.Context, interval time.Duration) {
+	logger := logging.CurrentLogger(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+			if _, err := sto.RemoveOldEvents(ctx, &api.RemoveOldEventsInput{}); err != nil {
+				logger.Infof("error removing old events: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deref/exo/internal/chrono"
+	"github.com/deref/exo/internal/config"
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/gensym"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	ctx := context.Background()
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	// Each connection to :memory: is a distinct database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	sto := &Store{
+		DB:    db,
+		IDGen: gensym.NewULIDGenerator(ctx),
+	}
+	require.NoError(t, sto.Migrate(ctx))
+	return sto
+}
+
+func addEvents(t *testing.T, sto *Store, stream string, n int, age time.Duration, message string) {
+	ctx := context.Background()
+	timestamp := chrono.IsoNano(time.Now().Add(-age))
+	for i := 0; i < n; i++ {
+		_, err := sto.AddEvent(ctx, &api.AddEventInput{
+			Stream:    stream,
+			Timestamp: timestamp,
+			Message:   message,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func describeStreams(t *testing.T, sto *Store, names ...string) map[string]api.StreamDescription {
+	output, err := sto.DescribeStreams(context.Background(), &api.DescribeStreamsInput{
+		Names: names,
+	})
+	require.NoError(t, err)
+	streams := make(map[string]api.StreamDescription)
+	for _, stream := range output.Streams {
+		streams[stream.Name] = stream
+	}
+	return streams
+}
+
+func TestRemoveOldEventsMaxEvents(t *testing.T) {
+	sto := newTestStore(t)
+	sto.Retention = RetentionPolicy{MaxEvents: 3}
+	sto.StreamRetention = map[string]RetentionPolicy{
+		"quiet": {MaxEvents: 5},
+	}
+	addEvents(t, sto, "chatty", 10, 0, "hello")
+	addEvents(t, sto, "quiet", 10, 0, "hello")
+
+	output, err := sto.RemoveOldEvents(context.Background(), &api.RemoveOldEventsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 12, output.RemovedCount)
+
+	streams := describeStreams(t, sto, "chatty", "quiet")
+	assert.Equal(t, 3, streams["chatty"].EventCount)
+	assert.Equal(t, 5, streams["quiet"].EventCount)
+}
+
+func TestRetentionPolicyDefault(t *testing.T) {
+	var sto Store
+	assert.Equal(t, RetentionPolicy{MaxEvents: DefaultMaxEvents}, sto.retentionPolicy("web"))
+
+	sto.StreamRetention = map[string]RetentionPolicy{
+		"db": {MaxAge: time.Hour},
+	}
+	assert.Equal(t, RetentionPolicy{MaxAge: time.Hour}, sto.retentionPolicy("db"))
+	assert.Equal(t, RetentionPolicy{MaxEvents: DefaultMaxEvents}, sto.retentionPolicy("web"))
+}
+
+func TestRemoveOldEventsMaxAge(t *testing.T) {
+	sto := newTestStore(t)
+	sto.Retention = RetentionPolicy{MaxAge: time.Hour}
+	addEvents(t, sto, "stream", 4, 2*time.Hour, "old")
+	addEvents(t, sto, "stream", 2, 0, "new")
+
+	_, err := sto.RemoveOldEvents(context.Background(), &api.RemoveOldEventsInput{})
+	require.NoError(t, err)
+
+	output, err := sto.GetEvents(context.Background(), &api.GetEventsInput{
+		Streams: []string{"stream"},
+		Cursor:  new(string),
+	})
+	require.NoError(t, err)
+	if assert.Len(t, output.Items, 2) {
+		assert.Equal(t, "new", output.Items[0].Message)
+	}
+}
+
+func TestRemoveOldEventsMaxBytes(t *testing.T) {
+	sto := newTestStore(t)
+	message := strings.Repeat("x", 98)
+	// Each event is 100 bytes: the message plus "{}" tags.
+	sto.Retention = RetentionPolicy{MaxBytes: 450}
+	addEvents(t, sto, "stream", 10, 0, message)
+
+	_, err := sto.RemoveOldEvents(context.Background(), &api.RemoveOldEventsInput{})
+	require.NoError(t, err)
+
+	stream := describeStreams(t, sto, "stream")["stream"]
+	assert.Equal(t, 4, stream.EventCount)
+	assert.Equal(t, int64(400), stream.ByteCount)
+}
+
+func TestConfigureRetention(t *testing.T) {
+	var sto Store
+	err := sto.ConfigureRetention(config.LogConfig{
+		Retention: config.RetentionConfig{
+			MaxAge:    "72h",
+			MaxEvents: 100,
+		},
+		Streams: map[string]config.RetentionConfig{
+			"db": {MaxBytes: 1024},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RetentionPolicy{MaxAge: 72 * time.Hour, MaxEvents: 100}, sto.retentionPolicy("web"))
+	assert.Equal(t, RetentionPolicy{MaxBytes: 1024}, sto.retentionPolicy("db"))
+
+	err = sto.ConfigureRetention(config.LogConfig{
+		Retention: config.RetentionConfig{MaxAge: "forever"},
+	})
+	assert.Error(t, err)
+}
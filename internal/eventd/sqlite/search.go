@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/deref/exo/internal/chrono"
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/logsearch"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/internal/util/jsonutil"
+	"github.com/deref/exo/internal/util/mathutil"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+	maxSearchContext   = 100
+)
+
+func (sto *Store) SearchEvents(ctx context.Context, input *api.SearchEventsInput) (*api.SearchEventsOutput, error) {
+	query, err := logsearch.Parse(input.Query)
+	if err != nil {
+		return nil, errutil.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid query: %v", err))
+	}
+	since, err := parseSearchBound(input.Since, math.MinInt64)
+	if err != nil {
+		return nil, errutil.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid since: %v", err))
+	}
+	until, err := parseSearchBound(input.Until, math.MaxInt64)
+	if err != nil {
+		return nil, errutil.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid until: %v", err))
+	}
+	limit := defaultSearchLimit
+	if input.Limit != nil && *input.Limit > 0 {
+		limit = mathutil.IntMin(*input.Limit, maxSearchLimit)
+	}
+	contextSize := mathutil.IntClamp(input.Context, 0, maxSearchContext)
+
+	output := api.SearchEventsOutput{
+		Matches: []api.SearchMatch{},
+	}
+	if len(input.Streams) == 0 {
+		return &output, nil
+	}
+
+	// Candidates are found with the full-text index, if possible, and then
+	// checked against the query, which may be more precise than the index.
+	var sql string
+	var args []any
+	if fts := query.FTS(); sto.fullText && fts != "" {
+		sql = `
+			SELECT event.stream, event.id, event.timestamp, event.message, event.tags
+			FROM event_fts
+			JOIN event ON event.rowid = event_fts.rowid
+			WHERE event_fts MATCH ?
+			AND event.stream IN (?)
+			AND event.timestamp >= ?
+			AND event.timestamp < ?
+			ORDER BY event.id DESC
+		`
+		args = []any{fts, input.Streams, since, until}
+	} else {
+		sql = `
+			SELECT stream, id, timestamp, message, tags
+			FROM event
+			WHERE stream IN (?)
+			AND timestamp >= ?
+			AND timestamp < ?
+			ORDER BY id DESC
+		`
+		args = []any{input.Streams, since, until}
+	}
+	sql, args, err = sqlx.In(sql, args...)
+	if err != nil {
+		panic(err)
+	}
+	if err := sto.forEachEvent(ctx, func(event api.Event) bool {
+		if !query.Match(event.Message) {
+			return true
+		}
+		if len(output.Matches) == limit {
+			output.Truncated = true
+			return false
+		}
+		output.Matches = append(output.Matches, api.SearchMatch{
+			Event: event,
+		})
+		return true
+	}, sql, args...); err != nil {
+		return nil, err
+	}
+
+	// Order chronologically.
+	for l, r := 0, len(output.Matches)-1; l < r; l, r = l+1, r-1 {
+		output.Matches[l], output.Matches[r] = output.Matches[r], output.Matches[l]
+	}
+
+	for i := range output.Matches {
+		match := &output.Matches[i]
+		match.Before, err = sto.collectEvents(ctx, `
+			SELECT stream, id, timestamp, message, tags
+			FROM event
+			WHERE stream = ?
+			AND id < ?
+			ORDER BY id DESC
+			LIMIT ?
+		`, match.Event.Stream, match.Event.ID, contextSize)
+		if err != nil {
+			return nil, fmt.Errorf("querying context before %q: %w", match.Event.ID, err)
+		}
+		for l, r := 0, len(match.Before)-1; l < r; l, r = l+1, r-1 {
+			match.Before[l], match.Before[r] = match.Before[r], match.Before[l]
+		}
+		match.After, err = sto.collectEvents(ctx, `
+			SELECT stream, id, timestamp, message, tags
+			FROM event
+			WHERE stream = ?
+			AND id > ?
+			ORDER BY id ASC
+			LIMIT ?
+		`, match.Event.Stream, match.Event.ID, contextSize)
+		if err != nil {
+			return nil, fmt.Errorf("querying context after %q: %w", match.Event.ID, err)
+		}
+	}
+
+	return &output, nil
+}
+
+func parseSearchBound(s *string, unbounded int64) (int64, error) {
+	if s == nil || *s == "" {
+		return unbounded, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, *s)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano(), nil
+}
+
+// Calls f with each event selected by a query, until f returns false.
+func (sto *Store) forEachEvent(ctx context.Context, f func(api.Event) bool, query string, args ...any) error {
+	rows, err := sto.DB.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("querying: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if !f(event) {
+			break
+		}
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("advancing rows: %w", rows.Err())
+	}
+	return nil
+}
+
+func (sto *Store) collectEvents(ctx context.Context, query string, args ...any) ([]api.Event, error) {
+	events := []api.Event{}
+	err := sto.forEachEvent(ctx, func(event api.Event) bool {
+		events = append(events, event)
+		return true
+	}, query, args...)
+	return events, err
+}
+
+// Scans a row of stream, id, timestamp, message, and tags.
+func scanEvent(rows *sqlx.Rows) (api.Event, error) {
+	var event api.Event
+	var timestampNano int64
+	var tags string
+	if err := rows.Scan(&event.Stream, &event.ID, &timestampNano, &event.Message, &tags); err != nil {
+		return event, fmt.Errorf("scanning: %w", err)
+	}
+	event.Timestamp = chrono.NanoToIso(timestampNano)
+	if err := jsonutil.UnmarshalString(tags, &event.Tags); err != nil {
+		return event, fmt.Errorf("unmarshalling event %q tags: %w", event.ID, err)
+	}
+	return event, nil
+}
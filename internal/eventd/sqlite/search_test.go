@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deref/exo/internal/chrono"
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchEvents(t *testing.T) {
+	ctx := context.Background()
+	sto := newTestStore(t)
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, event := range []struct {
+		Stream  string
+		Message string
+	}{
+		{"api", "starting server"},
+		{"api", "GET /users 200"},
+		{"worker", "connection refused"},
+		{"api", "GET /health 200"},
+		{"api", "GET /users 503"},
+		{"worker", "retrying connection"},
+		{"api", "GET /users 200"},
+	} {
+		_, err := sto.AddEvent(ctx, &api.AddEventInput{
+			Stream:    event.Stream,
+			Timestamp: chrono.IsoNano(start.Add(time.Duration(i) * time.Minute)),
+			Message:   event.Message,
+		})
+		require.NoError(t, err)
+	}
+
+	search := func(input api.SearchEventsInput) []string {
+		if input.Streams == nil {
+			input.Streams = []string{"api", "worker"}
+		}
+		output, err := sto.SearchEvents(ctx, &input)
+		require.NoError(t, err)
+		var messages []string
+		for _, match := range output.Matches {
+			messages = append(messages, match.Event.Message)
+		}
+		return messages
+	}
+	testSearch := func(t *testing.T) {
+		assert.Equal(t, []string{"connection refused", "retrying connection"}, search(api.SearchEventsInput{
+			Query: "connection",
+		}))
+		assert.Equal(t, []string{"connection refused"}, search(api.SearchEventsInput{
+			Query:   `"connection refused" OR starting`,
+			Streams: []string{"worker"},
+		}))
+		assert.Equal(t, []string{"GET /users 503"}, search(api.SearchEventsInput{
+			Query: `users -/ 200$/`,
+		}))
+		assert.Equal(t, []string{"GET /health 200", "GET /users 200"}, search(api.SearchEventsInput{
+			Query: `/ 200$/ NOT starting`,
+			Since: strPtr(chrono.IsoNano(start.Add(2 * time.Minute))),
+		}))
+		assert.Equal(t, []string{"GET /users 200", "GET /health 200"}, search(api.SearchEventsInput{
+			Query: `get`,
+			Until: strPtr(chrono.IsoNano(start.Add(4 * time.Minute))),
+		}))
+
+		limit := 1
+		output, err := sto.SearchEvents(ctx, &api.SearchEventsInput{
+			Streams: []string{"api"},
+			Query:   "users",
+			Context: 1,
+			Limit:   &limit,
+		})
+		require.NoError(t, err)
+		assert.True(t, output.Truncated)
+		require.Len(t, output.Matches, 1)
+		match := output.Matches[0]
+		assert.Equal(t, "GET /users 200", match.Event.Message)
+		require.Len(t, match.Before, 1)
+		assert.Equal(t, "GET /users 503", match.Before[0].Message)
+		assert.Empty(t, match.After)
+
+		// Non-positive limits use the default.
+		for _, limit := range []int{0, -1} {
+			limit := limit
+			output, err := sto.SearchEvents(ctx, &api.SearchEventsInput{
+				Streams: []string{"api"},
+				Query:   "users",
+				Limit:   &limit,
+			})
+			require.NoError(t, err)
+			assert.False(t, output.Truncated)
+			assert.Len(t, output.Matches, 3)
+		}
+
+		_, err = sto.SearchEvents(ctx, &api.SearchEventsInput{
+			Streams: []string{"api"},
+			Query:   "(unclosed",
+		})
+		assert.Error(t, err)
+	}
+
+	t.Run("scan", func(t *testing.T) {
+		fullText := sto.fullText
+		defer func() { sto.fullText = fullText }()
+		sto.fullText = false
+		testSearch(t)
+	})
+	t.Run("index", func(t *testing.T) {
+		if !sto.fullText {
+			t.Skip("full-text index requires the sqlite_fts5 build tag")
+		}
+		testSearch(t)
+
+		// Removed events are removed from the index.
+		sto.Retention = RetentionPolicy{MaxEvents: 1}
+		_, err := sto.RemoveOldEvents(ctx, &api.RemoveOldEventsInput{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"retrying connection"}, search(api.SearchEventsInput{
+			Query: "connection",
+		}))
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/deref/exo/internal/chrono"
 	"github.com/deref/exo/internal/eventd/api"
 	"github.com/deref/exo/internal/gensym"
+	"github.com/deref/exo/internal/logparse"
 	"github.com/deref/exo/internal/util/errutil"
 	"github.com/deref/exo/internal/util/jsonutil"
 	"github.com/deref/exo/internal/util/mathutil"
@@ -21,6 +22,13 @@ import (
 type Store struct {
 	DB    *sqlx.DB
 	IDGen *gensym.ULIDGenerator
+	// Applied by RemoveOldEvents to each stream without an override in
+	// StreamRetention. A policy without limits keeps DefaultMaxEvents.
+	Retention       RetentionPolicy
+	StreamRetention map[string]RetentionPolicy
+
+	// Set by Migrate if the full-text index is available.
+	fullText bool
 }
 
 func (sto *Store) ClearEvents(ctx context.Context, input *api.ClearEventsInput) (*api.ClearEventsOutput, error) {
@@ -28,6 +36,16 @@ func (sto *Store) ClearEvents(ctx context.Context, input *api.ClearEventsInput)
 		query, args, err := sqlx.In(`
 		DELETE FROM event
 		WHERE stream IN (?)
+	`, input.Streams)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := sto.DB.ExecContext(ctx, query, args...); err != nil {
+			return nil, err
+		}
+		query, args, err = sqlx.In(`
+		DELETE FROM stream_drops
+		WHERE stream IN (?)
 	`, input.Streams)
 		if err != nil {
 			panic(err)
@@ -45,11 +63,15 @@ func (sto *Store) DescribeStreams(ctx context.Context, input *api.DescribeStream
 	}
 	if len(input.Names) > 0 {
 		query, args, err := sqlx.In(`
-			SELECT stream, max(timestamp) AS last_event_at
+			SELECT stream, max(timestamp), count(*), sum(`+eventSizeExpr+`), (
+				SELECT coalesce(sum(dropped_count), 0)
+				FROM stream_drops
+				WHERE stream_drops.stream = event.stream
+			)
 			FROM event
-			WHERE name IN (?)
-			ORDER BY stream ASC
+			WHERE stream IN (?)
 			GROUP BY stream
+			ORDER BY stream ASC
 		`, input.Names)
 		if err != nil {
 			panic(err)
@@ -62,7 +84,7 @@ func (sto *Store) DescribeStreams(ctx context.Context, input *api.DescribeStream
 		for rows.Next() {
 			var stream api.StreamDescription
 			var lastEventAtNano int64
-			if err := rows.Scan(&stream.Name, &lastEventAtNano); err != nil {
+			if err := rows.Scan(&stream.Name, &lastEventAtNano, &stream.EventCount, &stream.ByteCount, &stream.DroppedCount); err != nil {
 				return nil, fmt.Errorf("scanning: %w", err)
 			}
 			lastEventAtIso := chrono.NanoToIso(lastEventAtNano)
@@ -99,6 +121,17 @@ func (sto *Store) AddEvent(ctx context.Context, input *api.AddEventInput) (*api.
 	`, input.Stream, id, timestamp, input.Message, tags); err != nil {
 		return nil, fmt.Errorf("inserting: %w", err)
 	}
+
+	if input.DroppedCount > 0 {
+		if _, err := sto.DB.ExecContext(ctx, `
+			INSERT INTO stream_drops ( stream, dropped_count )
+			VALUES ( ?, ? )
+			ON CONFLICT ( stream ) DO UPDATE
+			SET dropped_count = dropped_count + excluded.dropped_count
+		`, input.Stream, input.DroppedCount); err != nil {
+			return nil, fmt.Errorf("counting dropped events: %w", err)
+		}
+	}
 	return &api.AddEventOutput{}, nil
 }
 
@@ -157,6 +190,12 @@ func (sto *Store) GetEvents(ctx context.Context, input *api.GetEventsInput) (*ap
 	}
 	limit = mathutil.IntClamp(limit, 0, maxLimit)
 
+	tagFilter, err := logparse.ParseFilter(input.TagFilter)
+	if err != nil {
+		return nil, errutil.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid tag filter: %v", err))
+	}
+	tagFilterExpr, tagFilterArgs := tagFilter.SQL("tags")
+
 	var query string
 	if reverse {
 		query = `
@@ -165,6 +204,7 @@ func (sto *Store) GetEvents(ctx context.Context, input *api.GetEventsInput) (*ap
 			WHERE stream IN (?)
 			AND id < ?
 			AND instr(lower(message), ?) <> 0
+			AND ` + tagFilterExpr + `
 			ORDER BY id DESC
 			LIMIT ?
 		`
@@ -175,11 +215,15 @@ func (sto *Store) GetEvents(ctx context.Context, input *api.GetEventsInput) (*ap
 			WHERE stream IN (?)
 			AND ? < id
 			AND instr(lower(message), ?) <> 0
+			AND ` + tagFilterExpr + `
 			ORDER BY id ASC
 			LIMIT ?
 		`
 	}
-	query, args, err := sqlx.In(query, input.Streams, cursor, input.FilterStr, limit)
+	args := []any{input.Streams, cursor, input.FilterStr}
+	args = append(args, tagFilterArgs...)
+	args = append(args, limit)
+	query, args, err = sqlx.In(query, args...)
 	if err != nil {
 		panic(err)
 	}
@@ -191,15 +235,9 @@ func (sto *Store) GetEvents(ctx context.Context, input *api.GetEventsInput) (*ap
 	defer rows.Close()
 
 	for rows.Next() {
-		var event api.Event
-		var timestampNano int64
-		var tags string
-		if err := rows.Scan(&event.Stream, &event.ID, &timestampNano, &event.Message, &tags); err != nil {
-			return nil, fmt.Errorf("scanning: %w", err)
-		}
-		event.Timestamp = chrono.NanoToIso(timestampNano)
-		if err := jsonutil.UnmarshalString(tags, &event.Tags); err != nil {
-			return nil, fmt.Errorf("unmarshalling event %q tags: %w", event.ID, err)
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
 		}
 		output.Items = append(output.Items, event)
 	}
@@ -256,23 +294,3 @@ func (sto *Store) getLatestCursor(ctx context.Context, streams []string) (string
 func incrementCursor(id string) string {
 	return id + "0"
 }
-
-func (sto *Store) RemoveOldEvents(ctx context.Context, input *api.RemoveOldEventsInput) (*api.RemoveOldEventsOutput, error) {
-	// This is an inefficent way to keep only the most recent rows.
-	// TODO: Use SQLITE_ENABLE_UPDATE_DELETE_LIMIT when go-sqlite supports it.
-	// See <https://github.com/mattn/go-sqlite3/issues/787>.
-	const maxEvents = 10000
-	_, err := sto.DB.ExecContext(ctx, `
-		DELETE FROM event
-		WHERE id NOT IN (
-			SELECT id
-			FROM event
-			ORDER BY id DESC
-			LIMIT ?
-		)
-	`, maxEvents)
-	if err != nil {
-		return nil, err
-	}
-	return &api.RemoveOldEventsOutput{}, nil
-}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deref/exo/internal/chrono"
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEventsTagFilter(t *testing.T) {
+	ctx := context.Background()
+	sto := newTestStore(t)
+	for _, tags := range []map[string]string{
+		{"level": "info", "request_id": "abc"},
+		{"level": "warn", "request_id": "abc"},
+		{"level": "error", "request_id": "def"},
+		{"stdio": "out"},
+	} {
+		_, err := sto.AddEvent(ctx, &api.AddEventInput{
+			Stream:    "stream",
+			Timestamp: chrono.IsoNano(time.Now()),
+			Message:   "message",
+			Tags:      tags,
+		})
+		require.NoError(t, err)
+	}
+
+	getEvents := func(tagFilter string) ([]map[string]string, error) {
+		cursor := ""
+		output, err := sto.GetEvents(ctx, &api.GetEventsInput{
+			Streams:   []string{"stream"},
+			Cursor:    &cursor,
+			TagFilter: tagFilter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tags := make([]map[string]string, len(output.Items))
+		for i, event := range output.Items {
+			tags[i] = event.Tags
+		}
+		return tags, nil
+	}
+
+	events, err := getEvents("level>=warn")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"level": "warn", "request_id": "abc"},
+		{"level": "error", "request_id": "def"},
+	}, events)
+
+	events, err = getEvents("request_id=abc level<warn")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"level": "info", "request_id": "abc"},
+	}, events)
+
+	events, err = getEvents("")
+	require.NoError(t, err)
+	assert.Len(t, events, 4)
+
+	_, err = getEvents("level>=loud")
+	assert.Error(t, err)
+}
+
+func TestDroppedCount(t *testing.T) {
+	ctx := context.Background()
+	sto := newTestStore(t)
+	for _, dropped := range []int{0, 3, 2} {
+		_, err := sto.AddEvent(ctx, &api.AddEventInput{
+			Stream:       "stream",
+			Timestamp:    chrono.IsoNano(time.Now()),
+			Message:      "message",
+			DroppedCount: dropped,
+		})
+		require.NoError(t, err)
+	}
+	addEvents(t, sto, "other", 1, 0, "message")
+
+	streams := describeStreams(t, sto, "stream", "other")
+	assert.Equal(t, 3, streams["stream"].EventCount)
+	assert.Equal(t, 5, streams["stream"].DroppedCount)
+	assert.Equal(t, 0, streams["other"].DroppedCount)
+
+	_, err := sto.ClearEvents(ctx, &api.ClearEventsInput{Streams: []string{"stream"}})
+	require.NoError(t, err)
+	addEvents(t, sto, "stream", 1, 0, "message")
+	assert.Equal(t, 0, describeStreams(t, sto, "stream")["stream"].DroppedCount)
+}
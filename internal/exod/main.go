@@ -94,12 +94,28 @@ func (svr *Server) Run(ctx context.Context) {
 	tel.StartSession(ctx)
 	tel.SendEvent(ctx, telemetry.SystemInfoIdentifiedEvent())
 
+	var metricsInterval, metricsMaxAge time.Duration
+	if !cfg.Metrics.Disable {
+		if metricsInterval, err = time.ParseDuration(cfg.Metrics.Interval); err != nil {
+			cmdutil.Fatalf("parsing metrics interval: %w", err)
+		}
+		if metricsMaxAge, err = time.ParseDuration(cfg.Metrics.MaxAge); err != nil {
+			cmdutil.Fatalf("parsing metrics max age: %w", err)
+		}
+	}
+
 	// XXX pass the peer to the server.
 	service := &peer.Peer{
 		SystemLog:   logger,
 		VarDir:      cfg.VarDir,
 		GUIEndpoint: fmt.Sprintf("http://localhost:%d", cfg.GUI.Port), // XXX should be constructed earlier than here.
 		Debug:       true,                                             // XXX parameterize me.
+		Daemon:      true,
+		Plugins:     cfg.Plugins,
+		LogSinks:    cfg.Log.Sinks,
+
+		MetricsInterval: metricsInterval,
+		MetricsMaxAge:   metricsMaxAge,
 	}
 	if err := service.Init(ctx); err != nil {
 		cmdutil.Fatalf("error initializing service: %v", err)
@@ -128,11 +144,15 @@ func (svr *Server) Run(ctx context.Context) {
 		Docker:      dockerClient,
 		Logger:      logger,
 		TaskTracker: taskTracker,
+		FileWatches: kernel.NewFileWatchManager(logger),
 		TokenClient: cfg.GetTokenClient(),
 		EsvClient:   esv.NewEsvClient(cfg.EsvTokenPath),
 		ExoVersion:  about.Version,
 		Service:     service,
 	}
+	if err := kernel.RestoreFileWatches(ctx, kernelCfg); err != nil {
+		logger.Infof("restoring file watches: %v", err)
+	}
 
 	// Commented out while transitioning to graphql implementation.
 	//eventStore := &eventdsqlite.Store{
@@ -143,6 +163,9 @@ func (svr *Server) Run(ctx context.Context) {
 	//if err := eventStore.Migrate(ctx); err != nil {
 	//	cmdutil.Fatalf("migrating event store: %v", err)
 	//}
+	//if err := eventStore.ConfigureRetention(cfg.Log); err != nil {
+	//	cmdutil.Fatalf("configuring log retention: %v", err)
+	//}
 	//
 	//syslogServer := &syslogd.Server{
 	//	SyslogPort: kernelCfg.SyslogPort,
@@ -161,18 +184,7 @@ func (svr *Server) Run(ctx context.Context) {
 		defer shutdown()
 
 		// Commented out while transitioning to graphql implementation.
-		//go func() {
-		//	for {
-		//		select {
-		//		case <-ctx.Done():
-		//			return
-		//		case <-time.After(5 * time.Second):
-		//			if _, err := eventStore.RemoveOldEvents(ctx, &eventdapi.RemoveOldEventsInput{}); err != nil {
-		//				logger.Infof("error removing old events: %v", err)
-		//			}
-		//		}
-		//	}
-		//}()
+		//go eventStore.RunCompaction(ctx, 5*time.Second)
 
 		// Commented out while transitioning to graphql implementation.
 		//go func() {
@@ -0,0 +1,118 @@
+package filewatch
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Glob matches slash-separated paths relative to a watch root. In addition to
+// the syntax of path.Match, a "**" path element matches zero or more
+// directories. A pattern without any slashes matches against the base name of
+// a path at any depth, so "*.go" is equivalent to "**/*.go".
+type Glob struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func CompileGlob(pattern string) (*Glob, error) {
+	pattern = strings.TrimPrefix(path.Clean("/"+pattern), "/")
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		pattern = "**/" + pattern
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	elems := strings.Split(pattern, "/")
+	for i, elem := range elems {
+		last := i == len(elems)-1
+		if elem == "**" {
+			if last {
+				sb.WriteString(".*")
+			} else {
+				sb.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+		if err := translateGlobElem(&sb, elem); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		if !last {
+			sb.WriteString("/")
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return &Glob{
+		pattern: pattern,
+		re:      re,
+	}, nil
+}
+
+func translateGlobElem(sb *strings.Builder, elem string) error {
+	for i := 0; i < len(elem); i++ {
+		c := elem[i]
+		switch c {
+		case '*':
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(elem[i:], ']')
+			if end < 0 {
+				return fmt.Errorf("unterminated character class")
+			}
+			class := elem[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		case '\\':
+			if i+1 < len(elem) {
+				i++
+				c = elem[i]
+			}
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return nil
+}
+
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+// Match reports whether a slash-separated relative path matches the glob.
+func (g *Glob) Match(name string) bool {
+	return g.re.MatchString(name)
+}
+
+type Globs []*Glob
+
+func CompileGlobs(patterns []string) (Globs, error) {
+	globs := make(Globs, len(patterns))
+	for i, pattern := range patterns {
+		var err error
+		globs[i], err = CompileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return globs, nil
+}
+
+// MatchAny reports whether any glob matches the given path.
+func (globs Globs) MatchAny(name string) bool {
+	for _, glob := range globs {
+		if glob.Match(name) {
+			return true
+		}
+	}
+	return false
+}
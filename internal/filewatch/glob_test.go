@@ -0,0 +1,45 @@
+package filewatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		Pattern string
+		Path    string
+		Match   bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/util/main.go", true},
+		{"*.go", "main.go.orig", false},
+		{"src/*.js", "src/index.js", true},
+		{"src/*.js", "src/lib/index.js", false},
+		{"src/**/*.js", "src/index.js", true},
+		{"src/**/*.js", "src/lib/deep/index.js", true},
+		{"src/**", "src/lib/deep/index.js", true},
+		{"src/**", "lib/index.js", false},
+		{"**", "anything/at/all", true},
+		{"node_modules", "node_modules", true},
+		{"node_modules", "web/node_modules", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"[abc].txt", "b.txt", true},
+		{"[!abc].txt", "b.txt", false},
+		{"./app/*.rb", "app/user.rb", true},
+	}
+	for _, test := range tests {
+		glob, err := CompileGlob(test.Pattern)
+		if !assert.NoError(t, err, "pattern: %q", test.Pattern) {
+			continue
+		}
+		assert.Equal(t, test.Match, glob.Match(test.Path), "pattern: %q, path: %q", test.Pattern, test.Path)
+	}
+}
+
+func TestGlobInvalid(t *testing.T) {
+	_, err := CompileGlob("[abc")
+	assert.Error(t, err)
+}
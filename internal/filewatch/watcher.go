@@ -0,0 +1,137 @@
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/fsnotify/fsnotify"
+)
+
+const DefaultDebounce = 250 * time.Millisecond
+
+// DefaultExclude lists patterns that are never worth restarting for.
+var DefaultExclude = []string{".git", ".hg", ".svn", "node_modules", ".DS_Store"}
+
+// Watcher recursively watches a directory tree and reports batches of changed
+// paths once changes have been quiet for the debounce interval.
+type Watcher struct {
+	Root string
+	// If non-empty, only paths matching at least one glob are reported.
+	Include Globs
+	// Paths matching any glob are not reported. Excluded directories are not
+	// descended in to.
+	Exclude  Globs
+	Debounce time.Duration
+	// Called with the slash-separated, root-relative paths that have changed.
+	OnChange func(paths []string)
+	// Receives errors that do not stop the watch, such as event queue
+	// overflows. Defaults to logging.Default().
+	Logger logging.Logger
+}
+
+func (w *Watcher) Run(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	if err := w.addTree(fsw, w.Root); err != nil {
+		return err
+	}
+
+	logger := w.Logger
+	if logger == nil {
+		logger = logging.Default()
+	}
+
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	timer := time.NewTimer(debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	pending := make(map[string]struct{})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			rel, ok := w.relativePath(event.Name)
+			if !ok || w.Exclude.MatchAny(rel) {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Errors are ignored because the directory may have already been
+					// removed again.
+					_ = w.addTree(fsw, event.Name)
+				}
+			}
+			if len(w.Include) > 0 && !w.Include.MatchAny(rel) {
+				continue
+			}
+			pending[rel] = struct{}{}
+			timer.Reset(debounce)
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			// Errors are transient, such as when events are dropped, so keep
+			// watching rather than giving up for the life of the process.
+			logger.Infof("watching files in %s: %v", w.Root, err)
+
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]struct{})
+			if len(paths) > 0 {
+				w.OnChange(paths)
+			}
+		}
+	}
+}
+
+func (w *Watcher) relativePath(name string) (string, bool) {
+	rel, err := filepath.Rel(w.Root, name)
+	if err != nil || rel == ".." || filepath.IsAbs(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func (w *Watcher) addTree(fsw *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Tolerate files that disappear or are unreadable during the walk.
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel, ok := w.relativePath(path); ok && rel != "." && w.Exclude.MatchAny(rel) {
+			return filepath.SkipDir
+		}
+		if err := fsw.Add(path); err != nil {
+			return fmt.Errorf("watching %q: %w", path, err)
+		}
+		return nil
+	})
+}
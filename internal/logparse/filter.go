@@ -0,0 +1,223 @@
+package logparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Op string
+
+const (
+	OpEq Op = "="
+	OpNe Op = "!="
+	OpLt Op = "<"
+	OpLe Op = "<="
+	OpGt Op = ">"
+	OpGe Op = ">="
+)
+
+// Ordered so that two character operators take precedence over their one
+// character prefixes.
+var ops = []Op{OpGe, OpLe, OpNe, OpEq, OpGt, OpLt}
+
+// Condition compares the value of a tag to an operand. Ordering comparisons
+// on the level tag compare by severity. Other ordering comparisons are
+// numeric when the operand is a number, and lexical otherwise.
+type Condition struct {
+	Key   string
+	Op    Op
+	Value string
+}
+
+// Filter is a conjunction of conditions. The zero filter matches all events.
+type Filter []Condition
+
+// ParseFilter parses a whitespace separated list of conditions, such as
+// `level>=warn request_id=abc`. Values containing whitespace may be double
+// quoted.
+func ParseFilter(s string) (Filter, error) {
+	var filter Filter
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return filter, nil
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return !isKeyRune(r)
+		})
+		if end == -1 {
+			return nil, fmt.Errorf("expected operator after %q", s)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("expected tag name at %q", s)
+		}
+		cond := Condition{Key: s[:end]}
+		s = s[end:]
+
+		for _, op := range ops {
+			if strings.HasPrefix(s, string(op)) {
+				cond.Op = op
+				break
+			}
+		}
+		if cond.Op == "" {
+			return nil, fmt.Errorf("expected operator after %q, found %q", cond.Key, s)
+		}
+		s = s[len(cond.Op):]
+
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for %q: %w", cond.Key, err)
+			}
+			cond.Value, _ = strconv.Unquote(quoted)
+			s = s[len(quoted):]
+		} else {
+			end := strings.IndexAny(s, " \t")
+			if end == -1 {
+				end = len(s)
+			}
+			cond.Value = s[:end]
+			s = s[end:]
+		}
+
+		if cond.Key == "level" {
+			cond.Value = NormalizeLevel(cond.Value)
+			if cond.isOrdering() && LevelRank(cond.Value) < 0 {
+				return nil, fmt.Errorf("unknown level %q, expected one of %s", cond.Value, strings.Join(levels, ", "))
+			}
+		}
+
+		filter = append(filter, cond)
+	}
+}
+
+func isKeyRune(r rune) bool {
+	return 'a' <= r && r <= 'z' ||
+		'A' <= r && r <= 'Z' ||
+		'0' <= r && r <= '9' ||
+		strings.ContainsRune("_-./@:", r)
+}
+
+func (cond Condition) isOrdering() bool {
+	switch cond.Op {
+	case OpLt, OpLe, OpGt, OpGe:
+		return true
+	default:
+		return false
+	}
+}
+
+func (cond Condition) isNumeric() bool {
+	_, err := strconv.ParseFloat(cond.Value, 64)
+	return err == nil
+}
+
+// Match reports whether a set of tags satisfies all conditions of the filter.
+// A missing tag satisfies only inequality conditions.
+func (f Filter) Match(tags map[string]string) bool {
+	for _, cond := range f {
+		if !cond.match(tags) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cond Condition) match(tags map[string]string) bool {
+	value, ok := tags[cond.Key]
+	switch cond.Op {
+	case OpEq:
+		return ok && value == cond.Value
+	case OpNe:
+		return !ok || value != cond.Value
+	}
+	if !ok {
+		return false
+	}
+
+	var cmp int
+	switch {
+	case cond.Key == "level":
+		rank := LevelRank(value)
+		if rank < 0 {
+			return false
+		}
+		cmp = rank - LevelRank(cond.Value)
+	case cond.isNumeric():
+		x, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		y, _ := strconv.ParseFloat(cond.Value, 64)
+		switch {
+		case x < y:
+			cmp = -1
+		case x > y:
+			cmp = 1
+		}
+	default:
+		cmp = strings.Compare(value, cond.Value)
+	}
+
+	switch cond.Op {
+	case OpLt:
+		return cmp < 0
+	case OpLe:
+		return cmp <= 0
+	case OpGt:
+		return cmp > 0
+	case OpGe:
+		return cmp >= 0
+	default:
+		panic(fmt.Errorf("unexpected operator: %q", cond.Op))
+	}
+}
+
+// SQL compiles the filter to an SQLite boolean expression over a column of
+// JSON-encoded tags, returning the expression and its arguments.
+func (f Filter) SQL(column string) (string, []any) {
+	if len(f) == 0 {
+		return "1", nil
+	}
+	exprs := make([]string, len(f))
+	var args []any
+	for i, cond := range f {
+		var condArgs []any
+		exprs[i], condArgs = cond.sql(column)
+		args = append(args, condArgs...)
+	}
+	return "(" + strings.Join(exprs, " AND ") + ")", args
+}
+
+func (cond Condition) sql(column string) (string, []any) {
+	path := `$."` + cond.Key + `"`
+	value := "json_extract(" + column + ", ?)"
+	switch cond.Op {
+	case OpEq:
+		return "CAST(" + value + " AS TEXT) = ?", []any{path, cond.Value}
+	case OpNe:
+		return "CAST(" + value + " AS TEXT) IS NOT ?", []any{path, cond.Value}
+	}
+
+	op := string(cond.Op)
+	switch {
+	case cond.Key == "level":
+		var b strings.Builder
+		b.WriteString("(CASE " + value)
+		for rank, level := range levels {
+			fmt.Fprintf(&b, " WHEN '%s' THEN %d", level, rank)
+		}
+		b.WriteString(" END) " + op + " ?")
+		return b.String(), []any{path, LevelRank(cond.Value)}
+	case cond.isNumeric():
+		// Non-numeric values would otherwise be cast to zero.
+		x, _ := strconv.ParseFloat(cond.Value, 64)
+		return "(" + value + " GLOB '*[0-9]*' AND " + value + " NOT GLOB '*[^0-9.eE+-]*' AND CAST(" + value + " AS REAL) " + op + " ?)",
+			[]any{path, path, path, x}
+	default:
+		return "CAST(" + value + " AS TEXT) " + op + " ?", []any{path, cond.Value}
+	}
+}
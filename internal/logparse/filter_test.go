@@ -0,0 +1,94 @@
+package logparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter(` level>=WARNING  request_id=abc path!="/health check" http.status<500`)
+	require.NoError(t, err)
+	assert.Equal(t, Filter{
+		{Key: "level", Op: OpGe, Value: "warn"},
+		{Key: "request_id", Op: OpEq, Value: "abc"},
+		{Key: "path", Op: OpNe, Value: "/health check"},
+		{Key: "http.status", Op: OpLt, Value: "500"},
+	}, filter)
+
+	filter, err = ParseFilter("")
+	require.NoError(t, err)
+	assert.Empty(t, filter)
+
+	for _, s := range []string{
+		"level",
+		"=abc",
+		"level~warn",
+		"level>=loud",
+		`name="unterminated`,
+	} {
+		_, err := ParseFilter(s)
+		assert.Error(t, err, s)
+	}
+}
+
+var filterTests = []struct {
+	Filter   string
+	Tags     map[string]string
+	Expected bool
+}{
+	{"", map[string]string{}, true},
+	{"level>=warn", map[string]string{"level": "error"}, true},
+	{"level>=warn", map[string]string{"level": "warn"}, true},
+	{"level>=warn", map[string]string{"level": "info"}, false},
+	{"level>=warn", map[string]string{"level": "verbose"}, false},
+	{"level>=warn", map[string]string{}, false},
+	{"level<info", map[string]string{"level": "debug"}, true},
+	{"level=warn", map[string]string{"level": "warn"}, true},
+	{"request_id=abc", map[string]string{"request_id": "abc"}, true},
+	{"request_id=abc", map[string]string{"request_id": "abcd"}, false},
+	{"request_id=abc", map[string]string{}, false},
+	{"request_id!=abc", map[string]string{}, true},
+	{"request_id!=abc", map[string]string{"request_id": "abc"}, false},
+	{"status>=500", map[string]string{"status": "503"}, true},
+	{"status>=500", map[string]string{"status": "60"}, false},
+	{"status>=500", map[string]string{"status": "n/a"}, false},
+	{"duration<0.5", map[string]string{"duration": "0.25"}, true},
+	{"name>m", map[string]string{"name": "zed"}, true},
+	{"name>m", map[string]string{"name": "alice"}, false},
+	{"level>=warn stdio=err", map[string]string{"level": "error", "stdio": "out"}, false},
+	{"level>=warn stdio=err", map[string]string{"level": "error", "stdio": "err"}, true},
+	{"http.status=200", map[string]string{"http.status": "200"}, true},
+}
+
+func TestFilterMatch(t *testing.T) {
+	for _, test := range filterTests {
+		filter, err := ParseFilter(test.Filter)
+		require.NoError(t, err)
+		assert.Equal(t, test.Expected, filter.Match(test.Tags), "%s with %v", test.Filter, test.Tags)
+	}
+}
+
+func TestFilterSQL(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, test := range filterTests {
+		filter, err := ParseFilter(test.Filter)
+		require.NoError(t, err)
+		tags, err := json.Marshal(test.Tags)
+		require.NoError(t, err)
+		expr, args := filter.SQL("tags")
+		var actual bool
+		query := fmt.Sprintf("SELECT coalesce(%s, 0) FROM (SELECT ? AS tags)", expr)
+		err = db.Get(&actual, query, append(args, string(tags))...)
+		require.NoError(t, err, test.Filter)
+		assert.Equal(t, test.Expected, actual, "%s with %v", test.Filter, test.Tags)
+	}
+}
@@ -0,0 +1,58 @@
+package logparse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Canonical levels, in order of increasing severity.
+var levels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+var levelAliases = map[string]string{
+	"trc":         "trace",
+	"dbg":         "debug",
+	"inf":         "info",
+	"information": "info",
+	"notice":      "info",
+	"wrn":         "warn",
+	"warning":     "warn",
+	"err":         "error",
+	"crit":        "fatal",
+	"critical":    "fatal",
+	"alert":       "fatal",
+	"emerg":       "fatal",
+	"panic":       "fatal",
+}
+
+// NormalizeLevel maps common spellings of log levels to one of trace, debug,
+// info, warn, error, or fatal. Numeric levels are interpreted with the
+// conventions of bunyan and pino, where 30 is info. Unrecognized levels are
+// lowercased, but otherwise returned as is.
+func NormalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if LevelRank(level) >= 0 {
+		return level
+	}
+	if alias, ok := levelAliases[level]; ok {
+		return alias
+	}
+	if n, err := strconv.Atoi(level); err == nil && n >= 10 {
+		rank := n/10 - 1
+		if rank >= len(levels) {
+			rank = len(levels) - 1
+		}
+		return levels[rank]
+	}
+	return level
+}
+
+// LevelRank returns the severity of a canonical level, or -1 if the level is
+// not canonical.
+func LevelRank(level string) int {
+	for rank, l := range levels {
+		if l == level {
+			return rank
+		}
+	}
+	return -1
+}
@@ -0,0 +1,64 @@
+package logparse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseLogfmt parses space-separated key=value pairs. Values may be quoted
+// with Go string escapes. A bare key is interpreted as "true". Returns nil if
+// the line contains no key=value pairs, so that unstructured text is not
+// mistaken for a sequence of bare keys.
+func parseLogfmt(line string) map[string]string {
+	fields := make(map[string]string)
+	sawPair := false
+	s := line
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			break
+		}
+
+		end := strings.IndexAny(s, "= \t")
+		if end == -1 {
+			end = len(s)
+		}
+		key := s[:end]
+		s = s[end:]
+		if key == "" || strings.ContainsRune(key, '"') {
+			return nil
+		}
+
+		if !strings.HasPrefix(s, "=") {
+			fields[key] = "true"
+			continue
+		}
+		s = s[1:]
+		sawPair = true
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil
+			}
+			value, err = strconv.Unquote(quoted)
+			if err != nil {
+				return nil
+			}
+			s = s[len(quoted):]
+		} else {
+			end := strings.IndexAny(s, " \t")
+			if end == -1 {
+				end = len(s)
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+		fields[key] = value
+	}
+	if !sawPair {
+		return nil
+	}
+	return fields
+}
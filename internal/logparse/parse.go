@@ -0,0 +1,156 @@
+// Package logparse extracts structured fields from log lines written by
+// processes that emit JSON or logfmt, and filters events on those fields.
+package logparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatPlain  Format = "plain"
+	FormatJSON   Format = "json"
+	FormatLogfmt Format = "logfmt"
+)
+
+// ParseFormat validates a log format name. The empty string is treated as
+// plain.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatPlain:
+		return FormatPlain, nil
+	case FormatJSON, FormatLogfmt:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("unsupported log format %q, expected one of json, logfmt, or plain", s)
+	}
+}
+
+// Entry is the structured interpretation of a single log line.
+type Entry struct {
+	// Zero if the line did not carry a recognized timestamp.
+	Timestamp time.Time
+	// Fields of the line, excluding the message and timestamp. A recognized
+	// level is normalized and stored under the "level" key.
+	Fields map[string]string
+}
+
+// Keys that conventionally hold the message, timestamp, and level of a
+// structured log line, in order of preference.
+var (
+	messageKeys   = []string{"msg", "message"}
+	timestampKeys = []string{"time", "ts", "timestamp"}
+	levelKeys     = []string{"level", "lvl", "severity"}
+)
+
+// Parse extracts structured fields from a line. Lines that are not valid for
+// the given format yield an empty entry, as do all plain lines.
+func Parse(format Format, line string) Entry {
+	var fields map[string]string
+	switch format {
+	case FormatJSON:
+		fields = parseJSON(line)
+	case FormatLogfmt:
+		fields = parseLogfmt(line)
+	}
+	var entry Entry
+	if len(fields) == 0 {
+		return entry
+	}
+
+	// The message is not duplicated in to fields, since the original line is
+	// retained as the event message.
+	for _, key := range messageKeys {
+		delete(fields, key)
+	}
+
+	for _, key := range timestampKeys {
+		if value, ok := fields[key]; ok {
+			if t, ok := parseTimestamp(value); ok {
+				entry.Timestamp = t
+				delete(fields, key)
+				break
+			}
+		}
+	}
+
+	for _, key := range levelKeys {
+		if value, ok := fields[key]; ok {
+			delete(fields, key)
+			fields["level"] = NormalizeLevel(value)
+			break
+		}
+	}
+
+	entry.Fields = fields
+	return entry
+}
+
+func parseJSON(line string) map[string]string {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil
+	}
+	fields := make(map[string]string, len(obj))
+	flattenJSON(fields, "", obj)
+	return fields
+}
+
+// Nested objects are flattened with dot-separated keys. Arrays are retained
+// as JSON text.
+func flattenJSON(fields map[string]string, prefix string, obj map[string]any) {
+	for key, value := range obj {
+		key = prefix + key
+		switch value := value.(type) {
+		case map[string]any:
+			flattenJSON(fields, key+".", value)
+		case string:
+			fields[key] = value
+		case json.Number:
+			fields[key] = value.String()
+		case bool:
+			fields[key] = strconv.FormatBool(value)
+		case nil:
+			fields[key] = ""
+		default:
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			_ = enc.Encode(value)
+			fields[key] = strings.TrimSuffix(buf.String(), "\n")
+		}
+	}
+}
+
+func parseTimestamp(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	}
+	// Numeric timestamps are Unix epoch times. The unit is inferred from the
+	// magnitude, covering seconds (possibly fractional), milliseconds,
+	// microseconds, and nanoseconds.
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f <= 0 || math.IsInf(f, 0) {
+		return time.Time{}, false
+	}
+	switch {
+	case f < 1e11:
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+	case f < 1e14:
+		return time.UnixMilli(int64(f)).UTC(), true
+	case f < 1e17:
+		return time.UnixMicro(int64(f)).UTC(), true
+	default:
+		return time.Unix(0, int64(f)).UTC(), true
+	}
+}
@@ -0,0 +1,81 @@
+package logparse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSON(t *testing.T) {
+	entry := Parse(FormatJSON, `{"level":"WARNING","time":"2021-10-01T12:00:00.5Z","msg":"slow request","request_id":"abc","http":{"status":503,"ok":false},"tags":["a","b"]}`)
+	assert.Equal(t, time.Date(2021, 10, 1, 12, 0, 0, 5e8, time.UTC), entry.Timestamp.UTC())
+	assert.Equal(t, map[string]string{
+		"level":       "warn",
+		"request_id":  "abc",
+		"http.status": "503",
+		"http.ok":     "false",
+		"tags":        `["a","b"]`,
+	}, entry.Fields)
+}
+
+func TestParseLogfmt(t *testing.T) {
+	entry := Parse(FormatLogfmt, `ts=1633089600 lvl=err msg="failed to connect" addr=localhost:5432 retry`)
+	assert.Equal(t, time.Unix(1633089600, 0).UTC(), entry.Timestamp)
+	assert.Equal(t, map[string]string{
+		"level": "error",
+		"addr":  "localhost:5432",
+		"retry": "true",
+	}, entry.Fields)
+}
+
+func TestParseUnstructured(t *testing.T) {
+	for _, test := range []struct {
+		format Format
+		line   string
+	}{
+		{FormatPlain, `level=info msg=hello`},
+		{FormatJSON, `not json`},
+		{FormatJSON, `[1, 2]`},
+		{FormatLogfmt, `just some text`},
+		{FormatLogfmt, `msg="unterminated`},
+	} {
+		entry := Parse(test.format, test.line)
+		assert.Empty(t, entry.Fields, "%s: %s", test.format, test.line)
+		assert.True(t, entry.Timestamp.IsZero(), "%s: %s", test.format, test.line)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	expected := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	for _, s := range []string{
+		"2021-10-01T12:00:00Z",
+		"2021-10-01T14:00:00+02:00",
+		"1633089600",
+		"1633089600.0",
+		"1633089600000",
+		"1633089600000000",
+		"1633089600000000000",
+	} {
+		actual, ok := parseTimestamp(s)
+		if assert.True(t, ok, s) {
+			assert.True(t, expected.Equal(actual), "%s: %s", s, actual)
+		}
+	}
+	_, ok := parseTimestamp("yesterday")
+	assert.False(t, ok)
+}
+
+func TestNormalizeLevel(t *testing.T) {
+	for input, expected := range map[string]string{
+		"INFO":    "info",
+		"Warning": "warn",
+		"crit":    "fatal",
+		"10":      "trace",
+		"50":      "error",
+		"70":      "fatal",
+		"verbose": "verbose",
+	} {
+		assert.Equal(t, expected, NormalizeLevel(input), input)
+	}
+}
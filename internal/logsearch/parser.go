@@ -0,0 +1,217 @@
+package logsearch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+func (p *parser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+// Consumes the given operator keyword, if it is next in the input.
+func (p *parser) keyword(kw string) bool {
+	p.skipSpace()
+	rest := p.input[p.pos:]
+	if !strings.HasPrefix(rest, kw) {
+		return false
+	}
+	if len(rest) > len(kw) {
+		next, _ := utf8.DecodeRuneInString(rest[len(kw):])
+		if !unicode.IsSpace(next) && next != '(' && next != '"' && next != '/' {
+			return false
+		}
+	}
+	p.pos += len(kw)
+	return true
+}
+
+// Returns nil at the end of input or group.
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil || left == nil {
+		return left, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if right == nil {
+			return nil, fmt.Errorf("expected term after OR at offset %d", p.pos)
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	var left node
+	for {
+		explicit := p.keyword("AND")
+		if !explicit {
+			switch p.peek() {
+			case 0, ')':
+				return left, nil
+			}
+			if p.atKeyword("OR") {
+				return left, nil
+			}
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if right == nil {
+			if explicit {
+				return nil, fmt.Errorf("expected term after AND at offset %d", p.pos)
+			}
+			return left, nil
+		}
+		if left == nil {
+			if explicit {
+				return nil, fmt.Errorf("expected term before AND at offset %d", p.pos)
+			}
+			left = right
+		} else {
+			left = &andNode{left: left, right: right}
+		}
+	}
+}
+
+func (p *parser) atKeyword(kw string) bool {
+	pos := p.pos
+	ok := p.keyword(kw)
+	p.pos = pos
+	return ok
+}
+
+func (p *parser) parseUnary() (node, error) {
+	negate := p.keyword("NOT")
+	if !negate && p.peek() == '-' {
+		p.pos++
+		negate = true
+	}
+	if negate {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand == nil {
+			return nil, fmt.Errorf("expected term after NOT at offset %d", p.pos)
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	switch p.peek() {
+	case 0, ')':
+		return nil, nil
+	case '(':
+		start := p.pos
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("unclosed parenthesis at offset %d", start)
+		}
+		p.pos++
+		if inner == nil {
+			return nil, fmt.Errorf("empty parentheses at offset %d", start)
+		}
+		return inner, nil
+	case '"':
+		return p.parsePhrase()
+	case '/':
+		return p.parseRegexp()
+	default:
+		return p.parseWord()
+	}
+}
+
+func (p *parser) parsePhrase() (node, error) {
+	start := p.pos
+	end := strings.IndexByte(p.input[start+1:], '"')
+	if end == -1 {
+		return nil, fmt.Errorf("unclosed quote at offset %d", start)
+	}
+	text := p.input[start+1 : start+1+end]
+	p.pos = start + end + 2
+	prefix := false
+	if strings.HasPrefix(p.input[p.pos:], "*") {
+		prefix = true
+		p.pos++
+	}
+	return newPhrase(text, prefix)
+}
+
+func (p *parser) parseRegexp() (node, error) {
+	start := p.pos
+	var b strings.Builder
+	for i := start + 1; i < len(p.input); i++ {
+		c := p.input[i]
+		switch {
+		case c == '\\' && i+1 < len(p.input) && p.input[i+1] == '/':
+			b.WriteByte('/')
+			i++
+		case c == '/':
+			p.pos = i + 1
+			re, err := regexp.Compile(b.String())
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression at offset %d: %w", start, err)
+			}
+			return &regexpNode{re: re}, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return nil, fmt.Errorf("unclosed regular expression at offset %d", start)
+}
+
+func (p *parser) parseWord() (node, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		p.pos += size
+	}
+	text := p.input[start:p.pos]
+	prefix := strings.HasSuffix(text, "*")
+	return newPhrase(strings.TrimSuffix(text, "*"), prefix)
+}
+
+func newPhrase(text string, prefix bool) (node, error) {
+	words := Words(text)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("search term %q contains no words", text)
+	}
+	return &phraseNode{words: words, prefix: prefix}, nil
+}
@@ -0,0 +1,187 @@
+// Package logsearch implements the query language of log search.
+//
+// A query is a boolean combination of terms. Words match whole words of a
+// message, case-insensitively, and may end in * to match a prefix. Double
+// quoted phrases match consecutive words. Words containing punctuation, such
+// as request_id, are treated as phrases. Terms delimited with slashes are
+// regular expressions, matched against the entire message.
+//
+// Adjacent terms must all match. Terms may be combined with the operators
+// AND, OR, and NOT, which must be capitalized. A leading - is shorthand for
+// NOT. Parentheses group terms. NOT binds tightest, followed by AND, then OR.
+//
+// For example:
+//
+//	timeout OR "connection refused"
+//	error -/health(check)?/
+//	(warn OR error) NOT request_id
+package logsearch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type Query struct {
+	root node
+}
+
+// Parse parses a query. The empty query is an error.
+func Parse(s string) (*Query, error) {
+	p := &parser{input: s}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.input[p.pos:], p.pos)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty query")
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether a message satisfies the query.
+func (q *Query) Match(message string) bool {
+	return q.root.match(&document{message: message})
+}
+
+// FTS returns an SQLite FTS5 expression that matches a superset of the
+// messages matched by the query, so that candidate messages may be found with
+// a full-text index before checking them with Match. Returns an empty string
+// if the index cannot narrow the search, such as for a query consisting only of
+// a regular expression.
+func (q *Query) FTS() string {
+	expr, _ := q.root.fts()
+	return expr
+}
+
+type node interface {
+	match(doc *document) bool
+	// Returns false if every message may match.
+	fts() (string, bool)
+}
+
+type document struct {
+	message string
+	words   []string
+}
+
+func (doc *document) getWords() []string {
+	if doc.words == nil {
+		doc.words = Words(doc.message)
+	}
+	return doc.words
+}
+
+// Words splits text in to lowercase words, consisting of letters and digits.
+// This approximates the unicode61 tokenizer of SQLite's full-text index.
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n *andNode) match(doc *document) bool {
+	return n.left.match(doc) && n.right.match(doc)
+}
+
+func (n *andNode) fts() (string, bool) {
+	left, leftOK := n.left.fts()
+	right, rightOK := n.right.fts()
+	switch {
+	case leftOK && rightOK:
+		return "(" + left + " AND " + right + ")", true
+	case leftOK:
+		return left, true
+	case rightOK:
+		return right, true
+	default:
+		return "", false
+	}
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n *orNode) match(doc *document) bool {
+	return n.left.match(doc) || n.right.match(doc)
+}
+
+func (n *orNode) fts() (string, bool) {
+	left, leftOK := n.left.fts()
+	right, rightOK := n.right.fts()
+	if !leftOK || !rightOK {
+		return "", false
+	}
+	return "(" + left + " OR " + right + ")", true
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) match(doc *document) bool {
+	return !n.operand.match(doc)
+}
+
+func (n *notNode) fts() (string, bool) {
+	// FTS5 supports only binary NOT. Since any message may fail to match the
+	// operand, the negation does not narrow the search.
+	return "", false
+}
+
+type phraseNode struct {
+	words  []string
+	prefix bool
+}
+
+func (n *phraseNode) match(doc *document) bool {
+	words := doc.getWords()
+	last := len(n.words) - 1
+outer:
+	for i := 0; i+last < len(words); i++ {
+		for j, word := range n.words {
+			candidate := words[i+j]
+			if j == last && n.prefix {
+				if !strings.HasPrefix(candidate, word) {
+					continue outer
+				}
+			} else if candidate != word {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (n *phraseNode) fts() (string, bool) {
+	// Words contain only letters and digits, so need no escaping.
+	expr := `"` + strings.Join(n.words, " ") + `"`
+	if n.prefix {
+		expr += " *"
+	}
+	return expr, true
+}
+
+type regexpNode struct {
+	re *regexp.Regexp
+}
+
+func (n *regexpNode) match(doc *document) bool {
+	return n.re.MatchString(doc.message)
+}
+
+func (n *regexpNode) fts() (string, bool) {
+	return "", false
+}
@@ -0,0 +1,91 @@
+package logsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	messages := []string{
+		"GET /health 200",
+		"connection refused: dial tcp 127.0.0.1:5432",
+		"Connected to database",
+		"request_id=abc timeout after 30s",
+		"ERROR: Timeout waiting for lock",
+	}
+	for _, test := range []struct {
+		Query    string
+		Expected []int
+	}{
+		{"timeout", []int{3, 4}},
+		{"TIMEOUT", []int{3, 4}},
+		{"conn*", []int{1, 2}},
+		{"connect", nil},
+		{`"connection refused"`, []int{1}},
+		{`"refused connection"`, nil},
+		{"request_id", []int{3}},
+		{"127.0.0.1", []int{1}},
+		{"timeout lock", []int{4}},
+		{"timeout AND lock", []int{4}},
+		{"health OR database", []int{0, 2}},
+		{"timeout -lock", []int{3}},
+		{"timeout NOT lock", []int{3}},
+		{"NOT timeout", []int{0, 1, 2}},
+		{"(health OR database) OR lock", []int{0, 2, 4}},
+		{"conn* (refused OR database)", []int{1, 2}},
+		{`/ \d{3}$/`, []int{0}},
+		{`/^[A-Z]+:/`, []int{4}},
+		{`/health\/?/ OR refused`, []int{0, 1}},
+	} {
+		q, err := Parse(test.Query)
+		require.NoError(t, err, test.Query)
+		var actual []int
+		for i, message := range messages {
+			if q.Match(message) {
+				actual = append(actual, i)
+			}
+		}
+		assert.Equal(t, test.Expected, actual, test.Query)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"   ",
+		"()",
+		"(timeout",
+		"timeout)",
+		`"unclosed`,
+		"/unclosed",
+		"/[/",
+		"timeout OR",
+		"AND timeout",
+		"NOT",
+		"---",
+	} {
+		_, err := Parse(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestFTS(t *testing.T) {
+	for query, expected := range map[string]string{
+		"timeout":                  `"timeout"`,
+		"conn*":                    `"conn" *`,
+		"request_id":               `"request id"`,
+		"a b":                      `("a" AND "b")`,
+		"a OR b":                   `("a" OR "b")`,
+		"a -b":                     `"a"`,
+		"NOT a":                    ``,
+		"/re/":                     ``,
+		"a OR /re/":                ``,
+		`"connection refused" /x/`: `"connection refused"`,
+	} {
+		q, err := Parse(query)
+		require.NoError(t, err, query)
+		assert.Equal(t, expected, q.FTS(), query)
+	}
+}
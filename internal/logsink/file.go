@@ -0,0 +1,118 @@
+package logsink
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultFileMaxBytes = 10 * 1024 * 1024
+	defaultFileMaxFiles = 5
+)
+
+// Appends events as JSON lines to a file. When the file would exceed
+// MaxBytes, it is renamed to Path + ".1", previously rotated files are
+// shifted up by one, and files beyond MaxFiles are removed.
+type FileSink struct {
+	Path string
+	// Defaults to 10 MiB.
+	MaxBytes int64
+	// Defaults to 5.
+	MaxFiles int
+
+	f    *os.File
+	size int64
+}
+
+func (sink *FileSink) maxBytes() int64 {
+	if sink.MaxBytes == 0 {
+		return defaultFileMaxBytes
+	}
+	return sink.MaxBytes
+}
+
+func (sink *FileSink) maxFiles() int {
+	if sink.MaxFiles == 0 {
+		return defaultFileMaxFiles
+	}
+	return sink.MaxFiles
+}
+
+func (sink *FileSink) Export(ctx context.Context, events []Event) error {
+	for _, event := range events {
+		line, err := marshalLine(event)
+		if err != nil {
+			return fmt.Errorf("marshalling event: %w", err)
+		}
+		if sink.f == nil {
+			if err := sink.open(); err != nil {
+				return err
+			}
+		}
+		// Opening may find a file that was filled by a previous sink.
+		if sink.size > 0 && sink.size+int64(len(line)) > sink.maxBytes() {
+			if err := sink.rotate(); err != nil {
+				return fmt.Errorf("rotating: %w", err)
+			}
+			if err := sink.open(); err != nil {
+				return err
+			}
+		}
+		n, err := sink.f.Write(line)
+		sink.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sink *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(sink.Path), 0700); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	f, err := os.OpenFile(sink.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	sink.f = f
+	sink.size = info.Size()
+	return nil
+}
+
+func (sink *FileSink) rotate() error {
+	if err := sink.Close(); err != nil {
+		return err
+	}
+	n := sink.maxFiles()
+	if err := os.Remove(sink.rotatedPath(n)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := n - 1; i >= 1; i-- {
+		if err := os.Rename(sink.rotatedPath(i), sink.rotatedPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(sink.Path, sink.rotatedPath(1))
+}
+
+func (sink *FileSink) rotatedPath(i int) string {
+	return fmt.Sprintf("%s.%d", sink.Path, i)
+}
+
+func (sink *FileSink) Close() error {
+	if sink.f == nil {
+		return nil
+	}
+	err := sink.f.Close()
+	sink.f = nil
+	sink.size = 0
+	return err
+}
@@ -0,0 +1,85 @@
+package logsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readMessages(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var messages []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		messages = append(messages, rec.Message)
+	}
+	require.NoError(t, scanner.Err())
+	return messages
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "logs", "exo.jsonl")
+	event := func(i int) Event {
+		return Event{
+			Resource: Resource{
+				WorkspaceID: "ws",
+				ComponentID: "cmp",
+			},
+			Timestamp: time.Date(2021, 10, 1, 12, 0, i, 0, time.UTC),
+			Message:   fmt.Sprintf("message %d", i),
+		}
+	}
+	line, err := marshalLine(event(0))
+	require.NoError(t, err)
+
+	// Each file fits two events.
+	sink := &FileSink{
+		Path:     path,
+		MaxBytes: int64(2*len(line) + 1),
+		MaxFiles: 2,
+	}
+	for i := 0; i < 7; i++ {
+		require.NoError(t, sink.Export(ctx, []Event{event(i)}))
+	}
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{"message 6"}, readMessages(t, path))
+	assert.Equal(t, []string{"message 4", "message 5"}, readMessages(t, path+".1"))
+	assert.Equal(t, []string{"message 2", "message 3"}, readMessages(t, path+".2"))
+	assert.NoFileExists(t, path+".3")
+
+	// Appends to an existing file.
+	sink = &FileSink{
+		Path:     path,
+		MaxBytes: sink.MaxBytes,
+		MaxFiles: 2,
+	}
+	require.NoError(t, sink.Export(ctx, []Event{event(7), event(8)}))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"message 8"}, readMessages(t, path))
+	assert.Equal(t, []string{"message 6", "message 7"}, readMessages(t, path+".1"))
+
+	// Rotates an existing file that is already full.
+	sink = &FileSink{
+		Path:     path,
+		MaxBytes: int64(len(line) + 1),
+		MaxFiles: 2,
+	}
+	require.NoError(t, sink.Export(ctx, []Event{event(9)}))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, []string{"message 9"}, readMessages(t, path))
+	assert.Equal(t, []string{"message 8"}, readMessages(t, path+".1"))
+}
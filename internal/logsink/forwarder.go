@@ -0,0 +1,195 @@
+package logsink
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deref/exo/internal/util/logging"
+)
+
+const (
+	forwarderChannelBufferSize = 4096
+	forwarderBatchSize         = 512
+	forwarderExportTimeout     = 10 * time.Second
+)
+
+var forwarderFlushInterval = time.Second
+
+// Exports events to sinks in batches from a background goroutine. Global sinks
+// receive all events, while workspace sinks receive only the events of their
+// workspace. Events are dropped, rather than blocking the caller, when sinks
+// fall behind.
+type Forwarder struct {
+	logger logging.Logger
+	events chan Event
+	flush  chan chan struct{}
+	done   chan struct{}
+	cancel context.CancelFunc
+	// Accessed atomically.
+	dropped uint64
+
+	mx         sync.Mutex
+	global     []Sink
+	workspaces map[string][]Sink
+}
+
+func NewForwarder(ctx context.Context, logger logging.Logger, global []Sink) *Forwarder {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Forwarder{
+		logger:     logger,
+		events:     make(chan Event, forwarderChannelBufferSize),
+		flush:      make(chan chan struct{}),
+		done:       make(chan struct{}),
+		cancel:     cancel,
+		global:     global,
+		workspaces: make(map[string][]Sink),
+	}
+	go f.run(ctx)
+	return f
+}
+
+// Replaces the sinks of a workspace, closing the previous ones. Empty sinks
+// stops exporting the workspace's events beyond the global sinks.
+func (f *Forwarder) SetWorkspaceSinks(workspaceID string, sinks []Sink) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.closeSinks(f.workspaces[workspaceID])
+	if len(sinks) == 0 {
+		delete(f.workspaces, workspaceID)
+	} else {
+		f.workspaces[workspaceID] = sinks
+	}
+}
+
+// Enqueues an event for export. Returns false if the event was dropped.
+func (f *Forwarder) Send(event Event) bool {
+	select {
+	case f.events <- event:
+		return true
+	default:
+		atomic.AddUint64(&f.dropped, 1)
+		return false
+	}
+}
+
+// Number of events dropped because the queue was full.
+func (f *Forwarder) Dropped() uint64 {
+	return atomic.LoadUint64(&f.dropped)
+}
+
+// Waits for previously sent events to be exported.
+func (f *Forwarder) Flush() {
+	notify := make(chan struct{})
+	select {
+	case f.flush <- notify:
+		<-notify
+	case <-f.done:
+	}
+}
+
+// Exports remaining events and closes all sinks.
+func (f *Forwarder) Close() {
+	f.cancel()
+	<-f.done
+}
+
+func (f *Forwarder) run(ctx context.Context) {
+	defer close(f.done)
+
+	ticker := time.NewTicker(forwarderFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, forwarderBatchSize)
+	export := func() {
+		if len(batch) > 0 {
+			f.export(batch)
+			batch = batch[:0]
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case event := <-f.events:
+				batch = append(batch, event)
+				if len(batch) == forwarderBatchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			drain()
+			f.mx.Lock()
+			f.closeSinks(f.global)
+			for _, sinks := range f.workspaces {
+				f.closeSinks(sinks)
+			}
+			f.global = nil
+			f.workspaces = nil
+			f.mx.Unlock()
+			return
+
+		case <-ticker.C:
+			export()
+
+		case event := <-f.events:
+			batch = append(batch, event)
+			if len(batch) == forwarderBatchSize {
+				export()
+			}
+
+		case notify := <-f.flush:
+			drain()
+			close(notify)
+		}
+	}
+}
+
+func (f *Forwarder) export(events []Event) {
+	// Exporting under the lock prevents workspace sinks from being closed
+	// while in use.
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), forwarderExportTimeout)
+	defer cancel()
+
+	for _, sink := range f.global {
+		if err := sink.Export(ctx, events); err != nil {
+			f.logger.Infof("exporting %d events: %v", len(events), err)
+		}
+	}
+	if len(f.workspaces) == 0 {
+		return
+	}
+	byWorkspace := make(map[string][]Event)
+	for _, event := range events {
+		id := event.Resource.WorkspaceID
+		if _, ok := f.workspaces[id]; ok {
+			byWorkspace[id] = append(byWorkspace[id], event)
+		}
+	}
+	for id, events := range byWorkspace {
+		for _, sink := range f.workspaces[id] {
+			if err := sink.Export(ctx, events); err != nil {
+				f.logger.Infof("exporting %d events of workspace %s: %v", len(events), id, err)
+			}
+		}
+	}
+}
+
+func (f *Forwarder) closeSinks(sinks []Sink) {
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			f.logger.Infof("closing log sink: %v", err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package logsink
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySink struct {
+	mx       sync.Mutex
+	messages []string
+	closed   bool
+}
+
+func (sink *memorySink) Export(ctx context.Context, events []Event) error {
+	sink.mx.Lock()
+	defer sink.mx.Unlock()
+	for _, event := range events {
+		sink.messages = append(sink.messages, event.Message)
+	}
+	return nil
+}
+
+func (sink *memorySink) Close() error {
+	sink.mx.Lock()
+	defer sink.mx.Unlock()
+	sink.closed = true
+	return nil
+}
+
+func TestForwarder(t *testing.T) {
+	global := &memorySink{}
+	ws1 := &memorySink{}
+	ws2 := &memorySink{}
+	f := NewForwarder(context.Background(), &logging.NopLogger{}, []Sink{global})
+	f.SetWorkspaceSinks("ws1", []Sink{ws1})
+	f.SetWorkspaceSinks("ws2", []Sink{ws2})
+
+	send := func(workspaceID, message string) {
+		assert.True(t, f.Send(Event{
+			Resource: Resource{WorkspaceID: workspaceID},
+			Message:  message,
+		}))
+	}
+	send("ws1", "a")
+	send("ws2", "b")
+	send("", "c")
+	f.Flush()
+
+	assert.Equal(t, []string{"a", "b", "c"}, global.messages)
+	assert.Equal(t, []string{"a"}, ws1.messages)
+	assert.Equal(t, []string{"b"}, ws2.messages)
+
+	// Replaced sinks are closed.
+	f.SetWorkspaceSinks("ws1", nil)
+	assert.True(t, ws1.closed)
+	send("ws1", "d")
+	send("ws2", "e")
+	f.Close()
+
+	assert.Equal(t, []string{"a"}, ws1.messages)
+	assert.Equal(t, []string{"b", "e"}, ws2.messages)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, global.messages)
+	assert.True(t, global.closed)
+	assert.True(t, ws2.closed)
+	assert.Equal(t, uint64(0), f.Dropped())
+}
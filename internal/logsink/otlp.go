@@ -0,0 +1,207 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/deref/exo/internal/about"
+	"github.com/deref/exo/internal/logparse"
+)
+
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/logs"
+
+// Exports events to an OpenTelemetry collector with the JSON encoding of
+// OTLP/HTTP. The workspace, stack, and component IDs of each event become
+// resource attributes, and tags become log record attributes. Severity is
+// derived from the "level" tag, as set by structured log parsing.
+type OTLPSink struct {
+	Endpoint string
+	Headers  map[string]string
+	Client   *http.Client
+}
+
+func NewOTLPSink(endpoint string, headers map[string]string) *OTLPSink {
+	if endpoint == "" {
+		endpoint = DefaultOTLPEndpoint
+	}
+	return &OTLPSink{
+		Endpoint: endpoint,
+		Headers:  headers,
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (sink *OTLPSink) Export(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(newOTLPRequest(events))
+	if err != nil {
+		return fmt.Errorf("marshalling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range sink.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := sink.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (sink *OTLPSink) Close() error {
+	return nil
+}
+
+// See <https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/logs/v1/logs.proto>.
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func stringAttribute(key, value string) otlpKeyValue {
+	return otlpKeyValue{
+		Key:   key,
+		Value: otlpAnyValue{StringValue: value},
+	}
+}
+
+// Severity numbers of the canonical levels, per the OpenTelemetry log data
+// model.
+var otlpSeverities = map[string]int{
+	"trace": 1,
+	"debug": 5,
+	"info":  9,
+	"warn":  13,
+	"error": 17,
+	"fatal": 21,
+}
+
+func newOTLPRequest(events []Event) otlpRequest {
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	// Group records by resource, preserving the order of first appearance.
+	var req otlpRequest
+	indexes := make(map[Resource]int)
+	for _, event := range events {
+		i, ok := indexes[event.Resource]
+		if !ok {
+			i = len(req.ResourceLogs)
+			indexes[event.Resource] = i
+			req.ResourceLogs = append(req.ResourceLogs, otlpResourceLogs{
+				Resource: otlpResource{
+					Attributes: resourceAttributes(event.Resource),
+				},
+				ScopeLogs: []otlpScopeLogs{{
+					Scope: otlpScope{
+						Name:    "exo",
+						Version: about.Version,
+					},
+				}},
+			})
+		}
+		scope := &req.ResourceLogs[i].ScopeLogs[0]
+		scope.LogRecords = append(scope.LogRecords, newOTLPLogRecord(event, observed))
+	}
+	return req
+}
+
+func resourceAttributes(res Resource) []otlpKeyValue {
+	attrs := []otlpKeyValue{
+		stringAttribute("service.name", "exo"),
+	}
+	for _, attr := range []struct {
+		Key   string
+		Value string
+	}{
+		{"exo.workspace.id", res.WorkspaceID},
+		{"exo.stack.id", res.StackID},
+		{"exo.component.id", res.ComponentID},
+	} {
+		if attr.Value != "" {
+			attrs = append(attrs, stringAttribute(attr.Key, attr.Value))
+		}
+	}
+	return attrs
+}
+
+func newOTLPLogRecord(event Event, observed string) otlpLogRecord {
+	record := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(event.Timestamp.UnixNano(), 10),
+		ObservedTimeUnixNano: observed,
+		Body:                 otlpAnyValue{StringValue: event.Message},
+	}
+	if level := logparse.NormalizeLevel(event.Tags["level"]); level != "" {
+		record.SeverityNumber = otlpSeverities[level]
+		record.SeverityText = level
+	}
+	if event.Type != "" {
+		record.Attributes = append(record.Attributes, stringAttribute("exo.event.type", event.Type))
+	}
+	keys := make([]string, 0, len(event.Tags))
+	for k := range event.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		record.Attributes = append(record.Attributes, stringAttribute(k, event.Tags[k]))
+	}
+	return record
+}
@@ -0,0 +1,91 @@
+package logsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deref/exo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPSink(t *testing.T) {
+	var requests []otlpRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v1/logs", req.URL.Path)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+		bs, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		var body otlpRequest
+		require.NoError(t, json.Unmarshal(bs, &body))
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	sink, err := New(config.LogSinkConfig{
+		Type:     "otlp",
+		Endpoint: srv.URL + "/v1/logs",
+		Headers: map[string]string{
+			"Authorization": "Bearer secret",
+		},
+	})
+	require.NoError(t, err)
+
+	api := Resource{WorkspaceID: "ws", StackID: "stk", ComponentID: "api"}
+	worker := Resource{WorkspaceID: "ws", StackID: "stk", ComponentID: "worker"}
+	ts := time.Unix(1633089600, 5)
+	require.NoError(t, sink.Export(context.Background(), []Event{
+		{Resource: api, Timestamp: ts, Message: "one", Tags: map[string]string{"level": "WARNING", "path": "/"}},
+		{Resource: worker, Timestamp: ts, Message: "two"},
+		{Resource: api, Timestamp: ts, Message: "three", Type: "Message"},
+	}))
+
+	require.Len(t, requests, 1)
+	resourceLogs := requests[0].ResourceLogs
+	require.Len(t, resourceLogs, 2)
+
+	assert.Equal(t, []otlpKeyValue{
+		stringAttribute("service.name", "exo"),
+		stringAttribute("exo.workspace.id", "ws"),
+		stringAttribute("exo.stack.id", "stk"),
+		stringAttribute("exo.component.id", "api"),
+	}, resourceLogs[0].Resource.Attributes)
+	records := resourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, records, 2)
+	assert.Equal(t, "1633089600000000005", records[0].TimeUnixNano)
+	assert.Equal(t, "one", records[0].Body.StringValue)
+	assert.Equal(t, 13, records[0].SeverityNumber)
+	assert.Equal(t, "warn", records[0].SeverityText)
+	assert.Equal(t, []otlpKeyValue{
+		stringAttribute("level", "WARNING"),
+		stringAttribute("path", "/"),
+	}, records[0].Attributes)
+	assert.Equal(t, "three", records[1].Body.StringValue)
+	assert.Equal(t, 0, records[1].SeverityNumber)
+	assert.Equal(t, []otlpKeyValue{
+		stringAttribute("exo.event.type", "Message"),
+	}, records[1].Attributes)
+
+	assert.Equal(t, "exo.component.id", resourceLogs[1].Resource.Attributes[3].Key)
+	assert.Equal(t, "worker", resourceLogs[1].Resource.Attributes[3].Value.StringValue)
+	assert.Equal(t, "two", resourceLogs[1].ScopeLogs[0].LogRecords[0].Body.StringValue)
+}
+
+func TestOTLPSinkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sink := NewOTLPSink(srv.URL, nil)
+	err := sink.Export(context.Background(), []Event{{Message: "hello"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+	assert.Contains(t, err.Error(), "overloaded")
+}
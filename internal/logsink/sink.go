@@ -0,0 +1,130 @@
+// Package logsink exports log events to destinations outside of exo, such as
+// an OpenTelemetry collector or a rotating file.
+package logsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deref/exo/internal/config"
+)
+
+// Identifies the entities that an event was logged by. Empty IDs are unknown
+// or not applicable.
+type Resource struct {
+	WorkspaceID string
+	StackID     string
+	ComponentID string
+}
+
+type Event struct {
+	Resource  Resource
+	Timestamp time.Time
+	Type      string
+	Message   string
+	Tags      map[string]string
+}
+
+type Sink interface {
+	// Export is not called concurrently.
+	Export(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// Constructs the sink described by cfg. No connections are made and no
+// files are opened until events are exported.
+func New(cfg config.LogSinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "otlp":
+		return NewOTLPSink(cfg.Endpoint, cfg.Headers), nil
+	case "file":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file sink requires a path")
+		}
+		if !filepath.IsAbs(cfg.Path) {
+			return nil, fmt.Errorf("file sink path must be absolute: %q", cfg.Path)
+		}
+		if cfg.MaxBytes < 0 || cfg.MaxFiles < 0 {
+			return nil, fmt.Errorf("file sink limits must not be negative")
+		}
+		return &FileSink{
+			Path:     cfg.Path,
+			MaxBytes: cfg.MaxBytes,
+			MaxFiles: cfg.MaxFiles,
+		}, nil
+	case "stdout":
+		return &WriterSink{W: os.Stdout}, nil
+	case "":
+		return nil, fmt.Errorf("sink type is required")
+	default:
+		return nil, fmt.Errorf("unknown sink type: %q", cfg.Type)
+	}
+}
+
+// Constructs all sinks, or none of them if any configuration is invalid.
+func NewAll(cfgs []config.LogSinkConfig) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		sink, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("sink %d: %w", i+1, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// JSON-lines representation of an event, written by the file and stdout
+// sinks.
+type record struct {
+	Timestamp   string            `json:"timestamp"`
+	WorkspaceID string            `json:"workspaceId,omitempty"`
+	StackID     string            `json:"stackId,omitempty"`
+	ComponentID string            `json:"componentId,omitempty"`
+	Type        string            `json:"type,omitempty"`
+	Message     string            `json:"message"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+func marshalLine(event Event) ([]byte, error) {
+	bs, err := json.Marshal(record{
+		Timestamp:   event.Timestamp.UTC().Format(time.RFC3339Nano),
+		WorkspaceID: event.Resource.WorkspaceID,
+		StackID:     event.Resource.StackID,
+		ComponentID: event.Resource.ComponentID,
+		Type:        event.Type,
+		Message:     event.Message,
+		Tags:        event.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(bs, '\n'), nil
+}
+
+// Writes events as JSON lines, such as to the daemon's stdout.
+type WriterSink struct {
+	W io.Writer
+}
+
+func (sink *WriterSink) Export(ctx context.Context, events []Event) error {
+	for _, event := range events {
+		line, err := marshalLine(event)
+		if err != nil {
+			return fmt.Errorf("marshalling event: %w", err)
+		}
+		if _, err := sink.W.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sink *WriterSink) Close() error {
+	return nil
+}
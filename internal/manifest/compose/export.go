@@ -0,0 +1,256 @@
+package compose
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deref/exo/internal/providers/docker/compose"
+	"gopkg.in/yaml.v3"
+)
+
+// Exporter converts workspace components back in to a compose project. It
+// reverses the renaming performed by the Importer, so that services refer to
+// networks, volumes, and other services by key.
+type Exporter struct {
+	// ProjectName is the prefix of resource names generated by the importer.
+	ProjectName string
+}
+
+// Process is a unix process component. Compose has no equivalent, so
+// processes are preserved in an "x-exo" extension, which compose ignores.
+type Process struct {
+	Name        string            `yaml:"-"`
+	Directory   string            `yaml:"directory,omitempty"`
+	Program     string            `yaml:"program"`
+	Arguments   []string          `yaml:"arguments,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+}
+
+type exportedProject struct {
+	Services compose.ProjectServices `yaml:"services,omitempty"`
+	Networks compose.ProjectNetworks `yaml:"networks,omitempty"`
+	Volumes  compose.ProjectVolumes  `yaml:"volumes,omitempty"`
+	Configs  compose.ProjectConfigs  `yaml:"configs,omitempty"`
+	Secrets  compose.ProjectSecrets  `yaml:"secrets,omitempty"`
+	Exo      *exoExtension           `yaml:"x-exo,omitempty"`
+}
+
+type exoExtension struct {
+	Processes yaml.Node `yaml:"processes"`
+}
+
+// Export writes a compose file for the given project and processes. The keys
+// of the services, networks, and volumes of the project are their component
+// names. Replicas of a service are regrouped in to a single service, and the
+// sources of its secrets and configs are moved back to the top-level sections.
+// Services are expected to carry the profiles of their components.
+func (exp *Exporter) Export(w io.Writer, project *compose.Project, processes []Process) error {
+	services := exp.groupReplicas(project.Services)
+
+	networkNameToKey := make(map[string]string, len(project.Networks))
+	for _, network := range project.Networks {
+		if network.Name.Value != "" {
+			networkNameToKey[network.Name.Value] = network.Key
+		}
+	}
+	volumeNameToKey := make(map[string]string, len(project.Volumes))
+	for _, volume := range project.Volumes {
+		if volume.Name.Value != "" {
+			volumeNameToKey[volume.Name.Value] = volume.Key
+		}
+	}
+	// Links are imported as references to the default container name, even
+	// when a service has a custom container name. See NOTE [RESOLVING SERVICE
+	// CONTAINERS].
+	containerNameToKey := make(map[string]string, len(services)*2)
+	for _, service := range services {
+		containerNameToKey[exp.prefixedName(service.Key, "1")] = service.Key
+		if service.ContainerName.Value != "" {
+			containerNameToKey[service.ContainerName.Value] = service.Key
+		}
+	}
+
+	out := exportedProject{
+		Networks: project.Networks,
+		Volumes:  project.Volumes,
+	}
+	for _, service := range services {
+		if service.ContainerName.Value == exp.prefixedName(service.Key, "1") {
+			service.ContainerName = compose.String{}
+		}
+
+		// Docker Compose adds these labels itself, and they are rejected on import.
+		labels := service.Labels.Items[:0:0]
+		for _, item := range service.Labels.Items {
+			if !strings.HasPrefix(item.Key, "com.docker.compose") {
+				labels = append(labels, item)
+			}
+		}
+		service.Labels.Items = labels
+
+		networks := make([]compose.ServiceNetwork, len(service.Networks.Items))
+		for i, network := range service.Networks.Items {
+			if key, ok := networkNameToKey[network.Key]; ok {
+				network.Key = key
+				if network.ShortForm.Expression != "" {
+					network.ShortForm = compose.MakeString(key)
+				}
+			}
+			networks[i] = network
+		}
+		service.Networks.Items = networks
+
+		volumes := make([]compose.VolumeMount, len(service.Volumes))
+		for i, mount := range service.Volumes {
+			// The importer only renames the source of long form mounts.
+			key, ok := volumeNameToKey[mount.Source.Value]
+			if ok && mount.Type.Value == "volume" && mount.ShortForm.Expression == "" {
+				mount.Source = compose.MakeString(key)
+			}
+			volumes[i] = mount
+		}
+		service.Volumes = volumes
+
+		links := make(compose.Links, len(service.Links))
+		for i, link := range service.Links {
+			if key, ok := containerNameToKey[link.Service]; ok {
+				link.Service = key
+				link.String = compose.MakeString(fmt.Sprintf("%s:%s", key, link.Alias))
+			}
+			links[i] = link
+		}
+		service.Links = links
+
+		service.Secrets = exportFileReferences(service.Secrets, func(ref compose.FileReference) {
+			for _, secret := range out.Secrets {
+				if secret.Key == ref.Source.Value {
+					return
+				}
+			}
+			out.Secrets = append(out.Secrets, compose.Secret{
+				Key:         ref.Source.Value,
+				File:        ref.File,
+				Environment: ref.Environment,
+			})
+		})
+		service.Configs = exportFileReferences(service.Configs, func(ref compose.FileReference) {
+			for _, config := range out.Configs {
+				if config.Key == ref.Source.Value {
+					return
+				}
+			}
+			out.Configs = append(out.Configs, compose.Config{
+				Key:  ref.Source.Value,
+				File: ref.File,
+			})
+		})
+
+		out.Services = append(out.Services, service)
+	}
+
+	if len(processes) > 0 {
+		sort.Slice(processes, func(i, j int) bool {
+			return processes[i].Name < processes[j].Name
+		})
+		out.Exo = &exoExtension{
+			Processes: yaml.Node{Kind: yaml.MappingNode},
+		}
+		for _, proc := range processes {
+			var keyNode, valueNode yaml.Node
+			if err := keyNode.Encode(proc.Name); err != nil {
+				return err
+			}
+			if err := valueNode.Encode(proc); err != nil {
+				return err
+			}
+			out.Exo.Processes.Content = append(out.Exo.Processes.Content, &keyNode, &valueNode)
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// The importer expands a service with more than one replica in to components
+// named "<service>-<index>", each with a container name suffixed by its
+// index. Regroups these components in to one service with deploy.replicas.
+func (exp *Exporter) groupReplicas(services compose.ProjectServices) compose.ProjectServices {
+	replicaOf := func(service compose.Service) (name string, ok bool) {
+		sep := strings.LastIndexByte(service.Key, '-')
+		if sep < 0 {
+			return "", false
+		}
+		name, suffix := service.Key[:sep], service.Key[sep+1:]
+		if index, err := strconv.Atoi(suffix); err != nil || index < 1 {
+			return "", false
+		}
+		return name, service.ContainerName.Value == exp.prefixedName(name, suffix)
+	}
+
+	replicaCounts := make(map[string]int)
+	for _, service := range services {
+		if name, ok := replicaOf(service); ok {
+			replicaCounts[name]++
+		}
+	}
+
+	grouped := make(compose.ProjectServices, 0, len(services))
+	seen := make(map[string]bool)
+	for _, service := range services {
+		name, ok := replicaOf(service)
+		replicas := replicaCounts[name]
+		if !ok || replicas < 2 {
+			grouped = append(grouped, service)
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var deploy compose.Deploy
+		if service.Deploy != nil {
+			deploy = *service.Deploy
+		}
+		deploy.Replicas = compose.NewInt(int64(replicas))
+		service.Deploy = &deploy
+		service.Key = name
+		service.ContainerName = compose.String{}
+		grouped = append(grouped, service)
+	}
+	return grouped
+}
+
+// The importer copies the source of each secret and config in to the
+// references to it. See NOTE [RESOLVED FILE REFERENCES]. Reverses that copy,
+// passing each resolved reference to define, so that the source can be
+// exported to the top-level section.
+func exportFileReferences(refs []compose.FileReference, define func(compose.FileReference)) []compose.FileReference {
+	if len(refs) == 0 {
+		return refs
+	}
+	exported := make([]compose.FileReference, len(refs))
+	for i, ref := range refs {
+		if ref.File.Value != "" || ref.Environment.Value != "" {
+			define(ref)
+		}
+		ref.File = compose.String{}
+		ref.Environment = compose.String{}
+		if ref.Target.Value == "" && ref.UID.Value == "" && ref.GID.Value == "" && ref.Mode == nil {
+			ref.ShortForm = ref.Source
+		}
+		exported[i] = ref
+	}
+	return exported
+}
+
+func (exp *Exporter) prefixedName(name string, suffix string) string {
+	return (&Importer{ProjectName: exp.ProjectName}).prefixedName(name, suffix)
+}
@@ -0,0 +1,178 @@
+package compose_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/deref/exo/internal/manifest/compose"
+	dockercompose "github.com/deref/exo/internal/providers/docker/compose"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExport(t *testing.T) {
+	decode := func(s string, v any) {
+		if err := yaml.Unmarshal([]byte(s), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var web, db dockercompose.Service
+	decode(`
+image: nginx
+container_name: testproj_web_1
+labels:
+  com.docker.compose.project: testproj
+  com.docker.compose.service: web
+  team: frontend
+networks:
+  - testproj_default
+links:
+  - testproj_db_1:database
+volumes:
+  - type: volume
+    source: testproj_static
+    target: /srv
+`, &web)
+	web.Key = "web"
+	decode(`
+image: postgres
+container_name: custom-db
+networks:
+  - testproj_default
+`, &db)
+	db.Key = "db"
+
+	var network dockercompose.Network
+	decode(`
+name: testproj_default
+driver: bridge
+`, &network)
+	network.Key = "default"
+
+	var volume dockercompose.Volume
+	decode(`
+name: testproj_static
+`, &volume)
+	volume.Key = "static"
+
+	project := &dockercompose.Project{
+		Services: dockercompose.ProjectServices{web, db},
+		Networks: dockercompose.ProjectNetworks{network},
+		Volumes:  dockercompose.ProjectVolumes{volume},
+	}
+	processes := []compose.Process{
+		{
+			Name:        "worker",
+			Program:     "node",
+			Arguments:   []string{"worker.js"},
+			Environment: map[string]string{"PORT": "3000"},
+		},
+	}
+
+	exporter := &compose.Exporter{ProjectName: "testproj"}
+	var buf bytes.Buffer
+	if !assert.NoError(t, exporter.Export(&buf, project, processes)) {
+		return
+	}
+	assert.Equal(t, strings.TrimSpace(`
+services:
+  web:
+    image: nginx
+    labels:
+      team: frontend
+    links:
+      - db:database
+    networks:
+      - default
+    volumes:
+      - type: volume
+        source: static
+        target: /srv
+  db:
+    container_name: custom-db
+    image: postgres
+    networks:
+      - default
+networks:
+  default:
+    name: testproj_default
+    driver: bridge
+volumes:
+  static:
+    name: testproj_static
+x-exo:
+  processes:
+    worker:
+      program: node
+      arguments:
+        - worker.js
+      environment:
+        PORT: "3000"
+`), strings.TrimSpace(buf.String()))
+}
+
+func TestExportReplicasAndFileReferences(t *testing.T) {
+	decode := func(s string, v any) {
+		if err := yaml.Unmarshal([]byte(s), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var services dockercompose.ProjectServices
+	for i, name := range []string{"worker-1", "worker-2"} {
+		var service dockercompose.Service
+		decode(`
+image: worker
+container_name: testproj_worker_`+strconv.Itoa(i+1)+`
+profiles:
+  - jobs
+secrets:
+  - source: token
+    file: ./token.txt
+configs:
+  - source: settings
+    target: /etc/settings.json
+    file: ./settings.json
+`, &service)
+		service.Key = name
+		services = append(services, service)
+	}
+	var api dockercompose.Service
+	decode(`
+image: api
+container_name: testproj_api-1_1
+`, &api)
+	api.Key = "api-1"
+	services = append(services, api)
+
+	exporter := &compose.Exporter{ProjectName: "testproj"}
+	var buf bytes.Buffer
+	if !assert.NoError(t, exporter.Export(&buf, &dockercompose.Project{Services: services}, nil)) {
+		return
+	}
+	assert.Equal(t, strings.TrimSpace(`
+services:
+  worker:
+    configs:
+      - source: settings
+        target: /etc/settings.json
+    image: worker
+    profiles:
+      - jobs
+    secrets:
+      - token
+    deploy:
+      replicas: 2
+  api-1:
+    image: api
+configs:
+  settings:
+    file: ./settings.json
+secrets:
+  token:
+    file: ./token.txt
+`), strings.TrimSpace(buf.String()))
+}
@@ -22,8 +22,6 @@ type Importer struct {
 }
 
 func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
-	b := exohcl.NewBuilder(bs)
-
 	project, err := compose.Parse(bytes.NewBuffer(bs))
 	if err != nil {
 		// TODO: Preserve location information from yaml parse errors.
@@ -31,8 +29,15 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 			Severity: hcl.DiagError,
 			Summary:  err.Error(),
 		})
-		return b.Build()
+		return exohcl.NewBuilder(bs).Build()
 	}
+	return imp.ImportProject(ctx, bs, project)
+}
+
+// Converts a project that was parsed from, or translated from, src. See
+// exohcl.NewBuilder.
+func (imp *Importer) ImportProject(ctx *exohcl.AnalysisContext, src []byte, project *compose.Project) *hcl.File {
+	b := exohcl.NewBuilder(src)
 
 	// TODO: Avoid mutating project during conversion.
 
@@ -42,6 +47,25 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 	networkKeyToName := map[string]string{}
 	volumeKeyToName := map[string]string{}
 
+	// Services reference secrets and configs by key, but the container
+	// components need the source of the files. See NOTE [RESOLVED FILE
+	// REFERENCES].
+	secretSources := make(map[string]fileSource, len(project.Secrets))
+	for _, secret := range project.Secrets {
+		secretSources[secret.Key] = fileSource{
+			File:        secret.File,
+			Environment: secret.Environment,
+			External:    secret.External.Value,
+		}
+	}
+	configSources := make(map[string]fileSource, len(project.Configs))
+	for _, config := range project.Configs {
+		configSources[config.Key] = fileSource{
+			File:     config.File,
+			External: config.External.Value,
+		}
+	}
+
 	for _, volume := range project.Volumes {
 		name := exohcl.MangleName(volume.Key)
 		if volume.Key != name {
@@ -54,7 +78,7 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 		}
 		volumeKeyToName[volume.Key] = volume.Name.Value
 
-		b.AddComponentBlock(makeComponentBlock("volume", name, volume, nil))
+		b.AddComponentBlock(makeComponentBlock("volume", name, volume, nil, nil))
 	}
 
 	// Set up networks.
@@ -81,7 +105,7 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 			network.Driver = compose.MakeString("bridge")
 		}
 
-		b.AddComponentBlock(makeComponentBlock("network", name, network, nil))
+		b.AddComponentBlock(makeComponentBlock("network", name, network, nil, nil))
 	}
 	// TODO: Docker Compose only creates the default network if there is at least 1 service that does not
 	// specify a network. We should do the same.
@@ -93,7 +117,30 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 		b.AddComponentBlock(makeComponentBlock("network", key, map[string]string{
 			"name":   name,
 			"driver": "bridge",
-		}, nil))
+		}, nil, nil))
+	}
+
+	// Replicated services are expanded to one component per container, so
+	// dependencies on them are expanded to each of the replica components.
+	serviceComponents := make(map[string][]string, len(project.Services))
+	for _, service := range project.Services {
+		name := exohcl.MangleName(service.Key)
+		replicas := serviceReplicas(service)
+		if replicas == 1 {
+			serviceComponents[name] = []string{name}
+			continue
+		}
+		components := make([]string, replicas)
+		for i := range components {
+			components[i] = replicaName(name, i+1)
+		}
+		serviceComponents[name] = components
+	}
+	dependencyComponents := func(name string) []string {
+		if components, ok := serviceComponents[name]; ok {
+			return components
+		}
+		return []string{name}
 	}
 
 	for _, service := range project.Services {
@@ -104,10 +151,22 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 		}
 		var dependsOn []string
 
+		replicas := serviceReplicas(service)
+		if replicas > 1 && service.ContainerName.Value != "" {
+			ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("service %q may not specify both container_name and more than one replica", service.Key),
+			})
+			continue
+		}
+		if service.Deploy != nil {
+			// Replicas are expanded into separate components below.
+			service.Deploy.Replicas = nil
+		}
+
 		if service.ContainerName.Value == "" {
 			// The generated container name intentionally matches the container name generated by Docker Compose
-			// when the scale is set at 1. When we address scaling containers, this will need to be updated to
-			// use a different suffix for each container.
+			// for the first replica. Subsequent replicas are suffixed by their index below.
 			service.ContainerName = compose.MakeString(imp.prefixedName(service.Key, "1"))
 		}
 
@@ -185,7 +244,7 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 					subject,
 				))
 			}
-			dependsOn = append(dependsOn, exohcl.MangleName(dependency.Service.Value))
+			dependsOn = append(dependsOn, dependencyComponents(exohcl.MangleName(dependency.Service.Value))...)
 		}
 
 		for idx, link := range service.Links {
@@ -224,15 +283,142 @@ func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
 				Service: containerName,
 				Alias:   linkAlias,
 			}
-			dependsOn = append(dependsOn, mangledServiceName)
+			dependsOn = append(dependsOn, dependencyComponents(mangledServiceName)...)
 		}
 
-		b.AddComponentBlock(makeComponentBlock("container", name, service, dependsOn))
+		service.Secrets = resolveFileReferences(ctx, src, "secret", service.Key, service.Secrets, secretSources)
+		service.Configs = resolveFileReferences(ctx, src, "config", service.Key, service.Configs, configSources)
+
+		// Profiles determine whether the container is started, which is decided
+		// when the manifest is applied, so they are hoisted out of the spec.
+		profiles := service.Profiles.Values()
+		service.Profiles = nil
+
+		if replicas == 1 {
+			b.AddComponentBlock(makeComponentBlock("container", name, service, dependsOn, profiles))
+			continue
+		}
+		for i := 1; i <= replicas; i++ {
+			service.ContainerName = compose.MakeString(imp.prefixedName(service.Key, strconv.Itoa(i)))
+			b.AddComponentBlock(makeComponentBlock("container", replicaName(name, i), service, dependsOn, profiles))
+		}
 	}
 
 	return b.Build()
 }
 
+type fileSource struct {
+	File        compose.String
+	Environment compose.String
+	External    bool
+}
+
+func resolveFileReferences(ctx *exohcl.AnalysisContext, src []byte, kind string, serviceKey string, refs []compose.FileReference, sources map[string]fileSource) []compose.FileReference {
+	var resolved []compose.FileReference
+	for i, ref := range refs {
+		source, ok := sources[ref.Source.Value]
+		if !ok {
+			ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("service %q references unknown %s: %q", serviceKey, kind, ref.Source.Value),
+			})
+			continue
+		}
+		if source.External {
+			subject := yamlRange(src, "services", serviceKey, kind+"s", i)
+			ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning(
+				fmt.Sprintf("external %s", kind),
+				fmt.Sprintf("External %ss are only available to Docker Swarm services; %q will not be mounted.", kind, ref.Source.Value),
+				subject,
+			))
+			continue
+		}
+		// Always emit the long form, since the short form cannot carry the source.
+		ref.ShortForm = compose.String{}
+		ref.File = source.File
+		ref.Environment = source.Environment
+		resolved = append(resolved, ref)
+	}
+	return resolved
+}
+
+// Locates the YAML node at path in src, where path elements are mapping keys
+// or sequence indexes. Returns nil if src does not contain the path, as when
+// the project was translated from another format.
+func yamlRange(src []byte, path ...any) *hcl.Range {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	node := doc.Content[0]
+	for _, elem := range path {
+		var next *yaml.Node
+		switch elem := elem.(type) {
+		case string:
+			if node.Kind != yaml.MappingNode {
+				return nil
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == elem {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && elem < len(node.Content) {
+				next = node.Content[elem]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	start := hcl.Pos{
+		Line:   node.Line,
+		Column: node.Column,
+		Byte:   yamlOffset(src, node.Line, node.Column),
+	}
+	end := start
+	if node.Kind == yaml.ScalarNode {
+		end.Column += len(node.Value)
+		end.Byte += len(node.Value)
+	}
+	return &hcl.Range{
+		Start: start,
+		End:   end,
+	}
+}
+
+// Converts a one-based line and column to a byte offset in src.
+func yamlOffset(src []byte, line, column int) int {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return len(src)
+		}
+		offset += i + 1
+	}
+	return offset + column - 1
+}
+
+// Returns the number of containers to create for a service. Defaults to 1.
+func serviceReplicas(service compose.Service) int {
+	if service.Deploy == nil || service.Deploy.Replicas == nil {
+		return 1
+	}
+	replicas := service.Deploy.Replicas.Int()
+	if replicas < 0 {
+		return 0
+	}
+	return replicas
+}
+
+func replicaName(name string, index int) string {
+	return fmt.Sprintf("%s-%d", name, index)
+}
+
 func (imp *Importer) prefixedName(name string, suffix string) string {
 	var out strings.Builder
 	out.WriteString(imp.ProjectName)
@@ -246,7 +432,7 @@ func (imp *Importer) prefixedName(name string, suffix string) string {
 	return out.String()
 }
 
-func makeComponentBlock(typ string, name string, spec any, dependsOn []string) *hclgen.Block {
+func makeComponentBlock(typ string, name string, spec any, dependsOn []string, profiles []string) *hclgen.Block {
 	obj := yamlToHCL(spec).(*hclsyntax.ObjectConsExpr)
 	attrs := make([]*hclsyntax.Attribute, len(obj.Items))
 	for i, item := range obj.Items {
@@ -264,17 +450,25 @@ func makeComponentBlock(typ string, name string, spec any, dependsOn []string) *
 			SrcRange:  hcl.RangeBetween(key.Range(), val.Range()),
 		}
 	}
-	var blocks []*hclgen.Block
+	var meta []*hclsyntax.Attribute
 	if len(dependsOn) > 0 {
+		meta = append(meta, &hclsyntax.Attribute{
+			Name: "depends_on",
+			Expr: yamlToHCL(dependsOn),
+		})
+	}
+	if len(profiles) > 0 {
+		meta = append(meta, &hclsyntax.Attribute{
+			Name: "profiles",
+			Expr: yamlToHCL(profiles),
+		})
+	}
+	var blocks []*hclgen.Block
+	if len(meta) > 0 {
 		blocks = append(blocks, &hclgen.Block{
 			Type: "_",
 			Body: &hclgen.Body{
-				Attributes: []*hclsyntax.Attribute{
-					{
-						Name: "depends_on",
-						Expr: yamlToHCL(dependsOn),
-					},
-				},
+				Attributes: meta,
 			},
 		})
 	}
@@ -365,11 +559,17 @@ func yamlToHCL(v any) hclsyntax.Expression {
 			case "", "!!str":
 				return yamlToHCL(v.Value)
 			case "!!int":
-				i, err := strconv.ParseInt(v.Value, 10, 64)
+				i, err := parseYAMLInt(v.Value)
 				if err != nil {
 					return yamlToHCL(v.Value)
 				}
 				return yamlToHCL(i)
+			case "!!float":
+				f, err := strconv.ParseFloat(v.Value, 64)
+				if err != nil {
+					return yamlToHCL(v.Value)
+				}
+				return yamlToHCL(f)
 			case "!!bool":
 				return yamlToHCL(v.Value == "true")
 			default:
@@ -453,6 +653,15 @@ func yamlToHCL(v any) hclsyntax.Expression {
 	}
 }
 
+// Octal literals, such as file modes, keep their meaning. Other integers are
+// decimal, even with a leading zero, as in "08".
+func parseYAMLInt(s string) (int64, error) {
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return i, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
 func yamlToHCLKey(v any) hclsyntax.Expression {
 	x := yamlToHCL(v)
 	if template, isTemplate := x.(*hclsyntax.TemplateExpr); isTemplate && len(template.Parts) == 1 {
@@ -0,0 +1,24 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseYAMLInt(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"8":    8,
+		"08":   8,
+		"0400": 0400,
+		"0o17": 017,
+		"-12":  -12,
+	} {
+		i, err := parseYAMLInt(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, i, s)
+		}
+	}
+	_, err := parseYAMLInt("eight")
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package compose_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/deref/exo/internal/manifest/compose"
@@ -135,3 +136,188 @@ components {
 		})
 	}
 }
+
+func TestImportProfiles(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	importer := &compose.Importer{ProjectName: "testproj"}
+	f := importer.Import(ctx, []byte(`
+services:
+  web:
+    image: nginx
+  debug:
+    image: busybox
+    profiles: [debug, tools]
+`))
+	m := exohcl.NewManifest("", f)
+	m.Analyze(ctx)
+	cs := exohcl.NewComponentSet(m)
+	cs.Analyze(ctx)
+	if ctx.Diagnostics.HasErrors() {
+		t.Fatal(ctx.Diagnostics)
+	}
+
+	profiles := make(map[string][]string)
+	for _, component := range cs.Components {
+		profiles[component.Name] = component.Profiles
+		if strings.Contains(component.Spec, "profiles") {
+			t.Errorf("expected profiles to be omitted from spec of %q, got:\n%s", component.Name, component.Spec)
+		}
+	}
+	if len(profiles["web"]) != 0 {
+		t.Errorf("expected web to have no profiles, got %v", profiles["web"])
+	}
+	if got := strings.Join(profiles["debug"], ","); got != "debug,tools" {
+		t.Errorf("expected debug to have profiles debug,tools, got %v", profiles["debug"])
+	}
+}
+
+func TestImportReplicas(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	importer := &compose.Importer{ProjectName: "testproj"}
+	f := importer.Import(ctx, []byte(`
+services:
+  web:
+    image: nginx
+    deploy:
+      replicas: 2
+      resources:
+        limits:
+          cpus: 0.5
+  proxy:
+    image: haproxy
+    depends_on: [web]
+  disabled:
+    image: busybox
+    deploy:
+      replicas: 0
+`))
+	if ctx.Diagnostics.HasErrors() {
+		t.Fatal(ctx.Diagnostics)
+	}
+	if got := string(hclgen.FormatFile(hclgen.FileFromStructure(f))); !strings.Contains(got, `depends_on = ["default", "web-1", "web-2"]`) {
+		t.Errorf("expected proxy to depend on each web replica, got:\n%s", got)
+	}
+
+	m := exohcl.NewManifest("", f)
+	m.Analyze(ctx)
+	cs := exohcl.NewComponentSet(m)
+	cs.Analyze(ctx)
+	if ctx.Diagnostics.HasErrors() {
+		t.Fatal(ctx.Diagnostics)
+	}
+
+	specs := make(map[string]string)
+	for _, component := range cs.Components {
+		specs[component.Name] = component.Spec
+	}
+	if _, ok := specs["disabled"]; ok {
+		t.Errorf("expected no component for service with zero replicas")
+	}
+	if _, ok := specs["web"]; ok {
+		t.Errorf("expected replicated service to be expanded")
+	}
+	for _, replica := range []string{"1", "2"} {
+		spec, ok := specs["web-"+replica]
+		if !ok {
+			t.Errorf("expected component for web replica %s", replica)
+			continue
+		}
+		if !strings.Contains(spec, `"container_name": "testproj_web_`+replica+`"`) {
+			t.Errorf("expected indexed container name for web replica %s, got:\n%s", replica, spec)
+		}
+		if strings.Contains(spec, "replicas") {
+			t.Errorf("expected replicas to be omitted from spec of web replica %s, got:\n%s", replica, spec)
+		}
+	}
+}
+
+func TestImportReplicasContainerName(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	importer := &compose.Importer{ProjectName: "testproj"}
+	importer.Import(ctx, []byte(`
+services:
+  web:
+    image: nginx
+    container_name: web
+    deploy:
+      replicas: 2
+`))
+	if !ctx.Diagnostics.HasErrors() {
+		t.Errorf("expected error for replicated service with explicit container_name")
+	}
+}
+
+func TestImportSecretsAndConfigs(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	importer := &compose.Importer{ProjectName: "testproj"}
+	f := importer.Import(ctx, []byte(`
+services:
+  web:
+    image: nginx
+    secrets:
+      - db_password
+      - source: api_token
+        target: token
+        mode: 0400
+      - swarm_secret
+    configs:
+      - source: nginx_config
+        target: /etc/nginx/nginx.conf
+secrets:
+  db_password:
+    file: ./db_password.txt
+  api_token:
+    environment: API_TOKEN
+  swarm_secret:
+    external: true
+configs:
+  nginx_config:
+    file: ./nginx.conf
+`))
+	if ctx.Diagnostics.HasErrors() {
+		t.Fatal(ctx.Diagnostics)
+	}
+	if len(ctx.Diagnostics) != 1 || !strings.Contains(ctx.Diagnostics[0].Summary, "external secret") {
+		t.Errorf("expected external secret warning, got: %v", ctx.Diagnostics)
+	} else if subject := ctx.Diagnostics[0].Subject; subject == nil || subject.Start.Line != 10 || subject.Start.Column != 9 {
+		t.Errorf("expected external secret warning at line 10, column 9, got: %v", subject)
+	}
+
+	got := string(hclgen.FormatFile(hclgen.FileFromStructure(f)))
+	for _, expected := range []string{
+		`{ source = "db_password", file = "./db_password.txt" }`,
+		`{ source = "api_token", target = "token", mode = 256, environment = "API_TOKEN" }`,
+		`{ source = "nginx_config", target = "/etc/nginx/nginx.conf", file = "./nginx.conf" }`,
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("expected resolved reference %s, got:\n%s", expected, got)
+		}
+	}
+	if strings.Contains(got, "swarm_secret") {
+		t.Errorf("expected external secret to be omitted, got:\n%s", got)
+	}
+}
+
+func TestImportUnknownSecret(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	importer := &compose.Importer{ProjectName: "testproj"}
+	importer.Import(ctx, []byte(`
+services:
+  web:
+    image: nginx
+    secrets: [missing]
+`))
+	if !ctx.Diagnostics.HasErrors() {
+		t.Errorf("expected error for unknown secret")
+	}
+}
@@ -0,0 +1,197 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/deref/exo/internal/providers/docker/compose"
+	"gopkg.in/yaml.v3"
+)
+
+// Loader reads compose projects that span multiple files, either through
+// override files or through services that extend services in other files.
+type Loader struct {
+	// ReadFile reads the named file. Relative names are relative to the
+	// working directory of the loader.
+	ReadFile func(name string) ([]byte, error)
+}
+
+// Returns the conventional override file for a compose file. For example,
+// "docker-compose.override.yml" for "docker-compose.yml".
+func OverrideFilename(filename string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + ".override" + ext
+}
+
+// Load reads the given compose files, resolves extends in each file, and then
+// merges each file in to the files before it. The result is a single compose
+// document. A single file without any extended services is returned as is.
+func (l *Loader) Load(filenames ...string) ([]byte, error) {
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no compose files given")
+	}
+	var merged *yaml.Node
+	changed := len(filenames) > 1
+	var original []byte
+	for _, filename := range filenames {
+		bs, err := l.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filename, err)
+		}
+		original = bs
+		doc, err := l.loadFile(filename, bs)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", filename, err)
+		}
+		if doc.extended {
+			changed = true
+		}
+		if merged == nil {
+			merged = doc.node
+			continue
+		}
+		merged, err = compose.Merge(merged, doc.node)
+		if err != nil {
+			return nil, fmt.Errorf("merging %s: %w", filename, err)
+		}
+	}
+	if !changed {
+		return original, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(merged); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type loadedFile struct {
+	node *yaml.Node
+	// True if any service extended another.
+	extended bool
+}
+
+func (l *Loader) loadFile(filename string, bs []byte) (*loadedFile, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, err
+	}
+	res := &loadedFile{
+		node: compose.Normalize(&doc),
+	}
+	if res.node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected !!map, got %s", res.node.ShortTag())
+	}
+	services := mappingValue(res.node, "services")
+	if services == nil {
+		return res, nil
+	}
+	// Resolve every service before replacing any, so that services extended by
+	// multiple others are not merged more than once.
+	resolved := make([]*yaml.Node, len(services.Content)/2)
+	for i := range resolved {
+		key := services.Content[i*2].Value
+		var err error
+		resolved[i], err = l.resolveExtends(filename, res.node, key, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, service := range resolved {
+		if service != services.Content[i*2+1] {
+			res.extended = true
+			services.Content[i*2+1] = service
+		}
+	}
+	return res, nil
+}
+
+// Returns the definition of the given service with any extended services
+// merged in to it. The seen slice contains "file:service" pairs being resolved,
+// for detecting cycles.
+func (l *Loader) resolveExtends(filename string, project *yaml.Node, key string, seen []string) (*yaml.Node, error) {
+	id := filename + ":" + key
+	for _, other := range seen {
+		if other == id {
+			return nil, fmt.Errorf("cyclic extends: %s", strings.Join(append(seen, id), " -> "))
+		}
+	}
+	seen = append(seen, id)
+
+	service := mappingValue(mappingValue(project, "services"), key)
+	if service == nil {
+		return nil, fmt.Errorf("service %q not found in %s", key, filename)
+	}
+	extends := mappingValue(service, "extends")
+	if extends == nil {
+		return service, nil
+	}
+
+	var baseFilename, baseKey string
+	switch extends.Kind {
+	case yaml.ScalarNode:
+		baseKey = extends.Value
+	case yaml.MappingNode:
+		if file := mappingValue(extends, "file"); file != nil {
+			baseFilename = file.Value
+		}
+		if svc := mappingValue(extends, "service"); svc != nil {
+			baseKey = svc.Value
+		}
+	}
+	if baseKey == "" {
+		return nil, fmt.Errorf("service %q: extends must specify a service", key)
+	}
+
+	var base *yaml.Node
+	if baseFilename == "" {
+		var err error
+		base, err = l.resolveExtends(filename, project, baseKey, seen)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if !filepath.IsAbs(baseFilename) {
+			baseFilename = filepath.Join(filepath.Dir(filename), baseFilename)
+		}
+		bs, err := l.ReadFile(baseFilename)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: reading extended file: %w", key, err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(bs, &doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", baseFilename, err)
+		}
+		// TODO: Relative paths in the extended service, such as a build context,
+		// should be rebased to the directory of the extending file.
+		base, err = l.resolveExtends(baseFilename, compose.Normalize(&doc), baseKey, seen)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	override := *service
+	override.Content = nil
+	for i := 0; i+1 < len(service.Content); i += 2 {
+		if service.Content[i].Value != "extends" {
+			override.Content = append(override.Content, service.Content[i], service.Content[i+1])
+		}
+	}
+	return compose.MergeService(base, &override)
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
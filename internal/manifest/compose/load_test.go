@@ -0,0 +1,106 @@
+package compose_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/deref/exo/internal/manifest/compose"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLoader(files map[string]string) *compose.Loader {
+	return &compose.Loader{
+		ReadFile: func(name string) ([]byte, error) {
+			content, ok := files[name]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return []byte(content), nil
+		},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	loader := newTestLoader(map[string]string{
+		"docker-compose.yml": `
+services:
+  web:
+    extends: base
+    image: nginx
+  base:
+    restart: always
+    environment:
+      A: "1"
+  worker:
+    extends:
+      file: common/services.yml
+      service: worker
+    command: work --fast
+`,
+		"docker-compose.override.yml": `
+services:
+  web:
+    environment:
+      B: "2"
+`,
+		"common/services.yml": `
+services:
+  worker:
+    image: worker
+    command: work
+`,
+	})
+	bs, err := loader.Load("docker-compose.yml", "docker-compose.override.yml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, strings.TrimSpace(`
+services:
+  web:
+    restart: always
+    environment:
+      A: "1"
+      B: "2"
+    image: nginx
+  base:
+    restart: always
+    environment:
+      A: "1"
+  worker:
+    image: worker
+    command: work --fast
+`), strings.TrimSpace(string(bs)))
+}
+
+func TestLoadUnchanged(t *testing.T) {
+	content := "services:\n  web:\n    image: nginx # comment\n"
+	loader := newTestLoader(map[string]string{
+		"compose.yaml": content,
+	})
+	bs, err := loader.Load("compose.yaml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, content, string(bs))
+	}
+}
+
+func TestLoadCyclicExtends(t *testing.T) {
+	loader := newTestLoader(map[string]string{
+		"compose.yaml": `
+services:
+  a:
+    extends: b
+  b:
+    extends: a
+`,
+	})
+	_, err := loader.Load("compose.yaml")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cyclic extends")
+	}
+}
+
+func TestOverrideFilename(t *testing.T) {
+	assert.Equal(t, "docker-compose.override.yml", compose.OverrideFilename("docker-compose.yml"))
+	assert.Equal(t, "dir/compose.override.yaml", compose.OverrideFilename("dir/compose.yaml"))
+}
@@ -0,0 +1,191 @@
+package exocue
+
+import (
+	_ "embed"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/deref/exo/internal/manifest/exohcl/hclgen"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+//go:embed manifest.cue
+var manifestSchema []byte
+
+const schemaFilename = "exo/manifest.cue"
+
+// Importer evaluates an exo.cue manifest against #Manifest. Components are
+// converted to generic component blocks, with specs encoded as JSON.
+type Importer struct {
+	Filename string
+}
+
+func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
+	b := exohcl.NewBuilder(bs)
+
+	filename := imp.Filename
+	if filename == "" {
+		filename = "exo.cue"
+	}
+	manifest, err := evalManifest(filename, bs)
+	if err != nil {
+		ctx.AppendDiags(errorDiagnostics(err)...)
+		return b.Build()
+	}
+
+	iter, err := manifest.LookupPath(cue.ParsePath("components")).Fields()
+	if err != nil {
+		ctx.AppendDiags(errorDiagnostics(err)...)
+		return b.Build()
+	}
+	for iter.Next() {
+		name := iter.Label()
+		component := iter.Value()
+		subject := rangeFromPos(component.Pos())
+
+		if mangled := exohcl.MangleName(name); mangled != name {
+			ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("invalid component name: %q", name),
+				Detail:   fmt.Sprintf("Component names may only contain letters, digits, and dashes. Consider %q.", mangled),
+				Subject:  subject,
+			})
+			continue
+		}
+
+		typ, err := component.LookupPath(cue.ParsePath("type")).String()
+		if err != nil {
+			ctx.AppendDiags(errorDiagnostics(err)...)
+			continue
+		}
+		spec, err := component.LookupPath(cue.ParsePath("spec")).MarshalJSON()
+		if err != nil {
+			ctx.AppendDiags(errorDiagnostics(err)...)
+			continue
+		}
+
+		// TODO: Support component-level environment and run = false.
+		if run, err := component.LookupPath(cue.ParsePath("run")).Bool(); err == nil && !run {
+			ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning("run = false", fmt.Sprintf("Component %q will be run.", name), subject))
+		}
+		if env, err := component.LookupPath(cue.ParsePath("environment")).Fields(); err == nil && env.Next() {
+			ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning("component environment", fmt.Sprintf("The environment of component %q is ignored.", name), subject))
+		}
+
+		var rng hcl.Range
+		if subject != nil {
+			rng = *subject
+		}
+		b.AddComponentBlock(&hclgen.Block{
+			Type:   "component",
+			Labels: []string{name},
+			Body: &hclgen.Body{
+				Attributes: []*hclsyntax.Attribute{
+					{
+						Name:     "type",
+						Expr:     hclgen.NewStringLiteral(typ, rng),
+						SrcRange: rng,
+					},
+					{
+						Name:     "spec",
+						Expr:     hclgen.NewStringLiteral(string(spec), rng),
+						SrcRange: rng,
+					},
+				},
+			},
+		})
+	}
+
+	return b.Build()
+}
+
+// Unifies the manifest source with #Manifest and validates that the result is
+// concrete.
+func evalManifest(filename string, bs []byte) (cue.Value, error) {
+	inst := build.NewContext().NewInstance("", nil)
+	schemaFile, err := parser.ParseFile(schemaFilename, manifestSchema)
+	if err != nil {
+		panic(fmt.Errorf("parsing manifest schema: %w", err))
+	}
+	if err := inst.AddSyntax(schemaFile); err != nil {
+		panic(fmt.Errorf("adding manifest schema: %w", err))
+	}
+	file, err := parser.ParseFile(filename, bs, parser.ParseComments)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	if err := inst.AddSyntax(file); err != nil {
+		return cue.Value{}, err
+	}
+
+	// The root is not unified directly, since the schema deliberately defines
+	// some values as bottom, such as unsupported secrets. Only the regular
+	// fields of the manifest are unified with #Manifest.
+	root := cuecontext.New().BuildInstance(inst)
+	manifest := root.LookupPath(cue.ParsePath("#Manifest"))
+	iter, err := root.Fields()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	for iter.Next() {
+		manifest = manifest.FillPath(cue.MakePath(iter.Selector()), iter.Value())
+	}
+	if err := manifest.Validate(cue.Concrete(true)); err != nil {
+		return cue.Value{}, err
+	}
+	return manifest, nil
+}
+
+func errorDiagnostics(err error) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, e := range cueerrors.Errors(err) {
+		format, args := e.Msg()
+		diag := &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf(format, args...),
+		}
+		if path := e.Path(); len(path) > 0 {
+			diag.Detail = fmt.Sprintf("At %s.", cue.MakePath(selectors(path)...))
+		}
+		// Prefer positions in the manifest over positions in the schema.
+		for _, pos := range cueerrors.Positions(e) {
+			if pos.Filename() != schemaFilename {
+				diag.Subject = rangeFromPos(pos)
+				break
+			}
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+func selectors(path []string) []cue.Selector {
+	sels := make([]cue.Selector, len(path))
+	for i, label := range path {
+		sels[i] = cue.Str(label)
+	}
+	return sels
+}
+
+func rangeFromPos(pos token.Pos) *hcl.Range {
+	if !pos.IsValid() {
+		return nil
+	}
+	start := hcl.Pos{
+		Line:   pos.Line(),
+		Column: pos.Column(),
+		Byte:   pos.Offset(),
+	}
+	return &hcl.Range{
+		Filename: pos.Filename(),
+		Start:    start,
+		End:      start,
+	}
+}
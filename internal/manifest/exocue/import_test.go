@@ -0,0 +1,69 @@
+package exocue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	imp := &Importer{Filename: "exo.cue"}
+	f := imp.Import(ctx, []byte(`
+_port: 4000
+
+components: {
+	for name in ["api", "worker"] {
+		"\(name)": {
+			type: "process"
+			spec: {
+				program: "./\(name)"
+				environment: PORT: "\(_port)"
+			}
+		}
+	}
+}
+`))
+	if !assert.False(t, ctx.Diagnostics.HasErrors(), "%v", ctx.Diagnostics) {
+		return
+	}
+
+	m := &exohcl.Manifest{File: f}
+	m.Analyze(ctx)
+	components := exohcl.NewComponentSet(m)
+	components.Analyze(ctx)
+	if !assert.False(t, ctx.Diagnostics.HasErrors(), "%v", ctx.Diagnostics) {
+		return
+	}
+	if assert.Len(t, components.Components, 2) {
+		api := components.Components[0]
+		assert.Equal(t, "api", api.Name)
+		assert.Equal(t, "process", api.Type)
+		assert.JSONEq(t, `{"program":"./api","environment":{"PORT":"4000"}}`, api.Spec)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	imp := &Importer{Filename: "exo.cue"}
+	imp.Import(ctx, []byte(`components: {
+	web: {
+		type: 5
+		spec: {}
+	}
+}
+`))
+	if assert.True(t, ctx.Diagnostics.HasErrors()) {
+		diag := ctx.Diagnostics[0]
+		if assert.NotNil(t, diag.Subject) {
+			assert.Equal(t, "exo.cue", diag.Subject.Filename)
+			assert.Equal(t, 3, diag.Subject.Start.Line)
+		}
+	}
+}
@@ -3,7 +3,7 @@
     format: "exo.deref.io/cue-v1"
   }
 
-  environment: #EnvironmentExpression
+  environment: #EnvironmentExpression | *{}
   components: #ComponentsByName
 }
 
@@ -20,7 +20,7 @@
   name: string
   spec: #Model
   run: bool | *true
-  environment: #Environment
+  environment: #EnvironmentExpression | *{}
 }
 
 #Model: { [string]: _ }
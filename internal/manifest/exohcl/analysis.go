@@ -9,15 +9,14 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
-	ctyyaml "github.com/zclconf/go-cty-yaml"
 	"github.com/zclconf/go-cty/cty"
-	"github.com/zclconf/go-cty/cty/function"
-	"github.com/zclconf/go-cty/cty/function/stdlib"
 )
 
 type AnalysisContext struct {
 	context.Context
 	Diagnostics hcl.Diagnostics
+	// Values of manifest variables, overriding their defaults.
+	Variables map[string]string
 }
 
 func (ctx *AnalysisContext) AppendDiags(diags ...*hcl.Diagnostic) {
@@ -75,16 +74,25 @@ func NewUnsupportedFeatureWarning(featureName, explanation string, subject *hcl.
 	}
 }
 
-var evalCtx = &hcl.EvalContext{
-	Functions: map[string]function.Function{
-		"jsonencode": stdlib.JSONEncodeFunc,
-		"yamlencode": ctyyaml.YAMLEncodeFunc,
-	},
-}
-
-func AnalyzeString(ctx *AnalysisContext, x hcl.Expression) (s string, ok bool) {
+// Evaluates x as a string. If evalCtx is nil, only functions are available.
+func AnalyzeString(ctx *AnalysisContext, evalCtx *hcl.EvalContext, x hcl.Expression) (s string, ok bool) {
+	if evalCtx == nil {
+		evalCtx = defaultEvalContext
+	}
 	v, diags := x.Value(evalCtx)
 	ctx.AppendDiags(diags...)
+	if diags.HasErrors() {
+		return "", false
+	}
+	if !v.IsWhollyKnown() {
+		ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unknown value",
+			Detail:   "The value depends on values that could not be determined.",
+			Subject:  x.Range().Ptr(),
+		})
+		return "", false
+	}
 	if v.Type() != cty.String {
 		ctx.AppendDiags(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -6,11 +6,14 @@ import (
 	"github.com/deref/exo/internal/manifest/exohcl/hclgen"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type ComponentSet struct {
 	// Analysis inputs.
-	Blocks hcl.Blocks
+	Blocks      hcl.Blocks
+	EvalContext *hcl.EvalContext
 
 	// Analysis outputs.
 	Components []*Component
@@ -18,7 +21,8 @@ type ComponentSet struct {
 
 func NewComponentSet(m *Manifest) *ComponentSet {
 	return &ComponentSet{
-		Blocks: m.Components,
+		Blocks:      m.Components,
+		EvalContext: m.EvalContext,
 	}
 }
 
@@ -60,21 +64,99 @@ func (cs *ComponentSet) Analyze(ctx *AnalysisContext) {
 			})
 		}
 		for _, componentBlock := range body.Blocks {
-			component := NewComponent(componentBlock)
-			component.Analyze(ctx)
-			cs.Components = append(cs.Components, component)
+			cs.Components = append(cs.Components, NewComponent(componentBlock))
 		}
 	}
+	cs.analyzeComponents(ctx)
+}
+
+// Analyzes components such that each component is analyzed after the
+// components that it refers to. Expressions may refer to the spec of another
+// component as `component.<name>`, for example `component.db.ports`.
+func (cs *ComponentSet) analyzeComponents(ctx *AnalysisContext) {
+	parent := cs.EvalContext
+	if parent == nil {
+		parent = defaultEvalContext
+	}
+	byName := make(map[string]*Component, len(cs.Components))
+	for _, component := range cs.Components {
+		if labels := component.Source.Labels; len(labels) == 1 {
+			byName[labels[0]] = component
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	states := make(map[*Component]int, len(cs.Components))
+	values := make(map[string]cty.Value, len(cs.Components))
+	var visit func(component *Component)
+	visit = func(component *Component) {
+		if states[component] != 0 {
+			return
+		}
+		states[component] = visiting
+		for _, ref := range componentReferences(component.Source) {
+			name, _ := traversalAttr(ref, "component")
+			dependency := byName[name]
+			if dependency == nil {
+				// Evaluation will report the undefined reference.
+				continue
+			}
+			if states[dependency] == visiting {
+				ctx.AppendDiags(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Cyclic component reference",
+					Detail:   fmt.Sprintf("Component %q refers to %q, which refers back to it.", component.Source.Labels[0], name),
+					Subject:  ref.SourceRange().Ptr(),
+				})
+				continue
+			}
+			visit(dependency)
+		}
+		component.EvalContext = parent.NewChild()
+		component.EvalContext.Variables = map[string]cty.Value{
+			"component": cty.ObjectVal(values),
+		}
+		component.Analyze(ctx)
+		if component.Name != "" {
+			values[component.Name] = component.specValue()
+		}
+		states[component] = visited
+	}
+	for _, component := range cs.Components {
+		visit(component)
+	}
+}
+
+// Returns the traversals of the form component.<name> within a component block.
+func componentReferences(block *hclsyntax.Block) []hcl.Traversal {
+	var refs []hcl.Traversal
+	_ = hclsyntax.VisitAll(block.Body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if x, ok := node.(*hclsyntax.ScopeTraversalExpr); ok {
+			if _, ok := traversalAttr(x.Traversal, "component"); ok {
+				refs = append(refs, x.Traversal)
+			}
+		}
+		return nil
+	})
+	return refs
 }
 
 type Component struct {
 	Source *hclsyntax.Block
+	// Context for evaluating the component's expressions. If nil, only
+	// functions are available.
+	EvalContext *hcl.EvalContext
 
 	Expansion *hclsyntax.Block
 	Type      string
 	Name      string
 	Spec      string
 	DependsOn []string
+	// Profiles that the component is assigned to. See Enabled.
+	Profiles []string
 }
 
 func NewComponent(block *hclsyntax.Block) *Component {
@@ -95,6 +177,7 @@ func (c *Component) Analyze(ctx *AnalysisContext) {
 			{Name: "type", Required: true},
 			{Name: "spec"},
 			{Name: "depends_on"},
+			{Name: "profiles"},
 		},
 		Blocks: []hcl.BlockHeaderSchema{
 			{Type: "spec"},
@@ -152,31 +235,70 @@ func (c *Component) Analyze(ctx *AnalysisContext) {
 			})
 		}
 	} else {
-		c.Spec, _ = AnalyzeString(ctx, specAttr.Expr)
+		c.Spec, _ = AnalyzeString(ctx, c.EvalContext, specAttr.Expr)
 	}
 
-	depsAttr := content.Attributes["depends_on"]
-	if depsAttr != nil {
-		depsExpr := depsAttr.Expr
-		tup, ok := depsExpr.(*hclsyntax.TupleConsExpr)
-		if !ok {
-			ctx.AppendDiags(&hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Expected array of strings",
-				Detail:   fmt.Sprintf("Expected literal array of strings, got %T", depsExpr),
-				Subject:  depsExpr.Range().Ptr(),
-			})
-		}
-		c.DependsOn = make([]string, 0, len(tup.Exprs))
-		for _, elem := range tup.Exprs {
-			dep, diag := parseLiteralString(elem)
-			if diag != nil {
-				ctx.AppendDiags(diag)
-				continue
+	if depsAttr := content.Attributes["depends_on"]; depsAttr != nil {
+		c.DependsOn = parseLiteralStrings(ctx, depsAttr.Expr)
+	}
+
+	if profilesAttr := content.Attributes["profiles"]; profilesAttr != nil {
+		c.Profiles = parseLiteralStrings(ctx, profilesAttr.Expr)
+	}
+}
+
+// Returns the spec decoded as a value, so that other components may refer to
+// its properties. Specs are either JSON or YAML, which is a superset of JSON.
+func (c *Component) specValue() cty.Value {
+	src := []byte(c.Spec)
+	typ, err := ctyyaml.ImpliedType(src)
+	if err != nil {
+		return cty.DynamicVal
+	}
+	v, err := ctyyaml.Unmarshal(src, typ)
+	if err != nil {
+		return cty.DynamicVal
+	}
+	return v
+}
+
+// Reports whether the component should be started when the given profiles are
+// active. Components that are not assigned to any profile are always enabled.
+func (c *Component) Enabled(activeProfiles []string) bool {
+	if len(c.Profiles) == 0 {
+		return true
+	}
+	for _, active := range activeProfiles {
+		for _, profile := range c.Profiles {
+			if profile == active {
+				return true
 			}
-			c.DependsOn = append(c.DependsOn, dep)
 		}
 	}
+	return false
+}
+
+func parseLiteralStrings(ctx *AnalysisContext, x hcl.Expression) []string {
+	tup, ok := x.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Expected array of strings",
+			Detail:   fmt.Sprintf("Expected literal array of strings, got %T", x),
+			Subject:  x.Range().Ptr(),
+		})
+		return nil
+	}
+	res := make([]string, 0, len(tup.Exprs))
+	for _, elem := range tup.Exprs {
+		s, diag := parseLiteralString(elem)
+		if diag != nil {
+			ctx.AppendDiags(diag)
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
 }
 
 func expandComponent(ctx *AnalysisContext, block *hclsyntax.Block) *hclsyntax.Block {
@@ -198,10 +320,29 @@ func expandComponent(ctx *AnalysisContext, block *hclsyntax.Block) *hclsyntax.Bl
 		})
 		return nil
 	}
+	attrs := body.Attributes
+	specItems := make([]hclsyntax.ObjectConsItem, 0, len(attrs)+len(body.Blocks))
+	var profilesAttr *hclsyntax.Attribute
 	for _, subblock := range body.Blocks {
-		switch subblock.Type {
-		case "_":
-			// TODO: copy content of "meta" blocks in to the expanded output.
+		switch {
+		case subblock.Type == "_":
+			// TODO: copy remaining content of "meta" blocks in to the expanded output.
+			if attr := subblock.Body.Attributes["profiles"]; attr != nil {
+				profilesAttr = attr
+			}
+		case isSpecBlock(block.Type, subblock.Type):
+			if len(subblock.Labels) > 0 {
+				ctx.AppendDiags(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected label",
+					Detail:   fmt.Sprintf(`A %q block expects no labels, but has %d.`, subblock.Type, len(subblock.Labels)),
+					Subject:  subblock.LabelRanges[0].Ptr(),
+				})
+			}
+			specItems = append(specItems, hclsyntax.ObjectConsItem{
+				KeyExpr:   hclgen.NewObjStringKey(subblock.Type, subblock.TypeRange),
+				ValueExpr: blockToObjectCons(subblock),
+			})
 		default:
 			ctx.AppendDiags(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -211,8 +352,6 @@ func expandComponent(ctx *AnalysisContext, block *hclsyntax.Block) *hclsyntax.Bl
 			})
 		}
 	}
-	attrs := body.Attributes
-	specItems := make([]hclsyntax.ObjectConsItem, 0, len(attrs))
 	for _, attr := range attrs {
 		specItems = append(specItems, hclsyntax.ObjectConsItem{
 			KeyExpr:   hclgen.NewObjStringKey(attr.Name, attr.Range()),
@@ -221,7 +360,7 @@ func expandComponent(ctx *AnalysisContext, block *hclsyntax.Block) *hclsyntax.Bl
 	}
 	// sort.Sort(specItemsSorter{specItems}) // XXX sort specItems by attr range?
 	// XXX search for "_" blocks with depends_on, etc. and other meta properties.
-	return &hclsyntax.Block{
+	expanded := &hclsyntax.Block{
 		Type:   "component",
 		Labels: block.Labels,
 		Body: &hclsyntax.Body{
@@ -256,4 +395,45 @@ func expandComponent(ctx *AnalysisContext, block *hclsyntax.Block) *hclsyntax.Bl
 		OpenBraceRange:  block.OpenBraceRange,
 		CloseBraceRange: block.CloseBraceRange,
 	}
+	if profilesAttr != nil {
+		expanded.Body.Attributes["profiles"] = profilesAttr
+	}
+	return expanded
+}
+
+// specBlockTypes lists, by component type, the nested blocks that are
+// expanded in to object-valued spec properties of the same name.
+var specBlockTypes = map[string][]string{
+	"process": {"readiness", "watch"},
+}
+
+func isSpecBlock(componentType, blockType string) bool {
+	for _, typ := range specBlockTypes[componentType] {
+		if typ == blockType {
+			return true
+		}
+	}
+	return false
+}
+
+func blockToObjectCons(block *hclsyntax.Block) *hclsyntax.ObjectConsExpr {
+	body := block.Body
+	obj := &hclsyntax.ObjectConsExpr{
+		Items:     make([]hclsyntax.ObjectConsItem, 0, len(body.Attributes)+len(body.Blocks)),
+		SrcRange:  body.SrcRange,
+		OpenRange: block.OpenBraceRange,
+	}
+	for _, subblock := range body.Blocks {
+		obj.Items = append(obj.Items, hclsyntax.ObjectConsItem{
+			KeyExpr:   hclgen.NewObjStringKey(subblock.Type, subblock.TypeRange),
+			ValueExpr: blockToObjectCons(subblock),
+		})
+	}
+	for _, attr := range body.Attributes {
+		obj.Items = append(obj.Items, hclsyntax.ObjectConsItem{
+			KeyExpr:   hclgen.NewObjStringKey(attr.Name, attr.Range()),
+			ValueExpr: attr.Expr,
+		})
+	}
+	return obj
 }
@@ -0,0 +1,119 @@
+package exohcl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+)
+
+func analyzeComponents(t *testing.T, src string) ([]*Component, hcl.Diagnostics) {
+	t.Helper()
+	filename := "<file>"
+	file, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+	ctx := &AnalysisContext{
+		Context: context.Background(),
+	}
+	m := NewManifest(filename, file)
+	m.Analyze(ctx)
+	cs := NewComponentSet(m)
+	cs.Analyze(ctx)
+	return cs.Components, ctx.Diagnostics
+}
+
+func TestProcessReadinessBlock(t *testing.T) {
+	components, diags := analyzeComponents(t, `
+exo = "0.1"
+components {
+  process "db" {
+    program = "postgres"
+    readiness {
+      tcp = "localhost:5432"
+      intervalSeconds = 2
+    }
+  }
+}
+`)
+	if !assert.Empty(t, diags) || !assert.Len(t, components, 1) {
+		return
+	}
+	assert.Equal(t, "process", components[0].Type)
+	assert.JSONEq(t, `{
+		"program": "postgres",
+		"readiness": {"tcp": "localhost:5432", "intervalSeconds": 2}
+	}`, components[0].Spec)
+}
+
+func TestUnexpectedComponentBlock(t *testing.T) {
+	_, diags := analyzeComponents(t, `
+exo = "0.1"
+components {
+  volume "data" {
+    readiness {}
+  }
+}
+`)
+	assert.True(t, diags.HasErrors())
+}
+
+func TestProcessWatchBlock(t *testing.T) {
+	components, diags := analyzeComponents(t, `
+exo = "0.1"
+components {
+  process "web" {
+    program = "rails"
+    watch {
+      include = ["app/**/*.rb"]
+      signal = "SIGHUP"
+    }
+  }
+}
+`)
+	if !assert.Empty(t, diags) || !assert.Len(t, components, 1) {
+		return
+	}
+	assert.JSONEq(t, `{
+		"program": "rails",
+		"watch": {"include": ["app/**/*.rb"], "signal": "SIGHUP"}
+	}`, components[0].Spec)
+}
+
+func TestComponentProfiles(t *testing.T) {
+	components, diags := analyzeComponents(t, `
+exo = "0.1"
+components {
+  process "web" {
+    program = "rails"
+  }
+  process "debugger" {
+    program = "rdbg"
+    _ {
+      profiles = ["debug", "tools"]
+    }
+  }
+  component "seed" {
+    type = "process"
+    spec = "{}"
+    profiles = ["setup"]
+  }
+}
+`)
+	if !assert.Empty(t, diags) || !assert.Len(t, components, 3) {
+		return
+	}
+	web, debugger, seed := components[0], components[1], components[2]
+	assert.Empty(t, web.Profiles)
+	assert.Equal(t, []string{"debug", "tools"}, debugger.Profiles)
+	assert.JSONEq(t, `{"program": "rdbg"}`, debugger.Spec)
+	assert.Equal(t, []string{"setup"}, seed.Profiles)
+
+	assert.True(t, web.Enabled(nil))
+	assert.False(t, debugger.Enabled(nil))
+	assert.False(t, debugger.Enabled([]string{"setup"}))
+	assert.True(t, debugger.Enabled([]string{"setup", "tools"}))
+}
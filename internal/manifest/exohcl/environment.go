@@ -12,7 +12,8 @@ import (
 
 type Environment struct {
 	// Analysis inputs.
-	Blocks hcl.Blocks
+	Blocks      hcl.Blocks
+	EvalContext *hcl.EvalContext
 
 	// Analysis outputs.
 	Attributes []*hclgen.Attribute
@@ -22,12 +23,17 @@ type Environment struct {
 
 func NewEnvironment(m *Manifest) *Environment {
 	return &Environment{
-		Blocks: m.Environment,
+		Blocks:      m.Environment,
+		EvalContext: m.EvalContext,
 	}
 }
 
 func (env *Environment) Analyze(ctx *AnalysisContext) {
 	env.Variables = make(map[string]string)
+	evalCtx := env.EvalContext
+	if evalCtx == nil {
+		evalCtx = defaultEvalContext
+	}
 
 	if len(env.Blocks) > 1 {
 		ctx.AppendDiags(&hcl.Diagnostic{
@@ -65,8 +71,13 @@ func (env *Environment) Analyze(ctx *AnalysisContext) {
 					Subject:  attr.Expr.Range().Ptr(),
 					Context:  &attr.SrcRange,
 				})
+				continue
 			}
 			env.Attributes = append(env.Attributes, attr)
+			if !v.IsKnown() {
+				// Variables without values have already been reported.
+				continue
+			}
 			env.Variables[attr.Name] = v.AsString()
 		}
 
@@ -74,6 +85,7 @@ func (env *Environment) Analyze(ctx *AnalysisContext) {
 			switch child.Type {
 			case "secrets":
 				secrets := NewSecrets(child)
+				secrets.EvalContext = env.EvalContext
 				secrets.Analyze(ctx)
 				env.Secrets = append(env.Secrets, secrets)
 			default:
@@ -0,0 +1,251 @@
+package exohcl
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/deref/exo/internal/util/pathutil"
+	"github.com/hashicorp/hcl/v2/ext/tryfunc"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Returns the functions available to manifest expressions. Files read with
+// the file function are resolved relative to, and must be contained within,
+// baseDir. If baseDir is empty, files cannot be read.
+func newFunctions(baseDir string) map[string]function.Function {
+	return map[string]function.Function{
+		"abs":         stdlib.AbsoluteFunc,
+		"can":         tryfunc.CanFunc,
+		"ceil":        stdlib.CeilFunc,
+		"chomp":       stdlib.ChompFunc,
+		"cidrhost":    cidrHostFunc,
+		"cidrnetmask": cidrNetmaskFunc,
+		"cidrsubnet":  cidrSubnetFunc,
+		"coalesce":    stdlib.CoalesceFunc,
+		"compact":     stdlib.CompactFunc,
+		"concat":      stdlib.ConcatFunc,
+		"contains":    stdlib.ContainsFunc,
+		"distinct":    stdlib.DistinctFunc,
+		"element":     stdlib.ElementFunc,
+		"env":         envFunc,
+		"file":        makeFileFunc(baseDir),
+		"flatten":     stdlib.FlattenFunc,
+		"floor":       stdlib.FloorFunc,
+		"format":      stdlib.FormatFunc,
+		"formatlist":  stdlib.FormatListFunc,
+		"indent":      stdlib.IndentFunc,
+		"join":        stdlib.JoinFunc,
+		"jsondecode":  stdlib.JSONDecodeFunc,
+		"jsonencode":  stdlib.JSONEncodeFunc,
+		"keys":        stdlib.KeysFunc,
+		"length":      stdlib.LengthFunc,
+		"lookup":      stdlib.LookupFunc,
+		"lower":       stdlib.LowerFunc,
+		"max":         stdlib.MaxFunc,
+		"merge":       stdlib.MergeFunc,
+		"min":         stdlib.MinFunc,
+		"parseint":    stdlib.ParseIntFunc,
+		"range":       stdlib.RangeFunc,
+		"regex":       stdlib.RegexFunc,
+		"regexall":    stdlib.RegexAllFunc,
+		"replace":     stdlib.ReplaceFunc,
+		"reverse":     stdlib.ReverseListFunc,
+		"slice":       stdlib.SliceFunc,
+		"sort":        stdlib.SortFunc,
+		"split":       stdlib.SplitFunc,
+		"strlen":      stdlib.StrlenFunc,
+		"substr":      stdlib.SubstrFunc,
+		"title":       stdlib.TitleFunc,
+		"trim":        stdlib.TrimFunc,
+		"trimprefix":  stdlib.TrimPrefixFunc,
+		"trimspace":   stdlib.TrimSpaceFunc,
+		"trimsuffix":  stdlib.TrimSuffixFunc,
+		"try":         tryfunc.TryFunc,
+		"upper":       stdlib.UpperFunc,
+		"values":      stdlib.ValuesFunc,
+		"yamldecode":  ctyyaml.YAMLDecodeFunc,
+		"yamlencode":  ctyyaml.YAMLEncodeFunc,
+		"zipmap":      stdlib.ZipmapFunc,
+	}
+}
+
+// env(name, default?) returns the value of an environment variable of the
+// Exo daemon. If the variable is unset, the default is returned, or an error
+// if no default is given.
+var envFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "name", Type: cty.String},
+	},
+	VarParam: &function.Parameter{Name: "default", Type: cty.String},
+	Type:     function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		name := args[0].AsString()
+		if value, ok := os.LookupEnv(name); ok {
+			return cty.StringVal(value), nil
+		}
+		switch len(args) {
+		case 1:
+			return cty.NilVal, fmt.Errorf("environment variable %s is not set", name)
+		case 2:
+			return args[1], nil
+		default:
+			return cty.NilVal, fmt.Errorf("expected at most one default, got %d", len(args)-1)
+		}
+	},
+})
+
+func makeFileFunc(baseDir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "path", Type: cty.String},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if baseDir == "" {
+				return cty.NilVal, fmt.Errorf("files can only be read from manifests loaded from a file")
+			}
+			path := args[0].AsString()
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(baseDir, path)
+			}
+			if !pathutil.HasFilePathPrefix(path, baseDir) {
+				return cty.NilVal, fmt.Errorf("file %s is not contained within the manifest directory", path)
+			}
+			bs, err := os.ReadFile(path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(string(bs)), nil
+		},
+	})
+}
+
+// cidrsubnet(prefix, newbits, netnum) calculates a subnet address within the
+// given IP network address prefix.
+var cidrSubnetFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "newbits", Type: cty.Number},
+		{Name: "netnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, err
+		}
+		newbits, err := intArg(args[1])
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("invalid newbits: %w", err)
+		}
+		ones, bits := network.Mask.Size()
+		prefixLen := ones + newbits
+		if newbits < 0 || prefixLen > bits {
+			return cty.NilVal, fmt.Errorf("insufficient address space to extend prefix of %d by %d", ones, newbits)
+		}
+		netnum := args[2].AsBigFloat()
+		if !netnum.IsInt() || netnum.Sign() < 0 {
+			return cty.NilVal, fmt.Errorf("netnum must be a non-negative integer")
+		}
+		num, _ := netnum.Int(nil)
+		if num.BitLen() > newbits {
+			return cty.NilVal, fmt.Errorf("prefix extension of %d does not accommodate a subnet numbered %s", newbits, num)
+		}
+		ip := ipToInt(network.IP)
+		ip.Or(ip, num.Lsh(num, uint(bits-prefixLen)))
+		subnet := &net.IPNet{
+			IP:   intToIP(ip, len(network.IP)),
+			Mask: net.CIDRMask(prefixLen, bits),
+		}
+		return cty.StringVal(subnet.String()), nil
+	},
+})
+
+// cidrhost(prefix, hostnum) calculates the address of a host within the given
+// IP network address prefix. Negative host numbers count back from the end of
+// the range.
+var cidrHostFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "hostnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, err
+		}
+		hostnum := args[1].AsBigFloat()
+		if !hostnum.IsInt() {
+			return cty.NilVal, fmt.Errorf("hostnum must be an integer")
+		}
+		num, _ := hostnum.Int(nil)
+		ones, bits := network.Mask.Size()
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+		if num.Sign() < 0 {
+			num.Add(num, size)
+		}
+		if num.Sign() < 0 || num.Cmp(size) >= 0 {
+			return cty.NilVal, fmt.Errorf("prefix of %d does not accommodate a host numbered %s", ones, hostnum.Text('f', 0))
+		}
+		ip := ipToInt(network.IP)
+		ip.Or(ip, num)
+		return cty.StringVal(intToIP(ip, len(network.IP)).String()), nil
+	},
+})
+
+// cidrnetmask(prefix) returns the netmask of an IPv4 network address prefix,
+// in dotted-decimal notation.
+var cidrNetmaskFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, err
+		}
+		if len(network.Mask) != net.IPv4len {
+			return cty.NilVal, fmt.Errorf("only IPv4 networks have netmasks")
+		}
+		return cty.StringVal(net.IP(network.Mask).String()), nil
+	},
+})
+
+func parseCIDR(s string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR expression: %w", err)
+	}
+	if ip4 := network.IP.To4(); ip4 != nil {
+		network.IP = ip4
+	}
+	return network, nil
+}
+
+func intArg(v cty.Value) (int, error) {
+	f := v.AsBigFloat()
+	if !f.IsInt() {
+		return 0, fmt.Errorf("expected integer, got %s", f.Text('f', -1))
+	}
+	i, _ := f.Int64()
+	return int(i), nil
+}
+
+func ipToInt(ip net.IP) *big.Int {
+	return new(big.Int).SetBytes(ip)
+}
+
+func intToIP(i *big.Int, size int) net.IP {
+	bs := i.Bytes()
+	ip := make(net.IP, size)
+	copy(ip[size-len(bs):], bs)
+	return ip
+}
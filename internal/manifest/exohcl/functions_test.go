@@ -0,0 +1,55 @@
+package exohcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCIDRFunctions(t *testing.T) {
+	tests := []struct {
+		Call     func() (cty.Value, error)
+		Expected string
+	}{
+		{
+			func() (cty.Value, error) {
+				return cidrSubnetFunc.Call([]cty.Value{cty.StringVal("10.0.0.0/16"), cty.NumberIntVal(8), cty.NumberIntVal(2)})
+			},
+			"10.0.2.0/24",
+		},
+		{
+			func() (cty.Value, error) {
+				return cidrSubnetFunc.Call([]cty.Value{cty.StringVal("fd00:fd12:3456:7890::/56"), cty.NumberIntVal(16), cty.NumberIntVal(162)})
+			},
+			"fd00:fd12:3456:7800:a200::/72",
+		},
+		{
+			func() (cty.Value, error) {
+				return cidrHostFunc.Call([]cty.Value{cty.StringVal("10.12.112.0/20"), cty.NumberIntVal(16)})
+			},
+			"10.12.112.16",
+		},
+		{
+			func() (cty.Value, error) {
+				return cidrHostFunc.Call([]cty.Value{cty.StringVal("10.12.112.0/20"), cty.NumberIntVal(-2)})
+			},
+			"10.12.127.254",
+		},
+		{
+			func() (cty.Value, error) {
+				return cidrNetmaskFunc.Call([]cty.Value{cty.StringVal("172.16.0.0/12")})
+			},
+			"255.240.0.0",
+		},
+	}
+	for _, test := range tests {
+		actual, err := test.Call()
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.Expected, actual.AsString())
+		}
+	}
+
+	_, err := cidrSubnetFunc.Call([]cty.Value{cty.StringVal("10.0.0.0/30"), cty.NumberIntVal(1), cty.NumberIntVal(2)})
+	assert.Error(t, err)
+}
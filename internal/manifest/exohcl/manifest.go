@@ -14,6 +14,11 @@ type Manifest struct {
 	FormatVersion *FormatVersion
 	Environment   hcl.Blocks
 	Components    hcl.Blocks
+	Variables     hcl.Blocks
+	Locals        hcl.Blocks
+	// Context for evaluating expressions in the manifest, including the
+	// values of variables and locals.
+	EvalContext *hcl.EvalContext
 }
 
 func NewManifest(filename string, file *hcl.File) *Manifest {
@@ -32,6 +37,8 @@ func (m *Manifest) Analyze(ctx *AnalysisContext) {
 		Blocks: []hcl.BlockHeaderSchema{
 			{Type: "environment"},
 			{Type: "components"},
+			{Type: "variable", LabelNames: []string{"name"}},
+			{Type: "locals"},
 		},
 	})
 	ctx.AppendDiags(diags...)
@@ -41,5 +48,8 @@ func (m *Manifest) Analyze(ctx *AnalysisContext) {
 		m.FormatVersion.Analyze(ctx)
 		m.Environment = m.Content.Blocks.OfType("environment")
 		m.Components = m.Content.Blocks.OfType("components")
+		m.Variables = m.Content.Blocks.OfType("variable")
+		m.Locals = m.Content.Blocks.OfType("locals")
+		m.EvalContext = newEvalContext(ctx, m)
 	}
 }
@@ -11,7 +11,8 @@ import (
 
 type Secrets struct {
 	// Analysis inputs.
-	Block *hclsyntax.Block
+	Block       *hclsyntax.Block
+	EvalContext *hcl.EvalContext
 
 	// Analysis outputs.
 	Source string
@@ -41,7 +42,7 @@ func (s *Secrets) Analyze(ctx *AnalysisContext) {
 	ctx.AppendDiags(diags...)
 
 	sourceAttr := content.Attributes["source"]
-	s.Source, _ = AnalyzeString(ctx, sourceAttr.Expr)
+	s.Source, _ = AnalyzeString(ctx, s.EvalContext, sourceAttr.Expr)
 }
 
 type AppendSecrets struct {
@@ -0,0 +1,242 @@
+package exohcl
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Evaluation context for manifests without variables or locals, and for
+// expressions analyzed outside of a manifest.
+var defaultEvalContext = &hcl.EvalContext{
+	Functions: newFunctions(""),
+}
+
+// Returns the evaluation context for expressions in a manifest. The context
+// provides the manifest's functions, as well as the values of its variables
+// and locals as "var" and "local" respectively.
+func newEvalContext(ctx *AnalysisContext, m *Manifest) *hcl.EvalContext {
+	baseDir := ""
+	if m.Filename != "" && m.Filename != "/dev/stdin" {
+		if abs, err := filepath.Abs(m.Filename); err == nil {
+			baseDir = filepath.Dir(abs)
+		}
+	}
+	evalCtx := &hcl.EvalContext{
+		Functions: newFunctions(baseDir),
+		Variables: map[string]cty.Value{},
+	}
+	evalCtx.Variables["var"] = analyzeVariables(ctx, m.Variables)
+	evalCtx.Variables["local"] = analyzeLocals(ctx, evalCtx, m.Locals)
+	return evalCtx
+}
+
+// Evaluates variable blocks. Values given in the analysis context take
+// precedence over defaults. Variables without a value are unknown.
+func analyzeVariables(ctx *AnalysisContext, blocks hcl.Blocks) cty.Value {
+	values := make(map[string]cty.Value, len(blocks))
+	for _, block := range blocks {
+		name := block.Labels[0]
+		if !hclsyntax.ValidIdentifier(name) {
+			ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable name",
+				Detail:   fmt.Sprintf("Variable names must be valid identifiers, but got %q.", name),
+				Subject:  &block.LabelRanges[0],
+			})
+			continue
+		}
+		if _, exists := values[name]; exists {
+			ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate variable",
+				Detail:   fmt.Sprintf("A variable named %q was already declared.", name),
+				Subject:  &block.LabelRanges[0],
+			})
+			continue
+		}
+		values[name] = analyzeVariable(ctx, name, block)
+	}
+	return cty.ObjectVal(values)
+}
+
+func analyzeVariable(ctx *AnalysisContext, name string, block *hcl.Block) cty.Value {
+	content, diags := block.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "type"},
+			{Name: "default"},
+			{Name: "description"},
+		},
+	})
+	ctx.AppendDiags(diags...)
+
+	typ := cty.DynamicPseudoType
+	if typeAttr := content.Attributes["type"]; typeAttr != nil {
+		var diags hcl.Diagnostics
+		typ, diags = typeexpr.TypeConstraint(typeAttr.Expr)
+		ctx.AppendDiags(diags...)
+		if diags.HasErrors() {
+			return cty.DynamicVal
+		}
+	}
+
+	if override, ok := ctx.Variables[name]; ok {
+		return parseVariableOverride(ctx, name, typ, override, block.DefRange)
+	}
+
+	defaultAttr := content.Attributes["default"]
+	if defaultAttr == nil {
+		ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "No value for required variable",
+			Detail:   fmt.Sprintf("The variable %q has no default, so a value must be given with --var or with the EXO_VAR_%s environment variable.", name, name),
+			Subject:  block.DefRange.Ptr(),
+		})
+		return cty.UnknownVal(typ)
+	}
+	// Defaults may not refer to other variables or call functions.
+	v, diags := defaultAttr.Expr.Value(nil)
+	ctx.AppendDiags(diags...)
+	if diags.HasErrors() {
+		return cty.UnknownVal(typ)
+	}
+	converted, err := convert.Convert(v, typ)
+	if err != nil {
+		ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid default value for variable",
+			Detail:   fmt.Sprintf("The default value of %q is not compatible with its type: %v.", name, err),
+			Subject:  defaultAttr.Expr.Range().Ptr(),
+		})
+		return cty.UnknownVal(typ)
+	}
+	return converted
+}
+
+// Variables given outside of the manifest are strings. Values for variables of
+// other types are parsed as HCL expressions, such as `["a", "b"]`.
+func parseVariableOverride(ctx *AnalysisContext, name string, typ cty.Type, s string, rng hcl.Range) cty.Value {
+	if typ == cty.DynamicPseudoType || typ == cty.String {
+		return cty.StringVal(s)
+	}
+	x, diags := hclsyntax.ParseExpression([]byte(s), fmt.Sprintf("<value for var.%s>", name), hcl.InitialPos)
+	ctx.AppendDiags(diags...)
+	if diags.HasErrors() {
+		return cty.UnknownVal(typ)
+	}
+	v, diags := x.Value(nil)
+	ctx.AppendDiags(diags...)
+	if diags.HasErrors() {
+		return cty.UnknownVal(typ)
+	}
+	converted, err := convert.Convert(v, typ)
+	if err != nil {
+		ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value for variable",
+			Detail:   fmt.Sprintf("The value given for %q is not compatible with its type: %v.", name, err),
+			Subject:  rng.Ptr(),
+		})
+		return cty.UnknownVal(typ)
+	}
+	return converted
+}
+
+// Evaluates the attributes of locals blocks. Locals may refer to variables
+// and to each other, in any order, so long as there are no cycles.
+func analyzeLocals(ctx *AnalysisContext, evalCtx *hcl.EvalContext, blocks hcl.Blocks) cty.Value {
+	declared := make(map[string]*hcl.Attribute)
+	var pending []*hcl.Attribute
+	for _, block := range blocks {
+		attrs, diags := block.Body.JustAttributes()
+		ctx.AppendDiags(diags...)
+		sorted := make([]*hcl.Attribute, 0, len(attrs))
+		for _, attr := range attrs {
+			sorted = append(sorted, attr)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Range.Start.Byte < sorted[j].Range.Start.Byte
+		})
+		for _, attr := range sorted {
+			if prev, exists := declared[attr.Name]; exists {
+				ctx.AppendDiags(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate local value",
+					Detail:   fmt.Sprintf("A local value named %q was already declared at %s.", attr.Name, prev.NameRange),
+					Subject:  &attr.NameRange,
+				})
+				continue
+			}
+			declared[attr.Name] = attr
+			pending = append(pending, attr)
+		}
+	}
+
+	values := make(map[string]cty.Value, len(pending))
+	for len(pending) > 0 {
+		var deferred []*hcl.Attribute
+		for _, attr := range pending {
+			if !localsResolved(attr.Expr, declared, values) {
+				deferred = append(deferred, attr)
+				continue
+			}
+			evalCtx.Variables["local"] = cty.ObjectVal(values)
+			v, diags := attr.Expr.Value(evalCtx)
+			ctx.AppendDiags(diags...)
+			if diags.HasErrors() {
+				v = cty.DynamicVal
+			}
+			values[attr.Name] = v
+		}
+		if len(deferred) == len(pending) {
+			for _, attr := range deferred {
+				ctx.AppendDiags(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Cyclic local value",
+					Detail:   fmt.Sprintf("The local value %q refers to itself, either directly or through other local values.", attr.Name),
+					Subject:  &attr.NameRange,
+				})
+				values[attr.Name] = cty.DynamicVal
+			}
+			break
+		}
+		pending = deferred
+	}
+	return cty.ObjectVal(values)
+}
+
+// Reports whether every declared local referred to by x has been evaluated.
+func localsResolved(x hcl.Expression, declared map[string]*hcl.Attribute, values map[string]cty.Value) bool {
+	for _, traversal := range x.Variables() {
+		name, ok := traversalAttr(traversal, "local")
+		if !ok {
+			continue
+		}
+		if _, isDeclared := declared[name]; !isDeclared {
+			// Evaluation will report the undefined reference.
+			continue
+		}
+		if _, isEvaluated := values[name]; !isEvaluated {
+			return false
+		}
+	}
+	return true
+}
+
+// If traversal is of the form root.name, returns name.
+func traversalAttr(traversal hcl.Traversal, root string) (string, bool) {
+	if traversal.RootName() != root || len(traversal) < 2 {
+		return "", false
+	}
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+	return attr.Name, true
+}
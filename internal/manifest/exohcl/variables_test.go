@@ -0,0 +1,139 @@
+package exohcl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+)
+
+func analyzeComponentsWithVariables(t *testing.T, src string, vars map[string]string) ([]*Component, hcl.Diagnostics) {
+	t.Helper()
+	filename := "<file>"
+	file, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+	ctx := &AnalysisContext{
+		Context:   context.Background(),
+		Variables: vars,
+	}
+	m := NewManifest(filename, file)
+	m.Analyze(ctx)
+	cs := NewComponentSet(m)
+	cs.Analyze(ctx)
+	return cs.Components, ctx.Diagnostics
+}
+
+const variablesManifest = `
+exo = "0.1"
+variable "tag" {
+  default = "latest"
+}
+variable "replicas" {
+  type    = number
+  default = 1
+}
+locals {
+  image = "${local.registry}/app:${var.tag}"
+  registry = lower("Registry.Example.com")
+}
+components {
+  container "app" {
+    image   = local.image
+    command = format("serve --replicas=%d", var.replicas)
+  }
+}
+`
+
+func TestVariablesAndLocals(t *testing.T) {
+	components, diags := analyzeComponentsWithVariables(t, variablesManifest, nil)
+	if !assert.Empty(t, diags) || !assert.Len(t, components, 1) {
+		return
+	}
+	assert.Equal(t, `"command": "serve --replicas=1"
+"image": "registry.example.com/app:latest"
+`, components[0].Spec)
+
+	components, diags = analyzeComponentsWithVariables(t, variablesManifest, map[string]string{
+		"tag":      "v2",
+		"replicas": "3",
+	})
+	if !assert.Empty(t, diags) || !assert.Len(t, components, 1) {
+		return
+	}
+	assert.Equal(t, `"command": "serve --replicas=3"
+"image": "registry.example.com/app:v2"
+`, components[0].Spec)
+}
+
+func TestRequiredVariable(t *testing.T) {
+	_, diags := analyzeComponentsWithVariables(t, `
+exo = "0.1"
+variable "tag" {}
+`, nil)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "No value for required variable", diags[0].Summary)
+	}
+}
+
+func TestCyclicLocals(t *testing.T) {
+	_, diags := analyzeComponentsWithVariables(t, `
+exo = "0.1"
+locals {
+  a = local.b
+  b = local.a
+  c = "ok"
+}
+`, nil)
+	assert.Len(t, diags, 2)
+	for _, diag := range diags {
+		assert.Equal(t, "Cyclic local value", diag.Summary)
+	}
+}
+
+func TestComponentReferences(t *testing.T) {
+	components, diags := analyzeComponentsWithVariables(t, `
+exo = "0.1"
+components {
+  process "web" {
+    program     = "web"
+    environment = { DATABASE_PORT = component.db.environment.PORT }
+  }
+  process "db" {
+    program     = "postgres"
+    environment = { PORT = "5432" }
+  }
+}
+`, nil)
+	if !assert.Empty(t, diags) || !assert.Len(t, components, 2) {
+		return
+	}
+	assert.Equal(t, "web", components[0].Name)
+	assert.JSONEq(t, `{
+		"program": "web",
+		"environment": {"DATABASE_PORT": "5432"}
+	}`, components[0].Spec)
+}
+
+func TestCyclicComponentReferences(t *testing.T) {
+	_, diags := analyzeComponentsWithVariables(t, `
+exo = "0.1"
+components {
+  process "a" {
+    program = component.b.program
+  }
+  process "b" {
+    program = component.a.program
+  }
+}
+`, nil)
+	assert.True(t, diags.HasErrors())
+	var summaries []string
+	for _, diag := range diags {
+		summaries = append(summaries, diag.Summary)
+	}
+	assert.Contains(t, summaries, "Cyclic component reference")
+}
@@ -0,0 +1,534 @@
+// Package k8s imports local Kubernetes manifests. Resources are translated to
+// an equivalent compose project, which is then imported like a compose file:
+// workloads become containers, Services publish their ports, ConfigMaps
+// provide environment variables, and PersistentVolumeClaims become volumes.
+package k8s
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	composeimport "github.com/deref/exo/internal/manifest/compose"
+	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/deref/exo/internal/providers/docker/compose"
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type Importer struct {
+	// ProjectName is used as a prefix for the resources created by this importer.
+	ProjectName string
+}
+
+func (imp *Importer) Import(ctx *exohcl.AnalysisContext, bs []byte) *hcl.File {
+	objects, err := parseObjects(bs)
+	if err != nil {
+		ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  err.Error(),
+		})
+		return exohcl.NewBuilder(bs).Build()
+	}
+
+	tr := &translator{
+		ctx: ctx,
+		src: bs,
+		project: composeProject{
+			Services: make(map[string]*composeService),
+			Volumes:  make(map[string]struct{}),
+		},
+		configMaps: make(map[string]map[string]string),
+	}
+	tr.translate(objects)
+
+	doc, err := yaml.Marshal(tr.project)
+	if err != nil {
+		panic(err)
+	}
+	project, err := compose.Parse(bytes.NewReader(doc))
+	if err != nil {
+		ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("translating to compose: %v", err),
+		})
+		return exohcl.NewBuilder(bs).Build()
+	}
+	composeImporter := &composeimport.Importer{
+		ProjectName: imp.ProjectName,
+	}
+	return composeImporter.ImportProject(ctx, bs, project)
+}
+
+// Decodes the objects of each document, flattening lists.
+func parseObjects(bs []byte) ([]object, error) {
+	var objects []object
+	var add func(node *yaml.Node) error
+	add = func(node *yaml.Node) error {
+		var obj object
+		if err := node.Decode(&obj); err != nil {
+			return err
+		}
+		if strings.HasSuffix(obj.Kind, "List") {
+			var l list
+			if err := node.Decode(&l); err != nil {
+				return err
+			}
+			for i := range l.Items {
+				if err := add(&l.Items[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if obj.Kind == "" {
+			return fmt.Errorf("line %d: expected kind", node.Line)
+		}
+		obj.node = node
+		objects = append(objects, obj)
+		return nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(bs))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Skip empty documents, such as after a trailing separator.
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue
+		}
+		if err := add(doc.Content[0]); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type composeProject struct {
+	Services map[string]*composeService `yaml:"services,omitempty"`
+	Volumes  map[string]struct{}        `yaml:"volumes,omitempty"`
+}
+
+type composeService struct {
+	Image       string            `yaml:"image,omitempty"`
+	Entrypoint  []string          `yaml:"entrypoint,omitempty"`
+	Command     []string          `yaml:"command,omitempty"`
+	WorkingDir  string            `yaml:"working_dir,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Ports       []string          `yaml:"ports,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	StdinOpen   bool              `yaml:"stdin_open,omitempty"`
+	TTY         bool              `yaml:"tty,omitempty"`
+}
+
+// A container of a workload, which Services may select by pod labels.
+type workloadContainer struct {
+	Key       string
+	PodLabels map[string]string
+	Container container
+}
+
+type translator struct {
+	ctx        *exohcl.AnalysisContext
+	src        []byte
+	project    composeProject
+	configMaps map[string]map[string]string
+	containers []workloadContainer
+}
+
+func (tr *translator) translate(objects []object) {
+	// ConfigMaps and claims are collected first, since workloads may precede
+	// the objects that they reference.
+	var workloads, services []object
+	for _, obj := range objects {
+		switch obj.Kind {
+		case "ConfigMap":
+			var cm configMap
+			if tr.decode(obj, &cm) {
+				tr.configMaps[obj.Metadata.Name] = cm.Data
+			}
+		case "PersistentVolumeClaim":
+			tr.project.Volumes[obj.Metadata.Name] = struct{}{}
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Pod":
+			workloads = append(workloads, obj)
+		case "Service":
+			services = append(services, obj)
+		case "Namespace":
+			// Namespaces are ignored, since all objects are imported together.
+		default:
+			tr.ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning(
+				fmt.Sprintf("Kubernetes %s resources", obj.Kind),
+				"Only Deployments, StatefulSets, DaemonSets, ReplicaSets, Pods, Services, ConfigMaps, and PersistentVolumeClaims are imported.",
+				tr.subject(obj.node),
+			))
+		}
+	}
+	for _, obj := range workloads {
+		tr.translateWorkload(obj)
+	}
+	for _, obj := range services {
+		tr.translateService(obj)
+	}
+}
+
+func (tr *translator) decode(obj object, v any) bool {
+	if err := obj.node.Decode(v); err != nil {
+		tr.ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("invalid %s %q: %v", obj.Kind, obj.Metadata.Name, err),
+			Subject:  tr.subject(obj.node),
+		})
+		return false
+	}
+	return true
+}
+
+func (tr *translator) translateWorkload(obj object) {
+	var spec podSpec
+	var podLabels map[string]string
+	if obj.Kind == "Pod" {
+		var p pod
+		if !tr.decode(obj, &p) {
+			return
+		}
+		spec = p.Spec
+		podLabels = obj.Metadata.Labels
+	} else {
+		var w workload
+		if !tr.decode(obj, &w) {
+			return
+		}
+		if w.Spec.Replicas != nil && *w.Spec.Replicas > 1 {
+			tr.ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning(
+				"replicas",
+				fmt.Sprintf("%s %q will run a single container.", obj.Kind, obj.Metadata.Name),
+				tr.subject(obj.node),
+			))
+		}
+		spec = w.Spec.Template.Spec
+		podLabels = w.Spec.Template.Metadata.Labels
+	}
+
+	if len(spec.InitContainers) > 0 {
+		tr.ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning(
+			"init containers",
+			fmt.Sprintf("The init containers of %s %q will not be run.", obj.Kind, obj.Metadata.Name),
+			tr.subject(obj.node),
+		))
+	}
+	if len(spec.Containers) == 0 {
+		tr.ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s %q has no containers", obj.Kind, obj.Metadata.Name),
+			Subject:  tr.subject(obj.node),
+		})
+		return
+	}
+
+	volumes := make(map[string]podVolume, len(spec.Volumes))
+	for _, volume := range spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+
+	for _, c := range spec.Containers {
+		// Containers of a pod share a network namespace, but separate compose
+		// services do not, so sidecars are imported under distinct names.
+		key := obj.Metadata.Name
+		if len(spec.Containers) > 1 {
+			key = obj.Metadata.Name + "-" + c.Name
+		}
+		if _, exists := tr.project.Services[key]; exists {
+			tr.ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("duplicate container name: %q", key),
+				Subject:  tr.subject(obj.node),
+			})
+			continue
+		}
+		tr.project.Services[key] = tr.translateContainer(obj, c, volumes)
+		tr.containers = append(tr.containers, workloadContainer{
+			Key:       key,
+			PodLabels: podLabels,
+			Container: c,
+		})
+	}
+}
+
+func (tr *translator) translateContainer(obj object, c container, volumes map[string]podVolume) *composeService {
+	svc := &composeService{
+		Image:      escape(c.Image),
+		Entrypoint: escapeAll(c.Command),
+		Command:    escapeAll(c.Args),
+		WorkingDir: escape(c.WorkingDir),
+		StdinOpen:  c.Stdin,
+		TTY:        c.TTY,
+	}
+	subject := tr.subject(obj.node)
+	unsupported := func(feature string) {
+		tr.ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning(
+			feature,
+			fmt.Sprintf("It is ignored by container %q of %s %q.", c.Name, obj.Kind, obj.Metadata.Name),
+			subject,
+		))
+	}
+
+	env := make(map[string]string)
+	for _, from := range c.EnvFrom {
+		switch {
+		case from.ConfigMapRef != nil:
+			data, ok := tr.configMaps[from.ConfigMapRef.Name]
+			if !ok && !from.ConfigMapRef.Optional {
+				tr.unknownConfigMap(from.ConfigMapRef.Name, subject)
+			}
+			for k, v := range data {
+				env[from.Prefix+k] = escape(v)
+			}
+		case from.SecretRef != nil:
+			unsupported("environment from Secrets")
+		}
+	}
+	for _, v := range c.Env {
+		switch {
+		case v.ValueFrom == nil:
+			env[v.Name] = escape(v.Value)
+		case v.ValueFrom.ConfigMapKeyRef != nil:
+			ref := v.ValueFrom.ConfigMapKeyRef
+			data, ok := tr.configMaps[ref.Name]
+			if !ok {
+				if !ref.Optional {
+					tr.unknownConfigMap(ref.Name, subject)
+				}
+				continue
+			}
+			value, ok := data[ref.Key]
+			if !ok {
+				if !ref.Optional {
+					tr.ctx.AppendDiags(&hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  fmt.Sprintf("ConfigMap %q has no key %q", ref.Name, ref.Key),
+						Subject:  subject,
+					})
+				}
+				continue
+			}
+			env[v.Name] = escape(value)
+		case v.ValueFrom.SecretKeyRef != nil:
+			unsupported("environment from Secrets")
+		default:
+			unsupported(fmt.Sprintf("environment variable %q from fields", v.Name))
+		}
+	}
+	if len(env) > 0 {
+		svc.Environment = env
+	}
+
+	for _, mount := range c.VolumeMounts {
+		if mount.SubPath != "" {
+			unsupported("volume mount subPath")
+			continue
+		}
+		volume, ok := volumes[mount.Name]
+		if !ok {
+			tr.ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("unknown volume: %q", mount.Name),
+				Subject:  subject,
+			})
+			continue
+		}
+		var source string
+		readOnly := mount.ReadOnly
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			source = volume.PersistentVolumeClaim.ClaimName
+			readOnly = readOnly || volume.PersistentVolumeClaim.ReadOnly
+			// Claims are imported even when not declared, so that the volume is
+			// namespaced with the project.
+			tr.project.Volumes[source] = struct{}{}
+		case volume.HostPath != nil:
+			source = volume.HostPath.Path
+		case volume.EmptyDir != nil:
+			// Anonymous volume.
+		case volume.ConfigMap != nil:
+			unsupported("ConfigMap volumes")
+			continue
+		case volume.Secret != nil:
+			unsupported("Secret volumes")
+			continue
+		default:
+			unsupported(fmt.Sprintf("volume %q", volume.Name))
+			continue
+		}
+		spec := mount.MountPath
+		if source != "" {
+			spec = source + ":" + spec
+			if readOnly {
+				spec += ":ro"
+			}
+		}
+		svc.Volumes = append(svc.Volumes, escape(spec))
+	}
+
+	return svc
+}
+
+func (tr *translator) unknownConfigMap(name string, subject *hcl.Range) {
+	tr.ctx.AppendDiags(&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf("unknown ConfigMap: %q", name),
+		Subject:  subject,
+	})
+}
+
+func (tr *translator) translateService(obj object) {
+	var svc service
+	if !tr.decode(obj, &svc) {
+		return
+	}
+	subject := tr.subject(obj.node)
+	if svc.Spec.Type == "ExternalName" {
+		tr.ctx.AppendDiags(exohcl.NewUnsupportedFeatureWarning(
+			"ExternalName Services",
+			fmt.Sprintf("Service %q is ignored.", obj.Metadata.Name),
+			subject,
+		))
+		return
+	}
+
+	var selected []workloadContainer
+	if len(svc.Spec.Selector) > 0 {
+		for _, wc := range tr.containers {
+			if selectorMatches(svc.Spec.Selector, wc.PodLabels) {
+				selected = append(selected, wc)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		tr.ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  fmt.Sprintf("Service %q selects no containers", obj.Metadata.Name),
+			Subject:  subject,
+		})
+		return
+	}
+	if len(selected) > 1 || selected[0].Key != obj.Metadata.Name {
+		tr.ctx.AppendDiags(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  fmt.Sprintf("Service name %q is not resolvable", obj.Metadata.Name),
+			Detail:   "Containers are reachable by their own names and target ports, rather than by Service name and port.",
+			Subject:  subject,
+		})
+	}
+
+	for _, port := range svc.Spec.Ports {
+		published := false
+		for _, wc := range selected {
+			target, ok := resolveTargetPort(port, wc.Container)
+			if !ok {
+				continue
+			}
+			mapping := fmt.Sprintf("%d:%d", port.Port, target)
+			if strings.EqualFold(port.Protocol, "UDP") {
+				mapping += "/udp"
+			}
+			composeSvc := tr.project.Services[wc.Key]
+			if !containsString(composeSvc.Ports, mapping) {
+				composeSvc.Ports = append(composeSvc.Ports, mapping)
+			}
+			published = true
+			// Each host port can only be published once.
+			break
+		}
+		if !published {
+			tr.ctx.AppendDiags(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Service %q target port %q does not match a container port", obj.Metadata.Name, port.TargetPort),
+				Subject:  subject,
+			})
+		}
+	}
+}
+
+func selectorMatches(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolves the container port that a Service port targets. Named target ports
+// refer to a named container port. Numbered target ports prefer a container
+// that declares the port, but containers are not required to declare ports.
+func resolveTargetPort(port servicePort, c container) (int, bool) {
+	target := port.TargetPort
+	if target == "" {
+		target = strconv.Itoa(port.Port)
+	}
+	if n, err := strconv.Atoi(target); err == nil {
+		return n, true
+	}
+	for _, cp := range c.Ports {
+		if cp.Name == target {
+			return cp.ContainerPort, true
+		}
+	}
+	return 0, false
+}
+
+func containsString(ss []string, s string) bool {
+	for _, elem := range ss {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Escapes compose interpolation syntax.
+func escape(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+func escapeAll(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+	res := make([]string, len(ss))
+	for i, s := range ss {
+		res[i] = escape(s)
+	}
+	return res
+}
+
+func (tr *translator) subject(node *yaml.Node) *hcl.Range {
+	if node == nil || node.Line == 0 {
+		return nil
+	}
+	offset := 0
+	for line := 1; line < node.Line; line++ {
+		i := bytes.IndexByte(tr.src[offset:], '\n')
+		if i < 0 {
+			break
+		}
+		offset += i + 1
+	}
+	pos := hcl.Pos{
+		Line:   node.Line,
+		Column: node.Column,
+		Byte:   offset + node.Column - 1,
+	}
+	return &hcl.Range{
+		Start: pos,
+		End:   pos,
+	}
+}
@@ -0,0 +1,233 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/deref/exo/internal/manifest/exohcl/hclgen"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api-config
+data:
+  LOG_LEVEL: debug
+  GREETING: "cost: $5"
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: pgdata
+spec:
+  accessModes: [ReadWriteOnce]
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: example/api:1.0
+          command: [node]
+          args: [server.js]
+          env:
+            - name: PORT
+              value: "8080"
+            - name: LEVEL
+              valueFrom:
+                configMapKeyRef:
+                  name: api-config
+                  key: LOG_LEVEL
+          envFrom:
+            - configMapRef:
+                name: api-config
+              prefix: CFG_
+          ports:
+            - name: http
+              containerPort: 8080
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  template:
+    metadata:
+      labels:
+        app: db
+    spec:
+      containers:
+        - name: postgres
+          image: postgres:14
+          volumeMounts:
+            - name: data
+              mountPath: /var/lib/postgresql/data
+      volumes:
+        - name: data
+          persistentVolumeClaim:
+            claimName: pgdata
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  selector:
+    app: api
+  ports:
+    - port: 80
+      targetPort: http
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: postgres
+spec:
+  selector:
+    app: db
+  ports:
+    - port: 5432
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+`
+
+func TestImport(t *testing.T) {
+	ctx := &exohcl.AnalysisContext{
+		Context: context.Background(),
+	}
+	importer := &Importer{ProjectName: "proj"}
+	f := importer.Import(ctx, []byte(testManifest))
+
+	var summaries []string
+	for _, diag := range ctx.Diagnostics {
+		assert.Equal(t, hcl.DiagWarning, diag.Severity)
+		summaries = append(summaries, diag.Summary)
+	}
+	assert.Equal(t, []string{
+		"unsupported feature: Kubernetes Ingress resources",
+		`Service name "postgres" is not resolvable`,
+	}, summaries)
+
+	var buf bytes.Buffer
+	_, err := hclgen.WriteTo(&buf, hclgen.FileFromStructure(f))
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(`
+exo = "0.1"
+components {
+  volume "pgdata" {
+    name = "proj_pgdata"
+  }
+  network "default" {
+    driver = "bridge"
+    name   = "proj_default"
+  }
+  container "api" {
+    command        = ["server.js"]
+    container_name = "proj_api_1"
+    entrypoint     = ["node"]
+    environment    = { CFG_GREETING = "cost: $5", CFG_LOG_LEVEL = "debug", LEVEL = "debug", PORT = "8080" }
+    image          = "example/api:1.0"
+    labels         = { "com.docker.compose.project" = "proj", "com.docker.compose.service" = "api" }
+    networks       = ["proj_default"]
+    ports          = ["80:8080"]
+    _ {
+      depends_on = ["default"]
+    }
+  }
+  container "db" {
+    container_name = "proj_db_1"
+    image          = "postgres:14"
+    labels         = { "com.docker.compose.project" = "proj", "com.docker.compose.service" = "db" }
+    networks       = ["proj_default"]
+    ports          = ["5432:5432"]
+    volumes        = ["pgdata:/var/lib/postgresql/data"]
+    _ {
+      depends_on = ["default", "pgdata"]
+    }
+  }
+}
+`), strings.TrimSpace(buf.String()))
+}
+
+func TestImportErrors(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"missing kind": `
+apiVersion: v1
+metadata:
+  name: x
+`,
+		"unknown config map": `
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: web
+      image: nginx
+      envFrom:
+        - configMapRef:
+            name: missing
+`,
+		"unknown volume": `
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: web
+      image: nginx
+      volumeMounts:
+        - name: data
+          mountPath: /data
+`,
+		"unmatched target port": `
+kind: Pod
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  containers:
+    - name: web
+      image: nginx
+---
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+    - port: 80
+      targetPort: http
+`,
+	} {
+		ctx := &exohcl.AnalysisContext{
+			Context: context.Background(),
+		}
+		importer := &Importer{ProjectName: "proj"}
+		importer.Import(ctx, []byte(manifest))
+		assert.True(t, ctx.Diagnostics.HasErrors(), name)
+	}
+}
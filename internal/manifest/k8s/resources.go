@@ -0,0 +1,139 @@
+package k8s
+
+import "gopkg.in/yaml.v3"
+
+// The subset of Kubernetes resource schemas that is understood by the
+// importer. See <https://kubernetes.io/docs/reference/kubernetes-api/>.
+
+type object struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   objectMeta `yaml:"metadata"`
+
+	// Source of the object, for decoding the remainder of the object and for
+	// diagnostic locations.
+	node *yaml.Node
+}
+
+type objectMeta struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type list struct {
+	Items []yaml.Node `yaml:"items"`
+}
+
+// Deployments, StatefulSets, DaemonSets, and ReplicaSets.
+type workload struct {
+	Spec struct {
+		Replicas *int        `yaml:"replicas"`
+		Template podTemplate `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+type podTemplate struct {
+	Metadata objectMeta `yaml:"metadata"`
+	Spec     podSpec    `yaml:"spec"`
+}
+
+type pod struct {
+	Spec podSpec `yaml:"spec"`
+}
+
+type podSpec struct {
+	Containers     []container `yaml:"containers"`
+	InitContainers []container `yaml:"initContainers"`
+	Volumes        []podVolume `yaml:"volumes"`
+}
+
+type container struct {
+	Name         string          `yaml:"name"`
+	Image        string          `yaml:"image"`
+	Command      []string        `yaml:"command"`
+	Args         []string        `yaml:"args"`
+	WorkingDir   string          `yaml:"workingDir"`
+	Env          []envVar        `yaml:"env"`
+	EnvFrom      []envFromSource `yaml:"envFrom"`
+	Ports        []containerPort `yaml:"ports"`
+	VolumeMounts []volumeMount   `yaml:"volumeMounts"`
+	Stdin        bool            `yaml:"stdin"`
+	TTY          bool            `yaml:"tty"`
+}
+
+type envVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value"`
+	ValueFrom *envVarSource `yaml:"valueFrom"`
+}
+
+type envVarSource struct {
+	ConfigMapKeyRef  *keySelector `yaml:"configMapKeyRef"`
+	SecretKeyRef     *keySelector `yaml:"secretKeyRef"`
+	FieldRef         *yaml.Node   `yaml:"fieldRef"`
+	ResourceFieldRef *yaml.Node   `yaml:"resourceFieldRef"`
+}
+
+type keySelector struct {
+	Name     string `yaml:"name"`
+	Key      string `yaml:"key"`
+	Optional bool   `yaml:"optional"`
+}
+
+type envFromSource struct {
+	Prefix       string             `yaml:"prefix"`
+	ConfigMapRef *objectRefSelector `yaml:"configMapRef"`
+	SecretRef    *objectRefSelector `yaml:"secretRef"`
+}
+
+type objectRefSelector struct {
+	Name     string `yaml:"name"`
+	Optional bool   `yaml:"optional"`
+}
+
+type containerPort struct {
+	Name          string `yaml:"name"`
+	ContainerPort int    `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol"`
+}
+
+type volumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath"`
+	ReadOnly  bool   `yaml:"readOnly"`
+}
+
+type podVolume struct {
+	Name                  string `yaml:"name"`
+	PersistentVolumeClaim *struct {
+		ClaimName string `yaml:"claimName"`
+		ReadOnly  bool   `yaml:"readOnly"`
+	} `yaml:"persistentVolumeClaim"`
+	EmptyDir *yaml.Node `yaml:"emptyDir"`
+	HostPath *struct {
+		Path string `yaml:"path"`
+	} `yaml:"hostPath"`
+	ConfigMap *yaml.Node `yaml:"configMap"`
+	Secret    *yaml.Node `yaml:"secret"`
+}
+
+type service struct {
+	Spec struct {
+		Type     string            `yaml:"type"`
+		Selector map[string]string `yaml:"selector"`
+		Ports    []servicePort     `yaml:"ports"`
+	} `yaml:"spec"`
+}
+
+type servicePort struct {
+	Name     string `yaml:"name"`
+	Protocol string `yaml:"protocol"`
+	Port     int    `yaml:"port"`
+	// Either a port number or the name of a container port.
+	TargetPort string `yaml:"targetPort"`
+}
+
+type configMap struct {
+	Data map[string]string `yaml:"data"`
+}
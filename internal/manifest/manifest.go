@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/deref/exo/internal/manifest/compose"
+	"github.com/deref/exo/internal/manifest/exocue"
 	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/deref/exo/internal/manifest/k8s"
 	"github.com/deref/exo/internal/manifest/procfile"
 	"github.com/deref/exo/internal/util/errutil"
 	"github.com/hashicorp/hcl/v2"
@@ -18,6 +20,8 @@ func GuessFormat(path string) string {
 	switch name {
 	case "exo.hcl":
 		return "exo"
+	case "exo.cue":
+		return "cue"
 	case "procfile":
 		return "procfile"
 	case "compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml":
@@ -26,6 +30,9 @@ func GuessFormat(path string) string {
 		if strings.HasPrefix(name, "procfile.") || strings.HasSuffix(name, ".procfile") {
 			return "procfile"
 		}
+		if strings.HasSuffix(name, ".k8s.yaml") || strings.HasSuffix(name, ".k8s.yml") {
+			return "k8s"
+		}
 		return ""
 	}
 }
@@ -64,10 +71,18 @@ func (l *Loader) Load(ctx *exohcl.AnalysisContext) (*exohcl.Manifest, error) {
 		importer = &compose.Importer{
 			ProjectName: l.WorkspaceName,
 		}
+	case "k8s":
+		importer = &k8s.Importer{
+			ProjectName: l.WorkspaceName,
+		}
 	case "exo":
 		importer = &exohcl.Importer{
 			Filename: l.Filename,
 		}
+	case "cue":
+		importer = &exocue.Importer{
+			Filename: l.Filename,
+		}
 	default:
 		return m, fmt.Errorf("unknown manifest format: %q", l.Format)
 	}
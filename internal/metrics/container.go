@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deref/exo/internal/providers/docker"
+	dockerclient "github.com/docker/docker/client"
+)
+
+// ReadContainerUsage reads the usage of all processes in a container. Fails if
+// the container is not running.
+func ReadContainerUsage(ctx context.Context, client *dockerclient.Client, containerID string) (Usage, error) {
+	var usage Usage
+	res, err := client.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return usage, err
+	}
+	defer res.Body.Close()
+	var stats docker.ContainerStats
+	if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
+		return usage, fmt.Errorf("decoding container stats: %w", err)
+	}
+	// Stopped containers report empty stats.
+	if stats.PIDsStats.Current == 0 {
+		return usage, errors.New("container is not running")
+	}
+	return containerUsage(&stats), nil
+}
+
+func containerUsage(stats *docker.ContainerStats) Usage {
+	usage := Usage{
+		CPU:           time.Duration(stats.CPUStats.CPUUsage.TotalUsage),
+		ResidentBytes: stats.MemoryStats.Usage,
+		HasNetwork:    true,
+		HasDisk:       true,
+	}
+	for _, network := range stats.Networks {
+		usage.NetworkReceiveBytes += uint64(network.RxBytes)
+		usage.NetworkTransmitBytes += uint64(network.TxBytes)
+	}
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		// Ops are capitalized with cgroup v1, but not with v2.
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			usage.DiskReadBytes += uint64(entry.Value)
+		case "write":
+			usage.DiskWriteBytes += uint64(entry.Value)
+		}
+	}
+	return usage
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	psprocess "github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessTable is a snapshot of the parent of each process, used to find the
+// descendants of a process. Servers started by scripts or package managers
+// often do their work in child processes.
+type ProcessTable struct {
+	children map[int32][]int32
+}
+
+func SnapshotProcesses(ctx context.Context) (*ProcessTable, error) {
+	procs, err := psprocess.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table := &ProcessTable{
+		children: make(map[int32][]int32),
+	}
+	for _, proc := range procs {
+		ppid, err := proc.PpidWithContext(ctx)
+		if err != nil {
+			// Exited since listing.
+			continue
+		}
+		table.children[ppid] = append(table.children[ppid], proc.Pid)
+	}
+	return table, nil
+}
+
+// Descendants returns the pids of the children of a process, their children,
+// and so on.
+func (table *ProcessTable) Descendants(pid int32) []int32 {
+	var res []int32
+	seen := map[int32]bool{pid: true}
+	queue := append([]int32(nil), table.children[pid]...)
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		res = append(res, pid)
+		queue = append(queue, table.children[pid]...)
+	}
+	return res
+}
+
+// ReadUsage sums the usage of a process and its descendants. Fails if the
+// process itself is not running.
+func (table *ProcessTable) ReadUsage(ctx context.Context, pid int) (Usage, error) {
+	var usage Usage
+	root, err := psprocess.NewProcessWithContext(ctx, int32(pid))
+	if err != nil {
+		return usage, err
+	}
+	// Disk counters may be unavailable, such as on macOS, or for processes
+	// owned by other users.
+	usage.HasDisk, err = addProcessUsage(ctx, &usage, root)
+	if err != nil {
+		return usage, err
+	}
+
+	for _, pid := range table.Descendants(root.Pid) {
+		proc, err := psprocess.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			continue
+		}
+		// Descendants may have exited since the snapshot.
+		_, _ = addProcessUsage(ctx, &usage, proc)
+	}
+	return usage, nil
+}
+
+// Adds the usage of a single process, reporting whether disk usage was known.
+func addProcessUsage(ctx context.Context, usage *Usage, proc *psprocess.Process) (hasDisk bool, err error) {
+	times, err := proc.TimesWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	mem, err := proc.MemoryInfoWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	usage.CPU += time.Duration((times.User + times.System) * float64(time.Second))
+	usage.ResidentBytes += mem.RSS
+	io, err := proc.IOCountersWithContext(ctx)
+	if err != nil {
+		return false, nil
+	}
+	usage.DiskReadBytes += io.ReadBytes
+	usage.DiskWriteBytes += io.WriteBytes
+	return true, nil
+}
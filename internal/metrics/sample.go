@@ -0,0 +1,163 @@
+// Package metrics samples the resource usage of processes and containers, so
+// that changes in usage can be observed over time.
+package metrics
+
+import (
+	"time"
+)
+
+// Usage is a reading of the resource usage of a process, including its
+// descendants. Other than memory, usage is counted cumulatively over the
+// lifetime of the process.
+type Usage struct {
+	CPU           time.Duration
+	ResidentBytes uint64
+	// Network usage is only known for containers, which have their own
+	// network interfaces.
+	HasNetwork           bool
+	NetworkReceiveBytes  uint64
+	NetworkTransmitBytes uint64
+	HasDisk              bool
+	DiskReadBytes        uint64
+	DiskWriteBytes       uint64
+}
+
+type Reading struct {
+	Time time.Time
+	Usage
+}
+
+// Sample is the resource usage of a process over a span of time ending at the
+// timestamp. Throughputs are in bytes per second, and are nil if unknown.
+type Sample struct {
+	Timestamp           time.Time
+	CPUPercent          float64
+	ResidentBytes       uint64
+	NetworkReceiveRate  *float64
+	NetworkTransmitRate *float64
+	DiskReadRate        *float64
+	DiskWriteRate       *float64
+}
+
+// Between computes a sample from two consecutive readings of the same process.
+// Counters may decrease when descendant processes exit, in which case no usage
+// is attributed to the span.
+func Between(prev, cur Reading) Sample {
+	sample := Sample{
+		Timestamp:     cur.Time,
+		ResidentBytes: cur.ResidentBytes,
+	}
+	elapsed := cur.Time.Sub(prev.Time)
+	if elapsed <= 0 {
+		return sample
+	}
+	if cur.CPU > prev.CPU {
+		sample.CPUPercent = 100 * float64(cur.CPU-prev.CPU) / float64(elapsed)
+	}
+	rate := func(prev, cur uint64) *float64 {
+		var r float64
+		if cur > prev {
+			r = float64(cur-prev) / elapsed.Seconds()
+		}
+		return &r
+	}
+	if prev.HasNetwork && cur.HasNetwork {
+		sample.NetworkReceiveRate = rate(prev.NetworkReceiveBytes, cur.NetworkReceiveBytes)
+		sample.NetworkTransmitRate = rate(prev.NetworkTransmitBytes, cur.NetworkTransmitBytes)
+	}
+	if prev.HasDisk && cur.HasDisk {
+		sample.DiskReadRate = rate(prev.DiskReadBytes, cur.DiskReadBytes)
+		sample.DiskWriteRate = rate(prev.DiskWriteBytes, cur.DiskWriteBytes)
+	}
+	return sample
+}
+
+// Combine adds together consecutive samples with equal timestamps, such as
+// those taken of several processes that make up one component.
+func Combine(samples []Sample) []Sample {
+	var res []Sample
+	for _, sample := range samples {
+		n := len(res)
+		if n == 0 || !res[n-1].Timestamp.Equal(sample.Timestamp) {
+			res = append(res, sample)
+			continue
+		}
+		acc := &res[n-1]
+		acc.CPUPercent += sample.CPUPercent
+		acc.ResidentBytes += sample.ResidentBytes
+		for i, rate := range sample.rates() {
+			addRate(acc.rates()[i], *rate)
+		}
+	}
+	return res
+}
+
+func addRate(acc **float64, x *float64) {
+	if x == nil {
+		return
+	}
+	sum := *x
+	if *acc != nil {
+		sum += **acc
+	}
+	*acc = &sum
+}
+
+// Downsample combines chronologically ordered samples in to buckets of the
+// given width, aligned to multiples of the width. Each bucket is timestamped
+// with its start. CPU and throughputs are averaged, but the peak memory usage
+// is kept, so that spikes are not hidden.
+func Downsample(samples []Sample, width time.Duration) []Sample {
+	if width <= 0 {
+		return samples
+	}
+	var res []Sample
+	var n int
+	counts := make([]int, 4)
+	flush := func() {
+		if n == 0 {
+			return
+		}
+		acc := &res[len(res)-1]
+		acc.CPUPercent /= float64(n)
+		for i, rate := range acc.rates() {
+			if *rate != nil {
+				**rate /= float64(counts[i])
+			}
+		}
+	}
+	for _, sample := range samples {
+		start := sample.Timestamp.Truncate(width)
+		if len(res) == 0 || !res[len(res)-1].Timestamp.Equal(start) {
+			flush()
+			res = append(res, Sample{Timestamp: start})
+			n = 0
+			for i := range counts {
+				counts[i] = 0
+			}
+		}
+		acc := &res[len(res)-1]
+		n++
+		acc.CPUPercent += sample.CPUPercent
+		if sample.ResidentBytes > acc.ResidentBytes {
+			acc.ResidentBytes = sample.ResidentBytes
+		}
+		for i, rate := range sample.rates() {
+			if *rate != nil {
+				addRate(acc.rates()[i], *rate)
+				counts[i]++
+			}
+		}
+	}
+	flush()
+	return res
+}
+
+func (sample *Sample) rates() []**float64 {
+	return []**float64{
+		&sample.NetworkReceiveRate,
+		&sample.NetworkTransmitRate,
+		&sample.DiskReadRate,
+		&sample.DiskWriteRate,
+	}
+}
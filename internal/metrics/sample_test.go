@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestBetween(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	prev := Reading{
+		Time: t0,
+		Usage: Usage{
+			CPU:           time.Second,
+			ResidentBytes: 100,
+			HasDisk:       true,
+			DiskReadBytes: 1000,
+		},
+	}
+	cur := Reading{
+		Time: t0.Add(2 * time.Second),
+		Usage: Usage{
+			CPU:           2 * time.Second,
+			ResidentBytes: 200,
+			HasDisk:       true,
+			// Decreases when a child process exits.
+			DiskReadBytes:  500,
+			DiskWriteBytes: 4000,
+		},
+	}
+	assert.Equal(t, Sample{
+		Timestamp:     cur.Time,
+		CPUPercent:    50,
+		ResidentBytes: 200,
+		DiskReadRate:  floatPtr(0),
+		DiskWriteRate: floatPtr(2000),
+	}, Between(prev, cur))
+}
+
+func TestCombine(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+	assert.Equal(t, []Sample{
+		{Timestamp: t0, CPUPercent: 30, ResidentBytes: 3, NetworkReceiveRate: floatPtr(5)},
+		{Timestamp: t1, CPUPercent: 5, ResidentBytes: 1},
+	}, Combine([]Sample{
+		{Timestamp: t0, CPUPercent: 10, ResidentBytes: 1},
+		{Timestamp: t0, CPUPercent: 20, ResidentBytes: 2, NetworkReceiveRate: floatPtr(5)},
+		{Timestamp: t1, CPUPercent: 5, ResidentBytes: 1},
+	}))
+}
+
+func TestDownsample(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return t0.Add(time.Duration(seconds) * time.Second)
+	}
+	assert.Equal(t, []Sample{
+		{Timestamp: at(0), CPUPercent: 20, ResidentBytes: 300, DiskReadRate: floatPtr(4)},
+		{Timestamp: at(10), CPUPercent: 50, ResidentBytes: 100},
+	}, Downsample([]Sample{
+		{Timestamp: at(1), CPUPercent: 10, ResidentBytes: 300, DiskReadRate: floatPtr(6)},
+		{Timestamp: at(5), CPUPercent: 20, ResidentBytes: 200},
+		{Timestamp: at(9), CPUPercent: 30, ResidentBytes: 100, DiskReadRate: floatPtr(2)},
+		{Timestamp: at(12), CPUPercent: 50, ResidentBytes: 100},
+	}, 10*time.Second))
+}
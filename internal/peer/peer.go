@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/config"
 	"github.com/deref/exo/internal/resolvers"
 	"github.com/deref/exo/internal/util/errutil"
 	"github.com/deref/exo/internal/util/jsonutil"
@@ -19,6 +21,13 @@ type Peer struct {
 	VarDir      string
 	GUIEndpoint string
 	Debug       bool
+	// See resolvers.RootResolver.
+	Daemon   bool
+	Plugins  []config.PluginConfig
+	LogSinks []config.LogSinkConfig
+	// See resolvers.RootResolver.
+	MetricsInterval time.Duration
+	MetricsMaxAge   time.Duration
 
 	root   *resolvers.RootResolver
 	schema *graphql.Schema
@@ -32,6 +41,12 @@ func (p *Peer) Init(ctx context.Context) error {
 		SystemLog:   p.SystemLog,
 		GUIEndpoint: p.GUIEndpoint,
 		Service:     p,
+		Daemon:      p.Daemon,
+		Plugins:     p.Plugins,
+		LogSinks:    p.LogSinks,
+
+		MetricsInterval: p.MetricsInterval,
+		MetricsMaxAge:   p.MetricsMaxAge,
 	}
 	if err := p.root.Init(ctx); err != nil {
 		return err
@@ -0,0 +1,145 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/deref/exo/internal/providers/docker/compose"
+	"github.com/deref/exo/internal/util/pathutil"
+	"github.com/docker/docker/api/types/mount"
+)
+
+const defaultFileReferenceMode = 0444
+
+// Host directory that holds the secrets and configs mounted in to the
+// component's container.
+func (c *Container) fileReferenceDir() string {
+	return filepath.Join(os.TempDir(), "exo-files", c.ComponentID)
+}
+
+// Writes the service's secrets and configs to the host and returns read-only
+// bind mounts of them. Unlike copies in the container's writable layer, the
+// mounts are not hidden by tmpfs mounts of parent directories, such as /run.
+// Docker-Compose bind mounts the source files directly for standalone
+// containers, ignoring uid, gid, and mode. Writing the files allows the mode
+// to be honored, as well as the uid and gid where exo is permitted to chown.
+func (c *Container) fileReferenceMounts(spec *Spec) ([]mount.Mount, error) {
+	dir := c.fileReferenceDir()
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("removing previous files: %w", err)
+	}
+	if len(spec.Secrets) == 0 && len(spec.Configs) == 0 {
+		return nil, nil
+	}
+	mounts := make([]mount.Mount, 0, len(spec.Secrets)+len(spec.Configs))
+	for _, secret := range spec.Secrets {
+		mnt, err := c.writeFileReference(dir, "/run/secrets", secret)
+		if err != nil {
+			return nil, fmt.Errorf("secret %q: %w", secret.Source.Value, err)
+		}
+		mounts = append(mounts, mnt)
+	}
+	for _, config := range spec.Configs {
+		mnt, err := c.writeFileReference(dir, "/", config)
+		if err != nil {
+			return nil, fmt.Errorf("config %q: %w", config.Source.Value, err)
+		}
+		mounts = append(mounts, mnt)
+	}
+	return mounts, nil
+}
+
+func (c *Container) removeFileReferences() {
+	if err := os.RemoveAll(c.fileReferenceDir()); err != nil {
+		c.Logger.Infof("removing secrets and configs: %v", err)
+	}
+}
+
+// Writes the referenced file beneath hostDir, at the same relative path as it
+// is mounted in the container.
+func (c *Container) writeFileReference(hostDir string, targetDir string, ref compose.FileReference) (mount.Mount, error) {
+	var mnt mount.Mount
+	content, err := c.readFileReference(ref)
+	if err != nil {
+		return mnt, err
+	}
+
+	target := ref.Target.Value
+	if target == "" {
+		target = ref.Source.Value
+	}
+	if !path.IsAbs(target) {
+		target = path.Join(targetDir, target)
+	}
+
+	uid, err := parseID(ref.UID.Value)
+	if err != nil {
+		return mnt, fmt.Errorf("invalid uid: %w", err)
+	}
+	gid, err := parseID(ref.GID.Value)
+	if err != nil {
+		return mnt, fmt.Errorf("invalid gid: %w", err)
+	}
+	mode := os.FileMode(defaultFileReferenceMode)
+	if ref.Mode != nil {
+		mode = os.FileMode(ref.Mode.Value)
+	}
+
+	hostPath := filepath.Join(hostDir, filepath.FromSlash(strings.TrimPrefix(target, "/")))
+	if err := os.MkdirAll(filepath.Dir(hostPath), 0700); err != nil {
+		return mnt, fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(hostPath, content, 0600); err != nil {
+		return mnt, err
+	}
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(hostPath, uid, gid); err != nil {
+			c.Logger.Infof("cannot set owner of %s: %v", target, err)
+		}
+	}
+	// Chmod after writing, since the umask applies to new files.
+	if err := os.Chmod(hostPath, mode); err != nil {
+		return mnt, err
+	}
+
+	return mount.Mount{
+		Type:     mount.TypeBind,
+		Source:   hostPath,
+		Target:   target,
+		ReadOnly: true,
+	}, nil
+}
+
+func (c *Container) readFileReference(ref compose.FileReference) ([]byte, error) {
+	switch {
+	case ref.File.Value != "":
+		filePath := ref.File.Value
+		if !path.IsAbs(filePath) {
+			filePath = path.Join(c.WorkspaceRoot, filePath)
+		}
+		if !pathutil.HasPathPrefix(filePath, c.WorkspaceRoot) {
+			return nil, fmt.Errorf("file %s is not contained within the workspace", filePath)
+		}
+		return os.ReadFile(filePath)
+	case ref.Environment.Value != "":
+		value, ok := c.WorkspaceEnvironment[ref.Environment.Value]
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", ref.Environment.Value)
+		}
+		return []byte(value), nil
+	default:
+		return nil, fmt.Errorf("expected file or environment source")
+	}
+}
+
+// Returns -1 for an unspecified id, which os.Chown leaves unchanged.
+func parseID(s string) (int, error) {
+	if s == "" {
+		return -1, nil
+	}
+	return strconv.Atoi(s)
+}
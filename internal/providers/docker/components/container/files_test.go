@@ -0,0 +1,119 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/deref/exo/internal/providers/core"
+	"github.com/deref/exo/internal/providers/docker"
+	"github.com/deref/exo/internal/providers/docker/compose"
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFileReference(t *testing.T) {
+	workspaceRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspaceRoot, "db_password.txt"), []byte("hunter2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c := &Container{
+		ComponentBase: docker.ComponentBase{
+			ComponentBase: core.ComponentBase{
+				WorkspaceRoot: workspaceRoot,
+				WorkspaceEnvironment: map[string]string{
+					"API_TOKEN": "abc123",
+				},
+				Logger: &logging.NopLogger{},
+			},
+		},
+	}
+
+	hostDir := t.TempDir()
+
+	type entry struct {
+		Target  string
+		Mode    os.FileMode
+		Uid     int
+		Gid     int
+		Content string
+	}
+	write := func(targetDir string, ref compose.FileReferenceLongForm) entry {
+		mnt, err := c.writeFileReference(hostDir, targetDir, compose.FileReference{
+			FileReferenceLongForm: ref,
+		})
+		if !assert.NoError(t, err) {
+			return entry{}
+		}
+		assert.Equal(t, mount.TypeBind, mnt.Type)
+		assert.True(t, mnt.ReadOnly)
+		assert.True(t, strings.HasPrefix(mnt.Source, hostDir))
+		info, err := os.Stat(mnt.Source)
+		if !assert.NoError(t, err) {
+			return entry{}
+		}
+		stat := info.Sys().(*syscall.Stat_t)
+		content, err := os.ReadFile(mnt.Source)
+		assert.NoError(t, err)
+		return entry{
+			Target:  mnt.Target,
+			Mode:    info.Mode().Perm(),
+			Uid:     int(stat.Uid),
+			Gid:     int(stat.Gid),
+			Content: string(content),
+		}
+	}
+	uid, gid := os.Getuid(), os.Getgid()
+
+	assert.Equal(t, entry{
+		Target:  "/run/secrets/db_password",
+		Mode:    0444,
+		Uid:     uid,
+		Gid:     gid,
+		Content: "hunter2",
+	}, write("/run/secrets", compose.FileReferenceLongForm{
+		Source: compose.MakeString("db_password"),
+		File:   compose.MakeString("db_password.txt"),
+	}))
+
+	if uid == 0 {
+		// Only root may chown.
+		assert.Equal(t, entry{
+			Target:  "/run/secrets/token",
+			Mode:    0400,
+			Uid:     103,
+			Gid:     104,
+			Content: "abc123",
+		}, write("/run/secrets", compose.FileReferenceLongForm{
+			Source:      compose.MakeString("api_token"),
+			Target:      compose.MakeString("token"),
+			UID:         compose.MakeString("103"),
+			GID:         compose.MakeString("104"),
+			Mode:        &compose.FileMode{Value: 0400},
+			Environment: compose.MakeString("API_TOKEN"),
+		}))
+	}
+
+	assert.Equal(t, entry{
+		Target:  "/etc/app.conf",
+		Mode:    0444,
+		Uid:     uid,
+		Gid:     gid,
+		Content: "hunter2",
+	}, write("/", compose.FileReferenceLongForm{
+		Source: compose.MakeString("app_config"),
+		Target: compose.MakeString("/etc/app.conf"),
+		File:   compose.MakeString("db_password.txt"),
+	}))
+
+	_, err := c.writeFileReference(hostDir, "/run/secrets", compose.FileReference{
+		FileReferenceLongForm: compose.FileReferenceLongForm{
+			Source:      compose.MakeString("missing"),
+			Environment: compose.MakeString("MISSING"),
+		},
+	})
+	assert.Error(t, err)
+}
@@ -14,6 +14,7 @@ import (
 	core "github.com/deref/exo/internal/core/api"
 	"github.com/deref/exo/internal/providers/docker/components/image"
 	"github.com/deref/exo/internal/providers/docker/compose"
+	"github.com/deref/exo/internal/syslogd"
 	"github.com/deref/exo/internal/util/pathutil"
 	"github.com/deref/exo/internal/util/yamlutil"
 	"github.com/docker/docker/api/types"
@@ -81,8 +82,10 @@ func (c *Container) Initialize(ctx context.Context, input *core.InitializeInput)
 		return nil, fmt.Errorf("creating container: %w", err)
 	}
 
-	if err := c.start(ctx); err != nil {
-		c.Logger.Infof("starting container %q: %v", c.State.ContainerID, err)
+	if !input.Stopped {
+		if err := c.start(ctx); err != nil {
+			c.Logger.Infof("starting container %q: %v", c.State.ContainerID, err)
+		}
 	}
 
 	return &core.InitializeOutput{}, nil
@@ -200,11 +203,15 @@ func (c *Container) create(ctx context.Context, spec *Spec) error {
 	}
 
 	logCfg := container.LogConfig{}
-	if spec.Logging.Driver.Value == "" && len(spec.Logging.Options.Items) == 0 {
+	switch {
+	case spec.Logging.Driver.Value != "" || len(spec.Logging.Options.Items) > 0:
+		logCfg.Type = spec.Logging.Driver.Value
+		logCfg.Config = spec.Logging.Options.Map()
+	case c.SyslogPort != 0:
 		// No logging configuration specified, so default to logging to exo's
-		// syslog service.
+		// syslog service. Without one, Docker's default logging applies.
 		logCfg.Type = "syslog"
-		syslogHost := "localhost"
+		syslogHost := syslogd.LoopbackHost
 		// TODO: Find an OS-agnostic way to figure out the "gateway-host" name as
 		// reachable from the dockerd process.
 		if runtime.GOOS == "darwin" || strings.Contains(dockerInfo.KernelVersion, "microsoft") {
@@ -216,9 +223,6 @@ func (c *Container) create(ctx context.Context, spec *Spec) error {
 			"tag":             c.ComponentID,
 			"syslog-format":   "rfc5424micro",
 		}
-	} else {
-		logCfg.Type = spec.Logging.Driver.Value
-		logCfg.Config = spec.Logging.Options.Map()
 	}
 
 	blkioWeightDevice := make([]*blkiodev.WeightDevice, len(spec.BlkioConfig.WeightDevice))
@@ -236,7 +240,6 @@ func (c *Container) create(ctx context.Context, spec *Spec) error {
 		LogConfig: logCfg,
 		//NetworkMode     NetworkMode   // Network mode to use for the container
 		PortBindings: make(nat.PortMap),
-		// TODO: Potentially inherit from deploy's restart_policy.
 		RestartPolicy: container.RestartPolicy{
 			Name: spec.Restart.Value,
 		},
@@ -311,6 +314,12 @@ func (c *Container) create(ctx context.Context, spec *Spec) error {
 		Init: spec.Init.Ptr(),
 	}
 
+	if spec.Deploy != nil {
+		if err := applyDeploy(hostCfg, *spec.Deploy); err != nil {
+			return err
+		}
+	}
+
 	if hostCfg.IpcMode, err = c.parseIPCMode(spec.IPC.Value); err != nil {
 		return err
 	}
@@ -354,6 +363,11 @@ func (c *Container) create(ctx context.Context, spec *Spec) error {
 		}
 		hostCfg.Mounts[i] = mnt
 	}
+	fileMounts, err := c.fileReferenceMounts(spec)
+	if err != nil {
+		return fmt.Errorf("writing secrets and configs: %w", err)
+	}
+	hostCfg.Mounts = append(hostCfg.Mounts, fileMounts...)
 
 	for _, mapping := range spec.Ports {
 		targetLow, targetHigh := int(mapping.Target.Min), int(mapping.Target.Max)
@@ -479,6 +493,7 @@ func (c *Container) Dispose(ctx context.Context, input *core.DisposeInput) (*cor
 		return nil, err
 	}
 	c.State.ContainerID = ""
+	c.removeFileReferences()
 	return &core.DisposeOutput{}, nil
 }
 
@@ -522,6 +537,42 @@ func (c *Container) endpointSettings(sn compose.ServiceNetwork, spec *Spec) *net
 	return es
 }
 
+// Applies the subset of deploy settings that is meaningful for a standalone
+// container. Deploy resources take precedence over the equivalent legacy
+// service-level settings, matching docker-compose, except for a CPU limit,
+// which Docker cannot combine with a CPU quota or period.
+func applyDeploy(hostCfg *container.HostConfig, deploy compose.Deploy) error {
+	if rp := deploy.RestartPolicy; rp != nil && hostCfg.RestartPolicy.Name == "" {
+		hostCfg.RestartPolicy.Name = rp.DockerName()
+		// Docker only supports a retry count for the on-failure policy. Delay and
+		// window have no standalone container equivalent.
+		if rp.MaxAttempts != nil && hostCfg.RestartPolicy.Name == "on-failure" {
+			hostCfg.RestartPolicy.MaximumRetryCount = rp.MaxAttempts.Int()
+		}
+	}
+
+	limits := deploy.Resources.Limits
+	if limits.CPUs != nil {
+		if hostCfg.CPUQuota != 0 || hostCfg.CPUPeriod != 0 {
+			return errors.New("deploy.resources.limits.cpus cannot be combined with cpu_quota or cpu_period")
+		}
+		hostCfg.NanoCPUs = int64(limits.CPUs.Value * 1e9)
+	}
+	if limits.Memory.Expression != "" {
+		hostCfg.Memory = limits.Memory.Int64()
+	}
+	if limits.Pids != nil {
+		hostCfg.PidsLimit = limits.Pids.Int64Ptr()
+	}
+
+	// CPU reservations are only honored by swarm scheduling.
+	reservations := deploy.Resources.Reservations
+	if reservations.Memory.Expression != "" {
+		hostCfg.MemoryReservation = reservations.Memory.Int64()
+	}
+	return nil
+}
+
 func convertThrottleDevice(in []compose.ThrottleDevice) []*blkiodev.ThrottleDevice {
 	if in == nil {
 		return nil
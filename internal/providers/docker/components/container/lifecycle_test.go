@@ -0,0 +1,33 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/deref/exo/internal/providers/docker/compose"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDeployCPUs(t *testing.T) {
+	cpus := compose.MakeFloat(1.5)
+	deploy := compose.Deploy{
+		Resources: compose.Resources{
+			Limits: compose.ResourceLimits{
+				CPUs: &cpus,
+			},
+		},
+	}
+
+	var hostCfg container.HostConfig
+	if assert.NoError(t, applyDeploy(&hostCfg, deploy)) {
+		assert.Equal(t, int64(1.5e9), hostCfg.NanoCPUs)
+	}
+
+	hostCfg = container.HostConfig{}
+	hostCfg.CPUQuota = 50000
+	assert.Error(t, applyDeploy(&hostCfg, deploy))
+
+	hostCfg = container.HostConfig{}
+	hostCfg.CPUPeriod = 100000
+	assert.Error(t, applyDeploy(&hostCfg, deploy))
+}
@@ -17,7 +17,7 @@ func (c *Container) Start(ctx context.Context, input *core.StartInput) (*core.St
 
 func (c *Container) start(ctx context.Context) error {
 	err := c.Docker.ContainerStart(ctx, c.State.ContainerID, types.ContainerStartOptions{})
-	if err != nil {
+	if err == nil {
 		c.State.Running = true
 	}
 	return err
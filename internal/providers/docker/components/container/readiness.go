@@ -0,0 +1,62 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	core "github.com/deref/exo/internal/core/api"
+	"github.com/docker/docker/api/types"
+)
+
+var _ core.Readiness = (*Container)(nil)
+
+const readinessPollInterval = 500 * time.Millisecond
+const DefaultReadinessTimeout = 2 * time.Minute
+
+// AwaitReady blocks until the container is running. If the container has a
+// healthcheck, it must additionally report itself as healthy.
+func (c *Container) AwaitReady(ctx context.Context, input *core.AwaitReadyInput) (*core.AwaitReadyOutput, error) {
+	if err := c.awaitReady(ctx); err != nil {
+		return nil, err
+	}
+	return &core.AwaitReadyOutput{}, nil
+}
+
+func (c *Container) awaitReady(ctx context.Context) error {
+	if c.State.ContainerID == "" {
+		return errors.New("container has not been created")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultReadinessTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		inspection, err := c.Docker.ContainerInspect(ctx, c.State.ContainerID)
+		if err != nil {
+			return fmt.Errorf("inspecting container: %w", err)
+		}
+		state := inspection.State
+		if !state.Running && !state.Restarting {
+			return fmt.Errorf("container is not running: %s", state.Status)
+		}
+		health := state.Health
+		switch {
+		case health == nil && state.Running:
+			return nil
+		case health != nil && health.Status == types.Healthy:
+			return nil
+		case health != nil && health.Status == types.Unhealthy:
+			return errors.New("container is unhealthy")
+		}
+		select {
+		case <-ctx.Done():
+			return errors.New("timed out awaiting container health")
+		case <-ticker.C:
+		}
+	}
+}
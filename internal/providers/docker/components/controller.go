@@ -0,0 +1,287 @@
+// Package components adapts the Docker components that implement Compose
+// services, volumes, and networks to the resource controller interface, so
+// that compose manifests can be applied to stacks.
+package components
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/deref/exo/internal/api"
+	core "github.com/deref/exo/internal/core/api"
+	provcore "github.com/deref/exo/internal/providers/core"
+	"github.com/deref/exo/internal/providers/docker"
+	"github.com/deref/exo/internal/providers/docker/components/container"
+	"github.com/deref/exo/internal/providers/docker/components/network"
+	"github.com/deref/exo/internal/providers/docker/components/volume"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/deref/exo/sdk"
+	dockerclient "github.com/docker/docker/client"
+)
+
+// Component types produced by importing compose manifests. Manifests refer to
+// these types by their aliases "container", "volume", and "network".
+const (
+	ContainerType = "deref.io/docker/compose/container"
+	VolumeType    = "deref.io/docker/compose/volume"
+	NetworkType   = "deref.io/docker/compose/network"
+)
+
+func NewContainerController(svc api.Service, client *dockerclient.Client) *sdk.ResourceComponentController {
+	return sdk.NewResourceComponentController[Model](svc, &Controller{
+		Service: svc,
+		Docker:  client,
+		New: func(base docker.ComponentBase) Component {
+			return &container.Container{ComponentBase: base}
+		},
+	})
+}
+
+func NewVolumeController(svc api.Service, client *dockerclient.Client) *sdk.ResourceComponentController {
+	return sdk.NewResourceComponentController[Model](svc, &Controller{
+		Service: svc,
+		Docker:  client,
+		New: func(base docker.ComponentBase) Component {
+			return &volume.Volume{ComponentBase: base}
+		},
+	})
+}
+
+func NewNetworkController(svc api.Service, client *dockerclient.Client) *sdk.ResourceComponentController {
+	return sdk.NewResourceComponentController[Model](svc, &Controller{
+		Service: svc,
+		Docker:  client,
+		New: func(base docker.ComponentBase) Component {
+			return &network.Network{ComponentBase: base}
+		},
+	})
+}
+
+// Implemented by the container, volume, and network components.
+type Component interface {
+	core.Lifecycle
+	InitResource() error
+	MarshalState() (string, error)
+}
+
+type Controller struct {
+	Service api.Service
+	Docker  *dockerclient.Client
+	New     func(base docker.ComponentBase) Component
+}
+
+// The model of a compose resource is its compose spec, extended with the
+// component's context and state under the reserved "x-exo" key. Compose
+// ignores keys with an "x-" prefix.
+type Model struct {
+	Spec JSONObject
+	Exo  Extension
+}
+
+type Extension struct {
+	ComponentID   string            `json:"componentId,omitempty"`
+	ComponentName string            `json:"componentName,omitempty"`
+	StackID       string            `json:"stackId,omitempty"`
+	WorkspaceRoot string            `json:"workspaceRoot,omitempty"`
+	Environment   map[string]string `json:"environment,omitempty"`
+	State         RawJSON           `json:"state,omitempty"`
+}
+
+const extensionKey = "x-exo"
+
+func (m Model) MarshalJSON() ([]byte, error) {
+	obj := make(JSONObject, len(m.Spec)+1)
+	for k, v := range m.Spec {
+		obj[k] = v
+	}
+	obj[extensionKey] = m.Exo
+	return json.Marshal(obj)
+}
+
+func (m *Model) UnmarshalJSON(bs []byte) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &obj); err != nil {
+		return err
+	}
+	m.Exo = Extension{}
+	if ext, ok := obj[extensionKey]; ok {
+		if err := json.Unmarshal(ext, &m.Exo); err != nil {
+			return fmt.Errorf("unmarshalling %s: %w", extensionKey, err)
+		}
+		delete(obj, extensionKey)
+	}
+	m.Spec = make(JSONObject, len(obj))
+	for k, raw := range obj {
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		m.Spec[k] = v
+	}
+	return nil
+}
+
+// Returns the spec as understood by the component, which accepts YAML, a
+// superset of JSON.
+func (m *Model) specString() (string, error) {
+	bs, err := json.Marshal(m.Spec)
+	return string(bs), err
+}
+
+// Resolves the context of the component that owns the resource. The
+// underlying components interpolate their specs with the component's
+// environment and resolve relative paths against the workspace root.
+func (ctrl *Controller) resolveContext(ctx context.Context, cfg *sdk.ResourceConfig, m *Model) error {
+	var q struct {
+		Resource *struct {
+			Component *struct {
+				ID          string
+				Name        string
+				Environment struct {
+					AsMap JSONObject
+				}
+				Stack struct {
+					ID        string
+					Workspace *struct {
+						Root string
+					}
+				}
+			}
+		} `graphql:"resourceById(id: $id)"`
+	}
+	if err := api.Query(ctx, ctrl.Service, &q, map[string]any{
+		"id": cfg.ID,
+	}); err != nil {
+		return fmt.Errorf("querying resource: %w", err)
+	}
+	if q.Resource == nil || q.Resource.Component == nil {
+		return fmt.Errorf("resource %q is not owned by a component", cfg.ID)
+	}
+	component := q.Resource.Component
+	m.Exo.ComponentID = component.ID
+	m.Exo.ComponentName = component.Name
+	m.Exo.StackID = component.Stack.ID
+	m.Exo.WorkspaceRoot = ""
+	if component.Stack.Workspace != nil {
+		m.Exo.WorkspaceRoot = component.Stack.Workspace.Root
+	}
+	m.Exo.Environment = make(map[string]string, len(component.Environment.AsMap))
+	for k, v := range component.Environment.AsMap {
+		if s, ok := v.(string); ok {
+			m.Exo.Environment[k] = s
+		}
+	}
+	return nil
+}
+
+// Constructs the underlying component from the model, then invokes f. Any
+// changes to the component's state are saved back to the model.
+func (ctrl *Controller) withComponent(ctx context.Context, m *Model, f func(Component) error) error {
+	var state string
+	if len(m.Exo.State) > 0 {
+		state = string(m.Exo.State)
+	}
+	component := ctrl.New(docker.ComponentBase{
+		ComponentBase: provcore.ComponentBase{
+			ComponentID:          m.Exo.ComponentID,
+			ComponentName:        m.Exo.ComponentName,
+			ComponentState:       state,
+			WorkspaceID:          m.Exo.StackID,
+			WorkspaceRoot:        m.Exo.WorkspaceRoot,
+			WorkspaceEnvironment: m.Exo.Environment,
+			Logger:               logging.CurrentLogger(ctx),
+		},
+		Docker: ctrl.Docker,
+	})
+	if err := component.InitResource(); err != nil {
+		return err
+	}
+	err := f(component)
+	state, marshalErr := component.MarshalState()
+	if marshalErr != nil {
+		return fmt.Errorf("marshalling state: %w", marshalErr)
+	}
+	m.Exo.State = RawJSON(state)
+	return err
+}
+
+func (ctrl *Controller) IdentifyResource(ctx context.Context, cfg *sdk.ResourceConfig, m *Model) (string, error) {
+	// Not identifiable, since the underlying components do not expose the IDs
+	// of their Docker objects uniformly.
+	return "", nil
+}
+
+func (ctrl *Controller) CreateResource(ctx context.Context, cfg *sdk.ResourceConfig, m *Model) error {
+	if err := ctrl.resolveContext(ctx, cfg, m); err != nil {
+		return err
+	}
+	spec, err := m.specString()
+	if err != nil {
+		return fmt.Errorf("marshalling spec: %w", err)
+	}
+	return ctrl.withComponent(ctx, m, func(component Component) error {
+		_, err := component.Initialize(ctx, &core.InitializeInput{Spec: spec})
+		return err
+	})
+}
+
+func (ctrl *Controller) ReadResource(ctx context.Context, cfg *sdk.ResourceConfig, m *Model) error {
+	spec, err := m.specString()
+	if err != nil {
+		return fmt.Errorf("marshalling spec: %w", err)
+	}
+	return ctrl.withComponent(ctx, m, func(component Component) error {
+		_, err := component.Refresh(ctx, &core.RefreshInput{Spec: spec})
+		return err
+	})
+}
+
+// Changes to the spec are applied by replacing the underlying Docker objects,
+// as with Compose.
+func (ctrl *Controller) UpdateResource(ctx context.Context, cfg *sdk.ResourceConfig, prev *Model, next *Model) error {
+	next.Exo = prev.Exo
+	prevSpec, err := prev.specString()
+	if err != nil {
+		return fmt.Errorf("marshalling previous spec: %w", err)
+	}
+	nextSpec, err := next.specString()
+	if err != nil {
+		return fmt.Errorf("marshalling spec: %w", err)
+	}
+	if prevSpec == nextSpec {
+		// Start a container that was shut down. Other components have nothing to
+		// start.
+		return ctrl.withComponent(ctx, next, func(component Component) error {
+			process, ok := component.(core.Process)
+			if !ok {
+				return nil
+			}
+			_, err := process.Start(ctx, &core.StartInput{})
+			return err
+		})
+	}
+	if err := ctrl.DeleteResource(ctx, cfg, next); err != nil {
+		return fmt.Errorf("disposing previous: %w", err)
+	}
+	return ctrl.CreateResource(ctx, cfg, next)
+}
+
+func (ctrl *Controller) ShutdownResource(ctx context.Context, cfg *sdk.ResourceConfig, m *Model) error {
+	return ctrl.withComponent(ctx, m, func(component Component) error {
+		process, ok := component.(core.Process)
+		if !ok {
+			return nil
+		}
+		_, err := process.Stop(ctx, &core.StopInput{})
+		return err
+	})
+}
+
+func (ctrl *Controller) DeleteResource(ctx context.Context, cfg *sdk.ResourceConfig, m *Model) error {
+	return ctrl.withComponent(ctx, m, func(component Component) error {
+		_, err := component.Dispose(ctx, &core.DisposeInput{})
+		return err
+	})
+}
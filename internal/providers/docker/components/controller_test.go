@@ -0,0 +1,44 @@
+package components
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelRoundTrip(t *testing.T) {
+	var m Model
+	if !assert.NoError(t, json.Unmarshal([]byte(`{"image": "redis", "ports": ["6379:6379"]}`), &m)) {
+		return
+	}
+	assert.Equal(t, "redis", m.Spec["image"])
+	assert.Empty(t, m.Exo.State)
+
+	m.Exo.ComponentID = "abc"
+	m.Exo.State = []byte(`{"containerId":"123"}`)
+	bs, err := json.Marshal(m)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.JSONEq(t, `{
+		"image": "redis",
+		"ports": ["6379:6379"],
+		"x-exo": {
+			"componentId": "abc",
+			"state": {"containerId": "123"}
+		}
+	}`, string(bs))
+
+	var decoded Model
+	if assert.NoError(t, json.Unmarshal(bs, &decoded)) {
+		assert.Equal(t, m.Spec, decoded.Spec)
+		assert.Equal(t, "abc", decoded.Exo.ComponentID)
+		assert.JSONEq(t, `{"containerId":"123"}`, string(decoded.Exo.State))
+	}
+
+	spec, err := decoded.specString()
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"image": "redis", "ports": ["6379:6379"]}`, spec)
+	}
+}
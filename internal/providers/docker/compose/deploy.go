@@ -0,0 +1,67 @@
+package compose
+
+// See <https://github.com/compose-spec/compose-spec/blob/master/deploy.md>.
+// Only the settings that are applicable to standalone containers are
+// supported. See NOTE [DOCKER SWARM FEATURES].
+type Deploy struct {
+	Replicas      *Int           `yaml:"replicas,omitempty"`
+	Resources     Resources      `yaml:"resources,omitempty"`
+	RestartPolicy *RestartPolicy `yaml:"restart_policy,omitempty"`
+
+	EndpointMode   Ignored `yaml:"endpoint_mode,omitempty"`
+	Labels         Ignored `yaml:"labels,omitempty"`
+	Mode           Ignored `yaml:"mode,omitempty"`
+	Placement      Ignored `yaml:"placement,omitempty"`
+	RollbackConfig Ignored `yaml:"rollback_config,omitempty"`
+	UpdateConfig   Ignored `yaml:"update_config,omitempty"`
+}
+
+func (d *Deploy) Interpolate(env Environment) error {
+	return interpolateStruct(d, env)
+}
+
+type Resources struct {
+	Limits       ResourceLimits `yaml:"limits,omitempty"`
+	Reservations ResourceLimits `yaml:"reservations,omitempty"`
+}
+
+func (r *Resources) Interpolate(env Environment) error {
+	return interpolateStruct(r, env)
+}
+
+// Pids is only applicable to limits.
+type ResourceLimits struct {
+	CPUs   *Float `yaml:"cpus,omitempty"`
+	Memory Bytes  `yaml:"memory,omitempty"`
+	Pids   *Int   `yaml:"pids,omitempty"`
+	// TODO: Support device reservations.
+	Devices Ignored `yaml:"devices,omitempty"`
+}
+
+func (l *ResourceLimits) Interpolate(env Environment) error {
+	return interpolateStruct(l, env)
+}
+
+type RestartPolicy struct {
+	// One of "none", "on-failure", or "any". Defaults to "any".
+	Condition   String    `yaml:"condition,omitempty"`
+	MaxAttempts *Int      `yaml:"max_attempts,omitempty"`
+	Delay       *Duration `yaml:"delay,omitempty"`
+	Window      *Duration `yaml:"window,omitempty"`
+}
+
+func (rp *RestartPolicy) Interpolate(env Environment) error {
+	return interpolateStruct(rp, env)
+}
+
+// Translates to the name of a Docker restart policy.
+func (rp *RestartPolicy) DockerName() string {
+	switch rp.Condition.Value {
+	case "none":
+		return "no"
+	case "on-failure":
+		return "on-failure"
+	default:
+		return "always"
+	}
+}
@@ -0,0 +1,81 @@
+package compose
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployYAML(t *testing.T) {
+	testYAML(t, "resources", `
+replicas: 2
+resources:
+  limits:
+    cpus: 0.5
+    memory: 50m
+    pids: 100
+  reservations:
+    memory: 20m
+`, Deploy{
+		Replicas: NewInt(2),
+		Resources: Resources{
+			Limits: ResourceLimits{
+				CPUs: func() *Float {
+					f := MakeFloat(0.5)
+					return &f
+				}(),
+				Memory: Bytes{
+					String:   MakeString("50m"),
+					Quantity: 50,
+					Unit:     ByteUnit{Suffix: "m", Scalar: 1024 * 1024},
+				},
+				Pids: NewInt(100),
+			},
+			Reservations: ResourceLimits{
+				Memory: Bytes{
+					String:   MakeString("20m"),
+					Quantity: 20,
+					Unit:     ByteUnit{Suffix: "m", Scalar: 1024 * 1024},
+				},
+			},
+		},
+	})
+
+	testYAML(t, "restart_policy", `
+restart_policy:
+  condition: on-failure
+  max_attempts: 3
+  delay: 5s
+`, Deploy{
+		RestartPolicy: &RestartPolicy{
+			Condition:   MakeString("on-failure"),
+			MaxAttempts: NewInt(3),
+			Delay: &Duration{
+				String:   MakeString("5s"),
+				Duration: 5 * time.Second,
+			},
+		},
+	})
+
+	assertInterpolated(t, map[string]string{"n": "3"}, `
+replicas: ${n}
+`, Deploy{
+		Replicas: &Int{
+			String: MakeString("${n}").WithValue("3"),
+			Value:  3,
+		},
+	})
+}
+
+func TestRestartPolicyDockerName(t *testing.T) {
+	for condition, expected := range map[string]string{
+		"":           "always",
+		"any":        "always",
+		"none":       "no",
+		"on-failure": "on-failure",
+	} {
+		rp := &RestartPolicy{Condition: MakeString(condition)}
+		assert.Equal(t, expected, rp.DockerName(), "condition %q", condition)
+	}
+}
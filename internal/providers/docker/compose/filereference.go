@@ -0,0 +1,60 @@
+package compose
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// FileReference grants a service access to a top-level secret or config.
+type FileReference struct {
+	ShortForm String
+	FileReferenceLongForm
+}
+
+type FileReferenceLongForm struct {
+	Source String    `yaml:"source,omitempty"`
+	Target String    `yaml:"target,omitempty"`
+	UID    String    `yaml:"uid,omitempty"`
+	GID    String    `yaml:"gid,omitempty"`
+	Mode   *FileMode `yaml:"mode,omitempty"`
+
+	// NOTE [RESOLVED FILE REFERENCES]:
+	// The following fields are not part of the Compose specification. The
+	// referenced secret or config is defined at the project level, but the
+	// container component only sees the service, so the importer copies the
+	// source of the file in to the reference itself.
+	File        String `yaml:"file,omitempty"`
+	Environment String `yaml:"environment,omitempty"`
+}
+
+func (ref FileReference) MarshalYAML() (any, error) {
+	if ref.ShortForm.Expression != "" {
+		return ref.ShortForm, nil
+	}
+	return ref.FileReferenceLongForm, nil
+}
+
+func (ref *FileReference) UnmarshalYAML(node *yaml.Node) error {
+	var err error
+	if node.Tag == "!!str" {
+		err = node.Decode(&ref.ShortForm)
+	} else {
+		err = node.Decode(&ref.FileReferenceLongForm)
+	}
+	_ = ref.Interpolate(ErrEnvironment)
+	return err
+}
+
+func (ref *FileReference) Interpolate(env Environment) error {
+	if ref.ShortForm.Expression == "" {
+		return ref.FileReferenceLongForm.Interpolate(env)
+	}
+	if err := ref.ShortForm.Interpolate(env); err != nil {
+		return err
+	}
+	ref.Source = ref.ShortForm
+	return nil
+}
+
+func (ref *FileReferenceLongForm) Interpolate(env Environment) error {
+	return interpolateStruct(ref, env)
+}
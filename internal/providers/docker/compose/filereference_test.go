@@ -0,0 +1,41 @@
+package compose
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFileReferenceYAML(t *testing.T) {
+	testYAML(t, "short", "my_secret", FileReference{
+		ShortForm: MakeString("my_secret"),
+		FileReferenceLongForm: FileReferenceLongForm{
+			Source: MakeString("my_secret"),
+		},
+	})
+	testYAML(t, "long", `
+source: my_secret
+target: redis_secret
+uid: "103"
+gid: "103"
+mode: 0440
+`, FileReference{
+		FileReferenceLongForm: FileReferenceLongForm{
+			Source: MakeString("my_secret"),
+			Target: MakeString("redis_secret"),
+			UID:    String{Tag: "!!str", Style: yaml.DoubleQuotedStyle, Expression: "103", Value: "103"},
+			GID:    String{Tag: "!!str", Style: yaml.DoubleQuotedStyle, Expression: "103", Value: "103"},
+			Mode: &FileMode{
+				String: String{Tag: "!!int", Expression: "0440", Value: "0440"},
+				Value:  0440,
+			},
+		},
+	})
+	assertInterpolated(t, map[string]string{"name": "my_secret"}, `
+source: ${name}
+`, FileReference{
+		FileReferenceLongForm: FileReferenceLongForm{
+			Source: MakeString("${name}").WithValue("my_secret"),
+		},
+	})
+}
@@ -0,0 +1,291 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Merge combines two compose documents, or two service definitions when
+// resolving extends, following the merge rules of the Compose specification.
+// See <https://github.com/compose-spec/compose-spec/blob/master/13-merge.md>.
+//
+// - Mappings are merged recursively.
+// - Scalars, as well as command-like sequences, are replaced.
+// - Sequences with unique items, such as ports or volumes, are merged by key.
+// - Environment-like sequences are merged as mappings.
+// - Other sequences are appended.
+//
+// Neither input node is modified. Anchors, aliases, and merge keys are
+// resolved in the result.
+func Merge(base, override *yaml.Node) (*yaml.Node, error) {
+	return mergeNodes(nil, Normalize(base), Normalize(override))
+}
+
+// MergeService is like Merge, but for individual service definitions.
+func MergeService(base, override *yaml.Node) (*yaml.Node, error) {
+	return mergeNodes([]string{"services", "*"}, Normalize(base), Normalize(override))
+}
+
+// Normalize returns a deep copy of node with anchors, aliases, and merge keys
+// resolved, so that the copy can be freely restructured.
+func Normalize(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		return Normalize(node.Content[0])
+	case yaml.AliasNode:
+		return Normalize(node.Alias)
+	}
+	res := *node
+	res.Anchor = ""
+	res.Content = nil
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			res.Content = append(res.Content, Normalize(child))
+		}
+		return &res
+	}
+	// Explicit keys take precedence over merged keys, regardless of position.
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag != "!!merge" {
+			res.Content = append(res.Content, Normalize(key), Normalize(value))
+			continue
+		}
+		value = Normalize(value)
+		if value.Kind == yaml.SequenceNode {
+			merged = append(merged, value.Content...)
+		} else {
+			merged = append(merged, value)
+		}
+	}
+	for _, source := range merged {
+		for i := 0; i+1 < len(source.Content); i += 2 {
+			if mappingIndex(&res, source.Content[i].Value) < 0 {
+				res.Content = append(res.Content, source.Content[i], source.Content[i+1])
+			}
+		}
+	}
+	return &res
+}
+
+func mergeNodes(path []string, base, override *yaml.Node) (*yaml.Node, error) {
+	if base == nil || isNull(base) {
+		return override, nil
+	}
+	if override == nil {
+		return base, nil
+	}
+	if isNull(override) {
+		// Empty keys, such as a service with no overridden settings, are common
+		// in override files and do not reset the base value.
+		return base, nil
+	}
+
+	switch mergeRuleAt(path) {
+	case replaceRule:
+		return override, nil
+	case dictionaryRule:
+		return mergeMappings(path, dictionaryToMapping(base), dictionaryToMapping(override))
+	case keyedRule:
+		if base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode {
+			return mergeKeyedSequences(path, base, override), nil
+		}
+	case namedRule:
+		if base.Kind != override.Kind {
+			return mergeMappings(path, namesToMapping(base), namesToMapping(override))
+		}
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && override.Kind == yaml.MappingNode:
+		return mergeMappings(path, base, override)
+	case base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode:
+		return appendSequences(base, override), nil
+	case base.Kind == override.Kind || override.Kind == yaml.ScalarNode || base.Kind == yaml.ScalarNode:
+		// Short syntax, such as `build: .`, may be replaced by long syntax and
+		// vice versa.
+		return override, nil
+	default:
+		return nil, fmt.Errorf("cannot merge %s with %s at %s", base.ShortTag(), override.ShortTag(), strings.Join(path, "."))
+	}
+}
+
+func mergeMappings(path []string, base, override *yaml.Node) (*yaml.Node, error) {
+	res := *base
+	res.Content = append([]*yaml.Node{}, base.Content...)
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		idx := mappingIndex(&res, key.Value)
+		if idx < 0 {
+			res.Content = append(res.Content, key, value)
+			continue
+		}
+		merged, err := mergeNodes(append(path, key.Value), res.Content[idx+1], value)
+		if err != nil {
+			return nil, err
+		}
+		res.Content[idx+1] = merged
+	}
+	return &res, nil
+}
+
+func appendSequences(base, override *yaml.Node) *yaml.Node {
+	res := *base
+	res.Content = append([]*yaml.Node{}, base.Content...)
+	seen := make(map[string]bool)
+	for _, item := range base.Content {
+		if item.Kind == yaml.ScalarNode {
+			seen[item.Value] = true
+		}
+	}
+	for _, item := range override.Content {
+		if item.Kind == yaml.ScalarNode {
+			if seen[item.Value] {
+				continue
+			}
+			seen[item.Value] = true
+		}
+		res.Content = append(res.Content, item)
+	}
+	return &res
+}
+
+// Merges sequences whose items are identified by a key, such as the target
+// path of a volume. Overriding items replace base items in place.
+func mergeKeyedSequences(path []string, base, override *yaml.Node) *yaml.Node {
+	field := path[len(path)-1]
+	res := *base
+	res.Content = append([]*yaml.Node{}, base.Content...)
+	indexes := make(map[string]int, len(base.Content))
+	for i, item := range base.Content {
+		indexes[sequenceItemKey(field, item)] = i
+	}
+	for _, item := range override.Content {
+		key := sequenceItemKey(field, item)
+		if i, ok := indexes[key]; ok {
+			res.Content[i] = item
+			continue
+		}
+		indexes[key] = len(res.Content)
+		res.Content = append(res.Content, item)
+	}
+	return &res
+}
+
+func sequenceItemKey(field string, item *yaml.Node) string {
+	if item.Kind == yaml.ScalarNode {
+		switch field {
+		case "volumes":
+			var vm VolumeMount
+			if err := item.Decode(&vm); err == nil {
+				return vm.Target.Value
+			}
+		}
+		return item.Value
+	}
+	get := func(key string) string {
+		if idx := mappingIndex(item, key); idx >= 0 {
+			return item.Content[idx+1].Value
+		}
+		return ""
+	}
+	switch field {
+	case "volumes":
+		return get("target")
+	case "secrets", "configs":
+		if target := get("target"); target != "" {
+			return target
+		}
+		return get("source")
+	case "ports":
+		return strings.Join([]string{get("host_ip"), get("published"), get("target"), get("protocol")}, ":")
+	default:
+		return ""
+	}
+}
+
+type mergeRule int
+
+const (
+	defaultRule mergeRule = iota
+	// The value is replaced entirely.
+	replaceRule
+	// The value is a Dictionary, which may be either a sequence or a mapping.
+	dictionaryRule
+	// The value is a sequence of unique items.
+	keyedRule
+	// The value is either a sequence of names or a mapping keyed by name.
+	namedRule
+)
+
+func mergeRuleAt(path []string) mergeRule {
+	if len(path) < 3 || path[0] != "services" {
+		return defaultRule
+	}
+	switch strings.Join(path[2:], ".") {
+	case "command", "entrypoint", "healthcheck.test":
+		return replaceRule
+	case "environment", "labels", "sysctls", "build.args", "build.labels", "deploy.labels":
+		return dictionaryRule
+	case "ports", "volumes", "secrets", "configs":
+		return keyedRule
+	case "depends_on", "networks":
+		return namedRule
+	default:
+		return defaultRule
+	}
+}
+
+// Converts a sequence of "KEY=VALUE" items to a mapping.
+func dictionaryToMapping(node *yaml.Node) *yaml.Node {
+	if node.Kind != yaml.SequenceNode {
+		return node
+	}
+	res := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, item := range node.Content {
+		key, value, hasValue := strings.Cut(item.Value, "=")
+		valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		if hasValue {
+			valueNode = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+		}
+		res.Content = append(res.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+	}
+	return res
+}
+
+// Converts a sequence of names to a mapping with empty values.
+func namesToMapping(node *yaml.Node) *yaml.Node {
+	if node.Kind != yaml.SequenceNode {
+		return node
+	}
+	res := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, item := range node.Content {
+		res.Content = append(res.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item.Value},
+			&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"},
+		)
+	}
+	return res
+}
+
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
@@ -0,0 +1,145 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deref/exo/internal/util/yamlutil"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMerge(t *testing.T) {
+	check := func(name, base, override, expected string) {
+		t.Run(name, func(t *testing.T) {
+			var baseNode, overrideNode yaml.Node
+			if err := yaml.Unmarshal([]byte(base), &baseNode); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal([]byte(override), &overrideNode); err != nil {
+				t.Fatal(err)
+			}
+			merged, err := Merge(&baseNode, &overrideNode)
+			if assert.NoError(t, err) {
+				actual, err := yamlutil.MarshalString(merged)
+				assert.NoError(t, err)
+				assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(actual))
+			}
+		})
+	}
+
+	check("mappings", `
+services:
+  web:
+    image: nginx
+    restart: always
+`, `
+services:
+  web:
+    image: nginx:alpine
+  db:
+    image: postgres
+`, `
+services:
+  web:
+    image: nginx:alpine
+    restart: always
+  db:
+    image: postgres
+`)
+
+	check("sequences", `
+services:
+  web:
+    command: [serve, --port, "80"]
+    dns: [8.8.8.8]
+`, `
+services:
+  web:
+    command: [serve]
+    dns: [8.8.8.8, 4.4.4.4]
+`, `
+services:
+  web:
+    command: [serve]
+    dns: [8.8.8.8, 4.4.4.4]
+`)
+
+	check("dictionaries", `
+services:
+  web:
+    environment:
+      - A=1
+      - B=2
+`, `
+services:
+  web:
+    environment:
+      B: 3
+`, `
+services:
+  web:
+    environment:
+      A: "1"
+      B: 3
+`)
+
+	check("unique_items", `
+services:
+  web:
+    volumes:
+      - ./src:/srv
+      - data:/data
+`, `
+services:
+  web:
+    volumes:
+      - ./other:/srv
+`, `
+services:
+  web:
+    volumes:
+      - ./other:/srv
+      - data:/data
+`)
+
+	check("names", `
+services:
+  web:
+    depends_on: [db]
+`, `
+services:
+  web:
+    depends_on:
+      cache:
+        condition: service_healthy
+`, `
+services:
+  web:
+    depends_on:
+      db: {}
+      cache:
+        condition: service_healthy
+`)
+
+	check("merge_keys", `
+x-defaults: &defaults
+  restart: always
+  image: base
+services:
+  web:
+    <<: *defaults
+    image: nginx
+`, `
+services:
+  web: {}
+`, `
+x-defaults:
+  restart: always
+  image: base
+services:
+  web:
+    image: nginx
+    restart: always
+`)
+}
@@ -8,4 +8,10 @@ func TestNumbersYAML(t *testing.T) {
 		String: MakeString("${one}").WithValue("1"),
 		Value:  1,
 	})
+
+	testYAML(t, "float", `0.5`, MakeFloat(0.5))
+	assertInterpolated(t, map[string]string{"half": "0.5"}, `${half}`, Float{
+		String: MakeString("${half}").WithValue("0.5"),
+		Value:  0.5,
+	})
 }
@@ -3,6 +3,11 @@ package compose
 import "testing"
 
 func TestSecretAML(t *testing.T) {
+	testYAML(t, "environment", `
+environment: API_TOKEN
+`, Secret{
+		Environment: MakeString("API_TOKEN"),
+	})
 	assertInterpolated(t, map[string]string{"x": "y"}, `
 file: ${x}
 `, Secret{
@@ -188,6 +188,14 @@ platform: linux/arm64/v8
 		Platform: MakeString("linux/arm64/v8"),
 	})
 
+	testYAML(t, "profiles", `
+profiles:
+  - debug
+  - tools
+`, Service{
+		Profiles: []String{MakeString("debug"), MakeString("tools")},
+	})
+
 	testYAML(t, "pull_policy", `
 pull_policy: missing
 `, Service{
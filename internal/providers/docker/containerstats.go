@@ -61,18 +61,17 @@ type ContainerStats struct {
 		} `json:"stats"`
 		Usage uint64 `json:"usage"`
 	} `json:"memory_stats"`
-	Name     string `json:"name"`
-	Networks struct {
-		Eth0 struct {
-			RxBytes   int64 `json:"rx_bytes"`
-			RxDropped int64 `json:"rx_dropped"`
-			RxErrors  int64 `json:"rx_errors"`
-			RxPackets int64 `json:"rx_packets"`
-			TxBytes   int64 `json:"tx_bytes"`
-			TxDropped int64 `json:"tx_dropped"`
-			TxErrors  int64 `json:"tx_errors"`
-			TxPackets int64 `json:"tx_packets"`
-		} `json:"eth0"`
+	Name string `json:"name"`
+	// Keyed by interface name, such as "eth0".
+	Networks map[string]struct {
+		RxBytes   int64 `json:"rx_bytes"`
+		RxDropped int64 `json:"rx_dropped"`
+		RxErrors  int64 `json:"rx_errors"`
+		RxPackets int64 `json:"rx_packets"`
+		TxBytes   int64 `json:"tx_bytes"`
+		TxDropped int64 `json:"tx_dropped"`
+		TxErrors  int64 `json:"tx_errors"`
+		TxPackets int64 `json:"tx_packets"`
 	} `json:"networks"`
 	NumProcs  int64 `json:"num_procs"`
 	PIDsStats struct {
@@ -0,0 +1,147 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/deref/exo/sdk"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	dockerclient "github.com/docker/docker/client"
+)
+
+type ContainerController struct {
+	Docker *dockerclient.Client
+}
+
+type ContainerModel struct {
+	ContainerSpec
+	ContainerState
+}
+
+type ContainerSpec struct {
+	Name    string            `json:"name,omitempty"`
+	Image   string            `json:"image,omitempty"`
+	Command []string          `json:"command,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+type ContainerState struct {
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+func (ctrl *ContainerController) IdentifyResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ContainerModel) (string, error) {
+	return makeIRI("containers", m.ID), nil
+}
+
+func (ctrl *ContainerController) LocateResource(ctx context.Context, cfg *sdk.ResourceConfig, iri string, m *ContainerModel) error {
+	ref, err := parseRef("containers", iri)
+	if err != nil {
+		return err
+	}
+	inspection, err := ctrl.Docker.ContainerInspect(ctx, ref)
+	if err != nil {
+		return wrapNotFound(err, "container", ref)
+	}
+	ctrl.load(m, inspection)
+	return nil
+}
+
+func (ctrl *ContainerController) CreateResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ContainerModel) error {
+	if m.Image == "" {
+		return errors.New("image is required")
+	}
+	created, err := ctrl.Docker.ContainerCreate(ctx, &container.Config{
+		Image:  m.Image,
+		Cmd:    m.Command,
+		Labels: m.Labels,
+	}, nil, nil, nil, m.Name)
+	if err != nil {
+		return fmt.Errorf("creating: %w", err)
+	}
+	m.ID = created.ID
+	if err := ctrl.Docker.ContainerStart(ctx, m.ID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("starting: %w", err)
+	}
+	return ctrl.ReadResource(ctx, cfg, m)
+}
+
+func (ctrl *ContainerController) ReadResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ContainerModel) error {
+	ref := m.ID
+	if ref == "" {
+		ref = m.Name
+	}
+	if ref == "" {
+		return sdk.ErrResourceGone
+	}
+	inspection, err := ctrl.Docker.ContainerInspect(ctx, ref)
+	if dockerclient.IsErrNotFound(err) {
+		return sdk.ErrResourceGone
+	}
+	if err != nil {
+		return err
+	}
+	ctrl.load(m, inspection)
+	return nil
+}
+
+func (ctrl *ContainerController) load(m *ContainerModel, inspection types.ContainerJSON) {
+	m.ID = inspection.ID
+	m.Name = strings.TrimPrefix(inspection.Name, "/")
+	if inspection.Config != nil {
+		m.Image = inspection.Config.Image
+		m.Command = inspection.Config.Cmd
+		m.Labels = inspection.Config.Labels
+	}
+	if inspection.State != nil {
+		m.Status = inspection.State.Status
+	}
+}
+
+func (ctrl *ContainerController) UpdateResource(ctx context.Context, cfg *sdk.ResourceConfig, prev *ContainerModel, next *ContainerModel) error {
+	next.ContainerState = prev.ContainerState
+	if !reflect.DeepEqual(prev.ContainerSpec, next.ContainerSpec) {
+		return sdk.NewNotImplementedErrorf("changing a container")
+	}
+	if next.ID == "" {
+		return nil
+	}
+	// Start a container that was shut down. Starting a running container has
+	// no effect.
+	if err := ctrl.Docker.ContainerStart(ctx, next.ID, types.ContainerStartOptions{}); err != nil {
+		if dockerclient.IsErrNotFound(err) {
+			return sdk.ErrResourceGone
+		}
+		return fmt.Errorf("starting: %w", err)
+	}
+	return ctrl.ReadResource(ctx, cfg, next)
+}
+
+func (ctrl *ContainerController) ShutdownResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ContainerModel) error {
+	if m.ID == "" {
+		return nil
+	}
+	// Use container's default stop timeout.
+	err := ctrl.Docker.ContainerStop(ctx, m.ID, nil)
+	if dockerclient.IsErrNotFound(err) {
+		return sdk.ErrResourceGone
+	}
+	return err
+}
+
+func (ctrl *ContainerController) DeleteResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ContainerModel) error {
+	if m.ID == "" {
+		return nil
+	}
+	err := ctrl.Docker.ContainerRemove(ctx, m.ID, types.ContainerRemoveOptions{
+		Force: true,
+	})
+	if dockerclient.IsErrNotFound(err) {
+		err = nil
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/deref/exo/sdk"
+	"github.com/docker/docker/api/types"
+	dockerclient "github.com/docker/docker/client"
+)
+
+type NetworkController struct {
+	Docker *dockerclient.Client
+}
+
+type NetworkModel struct {
+	NetworkSpec
+	NetworkState
+}
+
+type NetworkSpec struct {
+	Name       string            `json:"name,omitempty"`
+	Driver     string            `json:"driver,omitempty"`
+	Internal   bool              `json:"internal,omitempty"`
+	Attachable bool              `json:"attachable,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+type NetworkState struct {
+	ID string `json:"id,omitempty"`
+}
+
+func (ctrl *NetworkController) IdentifyResource(ctx context.Context, cfg *sdk.ResourceConfig, m *NetworkModel) (string, error) {
+	return makeIRI("networks", m.ID), nil
+}
+
+func (ctrl *NetworkController) LocateResource(ctx context.Context, cfg *sdk.ResourceConfig, iri string, m *NetworkModel) error {
+	ref, err := parseRef("networks", iri)
+	if err != nil {
+		return err
+	}
+	inspection, err := ctrl.Docker.NetworkInspect(ctx, ref, types.NetworkInspectOptions{})
+	if err != nil {
+		return wrapNotFound(err, "network", ref)
+	}
+	ctrl.load(m, inspection)
+	return nil
+}
+
+func (ctrl *NetworkController) CreateResource(ctx context.Context, cfg *sdk.ResourceConfig, m *NetworkModel) error {
+	if m.Name == "" {
+		return errors.New("name is required")
+	}
+	created, err := ctrl.Docker.NetworkCreate(ctx, m.Name, types.NetworkCreate{
+		Driver:     m.Driver,
+		Internal:   m.Internal,
+		Attachable: m.Attachable,
+		Labels:     m.Labels,
+	})
+	if err != nil {
+		return fmt.Errorf("creating: %w", err)
+	}
+	m.ID = created.ID
+	return nil
+}
+
+func (ctrl *NetworkController) ReadResource(ctx context.Context, cfg *sdk.ResourceConfig, m *NetworkModel) error {
+	ref := m.ID
+	if ref == "" {
+		ref = m.Name
+	}
+	if ref == "" {
+		return sdk.ErrResourceGone
+	}
+	inspection, err := ctrl.Docker.NetworkInspect(ctx, ref, types.NetworkInspectOptions{})
+	if dockerclient.IsErrNotFound(err) {
+		return sdk.ErrResourceGone
+	}
+	if err != nil {
+		return err
+	}
+	ctrl.load(m, inspection)
+	return nil
+}
+
+func (ctrl *NetworkController) load(m *NetworkModel, inspection types.NetworkResource) {
+	m.ID = inspection.ID
+	m.Name = inspection.Name
+	m.Driver = inspection.Driver
+	m.Internal = inspection.Internal
+	m.Attachable = inspection.Attachable
+	m.Labels = inspection.Labels
+}
+
+func (ctrl *NetworkController) UpdateResource(ctx context.Context, cfg *sdk.ResourceConfig, prev *NetworkModel, next *NetworkModel) error {
+	next.NetworkState = prev.NetworkState
+	if !reflect.DeepEqual(prev.NetworkSpec, next.NetworkSpec) {
+		return sdk.NewNotImplementedErrorf("changing a network")
+	}
+	return nil
+}
+
+func (ctrl *NetworkController) ShutdownResource(ctx context.Context, cfg *sdk.ResourceConfig, m *NetworkModel) error {
+	return nil
+}
+
+func (ctrl *NetworkController) DeleteResource(ctx context.Context, cfg *sdk.ResourceConfig, m *NetworkModel) error {
+	if m.ID == "" {
+		return nil
+	}
+	err := ctrl.Docker.NetworkRemove(ctx, m.ID)
+	if dockerclient.IsErrNotFound(err) {
+		err = nil
+	}
+	return err
+}
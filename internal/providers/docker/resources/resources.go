@@ -0,0 +1,57 @@
+// Package resources provides resource controllers for Docker objects. Besides
+// creating new objects, these controllers support adopting pre-existing
+// containers, volumes, and networks by name or ID.
+package resources
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/sdk"
+	dockerclient "github.com/docker/docker/client"
+)
+
+const (
+	ContainerType = "deref.io/docker/container"
+	VolumeType    = "deref.io/docker/volume"
+	NetworkType   = "deref.io/docker/network"
+)
+
+func NewContainerController(svc api.Service, docker *dockerclient.Client) *sdk.ResourceComponentController {
+	return sdk.NewResourceComponentController[ContainerModel](svc, &ContainerController{Docker: docker})
+}
+
+func NewVolumeController(svc api.Service, docker *dockerclient.Client) *sdk.ResourceComponentController {
+	return sdk.NewResourceComponentController[VolumeModel](svc, &VolumeController{Docker: docker})
+}
+
+func NewNetworkController(svc api.Service, docker *dockerclient.Client) *sdk.ResourceComponentController {
+	return sdk.NewResourceComponentController[NetworkModel](svc, &NetworkController{Docker: docker})
+}
+
+// Docker IRIs take the form "docker:/<collection>/<id>". Containers and
+// networks are identified by ID, volumes by name. When locating, the Docker
+// API accepts either names or IDs, so the IRI prefix is optional.
+func makeIRI(collection string, id string) string {
+	if id == "" {
+		return ""
+	}
+	return "docker:/" + collection + "/" + id
+}
+
+func parseRef(collection string, iri string) (string, error) {
+	ref := strings.TrimPrefix(iri, "docker:/"+collection+"/")
+	if ref == "" || strings.Contains(ref, "/") {
+		return "", errutil.HTTPErrorf(http.StatusBadRequest, "invalid docker %s reference: %q", collection, iri)
+	}
+	return ref, nil
+}
+
+func wrapNotFound(err error, kind string, ref string) error {
+	if dockerclient.IsErrNotFound(err) {
+		return errutil.HTTPErrorf(http.StatusNotFound, "no such %s: %q", kind, ref)
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRef(t *testing.T) {
+	for _, test := range []struct {
+		IRI      string
+		Expected string
+	}{
+		{"postgres", "postgres"},
+		{"docker:/containers/postgres", "postgres"},
+		{"docker:/containers/0123abcd", "0123abcd"},
+	} {
+		ref, err := parseRef("containers", test.IRI)
+		if assert.NoError(t, err, test.IRI) {
+			assert.Equal(t, test.Expected, ref, test.IRI)
+		}
+	}
+
+	for _, iri := range []string{"", "docker:/containers/", "docker:/volumes/data"} {
+		_, err := parseRef("containers", iri)
+		assert.Error(t, err, iri)
+	}
+}
+
+func TestMakeIRI(t *testing.T) {
+	assert.Equal(t, "docker:/volumes/data", makeIRI("volumes", "data"))
+	assert.Equal(t, "", makeIRI("volumes", ""))
+}
@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/deref/exo/sdk"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	dockerclient "github.com/docker/docker/client"
+)
+
+type VolumeController struct {
+	Docker *dockerclient.Client
+}
+
+type VolumeModel struct {
+	VolumeSpec
+	VolumeState
+}
+
+type VolumeSpec struct {
+	Name   string            `json:"name,omitempty"`
+	Driver string            `json:"driver,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type VolumeState struct {
+	Mountpoint string `json:"mountpoint,omitempty"`
+}
+
+func (ctrl *VolumeController) IdentifyResource(ctx context.Context, cfg *sdk.ResourceConfig, m *VolumeModel) (string, error) {
+	return makeIRI("volumes", m.Name), nil
+}
+
+func (ctrl *VolumeController) LocateResource(ctx context.Context, cfg *sdk.ResourceConfig, iri string, m *VolumeModel) error {
+	ref, err := parseRef("volumes", iri)
+	if err != nil {
+		return err
+	}
+	inspection, err := ctrl.Docker.VolumeInspect(ctx, ref)
+	if err != nil {
+		return wrapNotFound(err, "volume", ref)
+	}
+	ctrl.load(m, inspection)
+	return nil
+}
+
+func (ctrl *VolumeController) CreateResource(ctx context.Context, cfg *sdk.ResourceConfig, m *VolumeModel) error {
+	if m.Name == "" {
+		return errors.New("name is required")
+	}
+	created, err := ctrl.Docker.VolumeCreate(ctx, volume.VolumeCreateBody{
+		Name:   m.Name,
+		Driver: m.Driver,
+		Labels: m.Labels,
+	})
+	if err != nil {
+		return fmt.Errorf("creating: %w", err)
+	}
+	ctrl.load(m, created)
+	return nil
+}
+
+func (ctrl *VolumeController) ReadResource(ctx context.Context, cfg *sdk.ResourceConfig, m *VolumeModel) error {
+	if m.Name == "" {
+		return sdk.ErrResourceGone
+	}
+	inspection, err := ctrl.Docker.VolumeInspect(ctx, m.Name)
+	if dockerclient.IsErrNotFound(err) {
+		return sdk.ErrResourceGone
+	}
+	if err != nil {
+		return err
+	}
+	ctrl.load(m, inspection)
+	return nil
+}
+
+func (ctrl *VolumeController) load(m *VolumeModel, inspection types.Volume) {
+	m.Name = inspection.Name
+	m.Driver = inspection.Driver
+	m.Labels = inspection.Labels
+	m.Mountpoint = inspection.Mountpoint
+}
+
+func (ctrl *VolumeController) UpdateResource(ctx context.Context, cfg *sdk.ResourceConfig, prev *VolumeModel, next *VolumeModel) error {
+	next.VolumeState = prev.VolumeState
+	if !reflect.DeepEqual(prev.VolumeSpec, next.VolumeSpec) {
+		return sdk.NewNotImplementedErrorf("changing a volume")
+	}
+	return nil
+}
+
+func (ctrl *VolumeController) ShutdownResource(ctx context.Context, cfg *sdk.ResourceConfig, m *VolumeModel) error {
+	return nil
+}
+
+func (ctrl *VolumeController) DeleteResource(ctx context.Context, cfg *sdk.ResourceConfig, m *VolumeModel) error {
+	if m.Name == "" {
+		return nil
+	}
+	force := false
+	err := ctrl.Docker.VolumeRemove(ctx, m.Name, force)
+	if dockerclient.IsErrNotFound(err) {
+		err = nil
+	}
+	return err
+}
@@ -2,21 +2,25 @@ package os
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/deref/exo/internal/api"
 	"github.com/deref/exo/internal/util/errutil"
 	"github.com/deref/exo/internal/util/osutil"
 	"github.com/deref/exo/internal/util/which"
 	"github.com/deref/exo/sdk"
+	psprocess "github.com/shirou/gopsutil/v3/process"
 )
 
-type ProcessController struct {
-	sdk.ResourceController[ProcessModel]
-}
+type ProcessController struct{}
 
 func NewProcessController(svc api.Service) *sdk.ResourceComponentController {
 	return sdk.NewResourceComponentController[ProcessModel](svc, &ProcessController{})
@@ -46,7 +50,7 @@ func (ctrl *ProcessController) IdentifyResource(ctx context.Context, cfg *sdk.Re
 	return fmt.Sprintf("exo:/processes/%d", *m.Pid), nil
 }
 
-func (ctrl *ProcessController) Create(ctx context.Context, cfg *sdk.ResourceConfig, m *ProcessModel) error {
+func (ctrl *ProcessController) CreateResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ProcessModel) error {
 	// Resolve program path.
 	{
 		whichQ := which.Query{
@@ -75,7 +79,24 @@ func (ctrl *ProcessController) Create(ctx context.Context, cfg *sdk.ResourceConf
 		return err
 	}
 	m.Pid = &cmd.Process.Pid
+	// Reap the process when it exits, so that it does not linger as a zombie.
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
+}
 
+// Accepts a process IRI or a bare pid.
+func (ctrl *ProcessController) LocateResource(ctx context.Context, cfg *sdk.ResourceConfig, iri string, m *ProcessModel) error {
+	pid, err := strconv.Atoi(strings.TrimPrefix(iri, "exo:/processes/"))
+	if err != nil || pid <= 0 {
+		return errutil.HTTPErrorf(http.StatusBadRequest, "invalid process iri: %q", iri)
+	}
+	m.Pid = &pid
+	if !ctrl.exists(m) {
+		return errutil.HTTPErrorf(http.StatusNotFound, "no such process: %d", pid)
+	}
 	return nil
 }
 
@@ -83,23 +104,99 @@ func (ctrl *ProcessController) ReadResource(ctx context.Context, cfg *sdk.Resour
 	if !ctrl.exists(m) {
 		return sdk.ErrResourceGone
 	}
+	if m.Program == "" {
+		// Adopted processes are only known by pid, so fill in the spec.
+		return ctrl.inspect(ctx, m)
+	}
 	return nil
 }
 
-func (ctrl *ProcessController) Shutdown(ctx context.Context, cfg *sdk.ResourceConfig, m *ProcessModel) error {
+func (ctrl *ProcessController) inspect(ctx context.Context, m *ProcessModel) error {
+	proc, err := psprocess.NewProcessWithContext(ctx, int32(*m.Pid))
+	if err != nil {
+		return fmt.Errorf("inspecting process: %w", err)
+	}
+	if cmdline, err := proc.CmdlineSliceWithContext(ctx); err == nil && len(cmdline) > 0 {
+		m.Program = cmdline[0]
+		m.Arguments = cmdline[1:]
+	}
+	if exe, err := proc.ExeWithContext(ctx); err == nil {
+		m.ProgramPath = exe
+	}
+	if cwd, err := proc.CwdWithContext(ctx); err == nil {
+		m.Directory = cwd
+	}
+	return nil
+}
+
+func (ctrl *ProcessController) UpdateResource(ctx context.Context, cfg *sdk.ResourceConfig, prev *ProcessModel, next *ProcessModel) error {
+	next.ProcessState = prev.ProcessState
+	if reflect.DeepEqual(prev.ProcessSpec, next.ProcessSpec) {
+		if ctrl.exists(next) {
+			return nil
+		}
+		// Start a process that was shut down.
+		next.ProcessState = ProcessState{}
+		return ctrl.CreateResource(ctx, cfg, next)
+	}
+	// Running processes cannot be changed, so restart with the new spec.
+	if err := ctrl.ShutdownResource(ctx, cfg, prev); err != nil && !errors.Is(err, sdk.ErrResourceGone) {
+		return fmt.Errorf("shutting down: %w", err)
+	}
+	next.ProcessState = ProcessState{}
+	return ctrl.CreateResource(ctx, cfg, next)
+}
+
+func (ctrl *ProcessController) ShutdownResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ProcessModel) error {
 	if !ctrl.exists(m) {
 		return sdk.ErrResourceGone
 	}
-	return osutil.ShutdownGroup(ctx, *m.Pid)
+	if !ctrl.isGroupLeader(m) {
+		return ctrl.shutdown(ctx, *m.Pid)
+	}
+	return ctrl.shutdown(ctx, -*m.Pid)
+}
+
+// Terminates pid, or the process group -pid, then polls until it exits,
+// killing it if ctx is cancelled. Processes created by this controller are
+// reaped as they exit and adopted processes are not children of exo, so
+// neither can be waited on.
+func (ctrl *ProcessController) shutdown(ctx context.Context, pid int) error {
+	if err := osutil.TerminateProcess(pid); err != nil {
+		return fmt.Errorf("terminating: %w", err)
+	}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for osutil.SignalProcess(pid, syscall.Signal(0)) == nil {
+		select {
+		case <-ctx.Done():
+			if err := osutil.KillProcess(pid); err != nil {
+				return fmt.Errorf("killing: %w", err)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Processes created by this controller lead their own process group, but
+// adopted processes may not.
+func (ctrl *ProcessController) isGroupLeader(m *ProcessModel) bool {
+	pgid, err := syscall.Getpgid(*m.Pid)
+	return err == nil && pgid == *m.Pid
 }
 
 func (ctrl *ProcessController) exists(m *ProcessModel) bool {
 	return m.Pid != nil && osutil.IsValidPid(*m.Pid)
 }
 
-func (ctrl *ProcessController) Delete(ctx context.Context, cfg *sdk.ResourceConfig, m *ProcessModel) error {
-	if m.Pid == nil {
+func (ctrl *ProcessController) DeleteResource(ctx context.Context, cfg *sdk.ResourceConfig, m *ProcessModel) error {
+	if !ctrl.exists(m) {
 		return nil
 	}
+	if !ctrl.isGroupLeader(m) {
+		return osutil.KillProcess(*m.Pid)
+	}
 	return osutil.KillGroup(*m.Pid)
 }
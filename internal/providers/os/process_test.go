@@ -0,0 +1,73 @@
+package os
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deref/exo/internal/util/osutil"
+	"github.com/deref/exo/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessLifecycle(t *testing.T) {
+	ctx := context.Background()
+	ctrl := &ProcessController{}
+	cfg := &sdk.ResourceConfig{ID: "test", Type: "deref.io/os/process"}
+	env := map[string]string{"PATH": "/usr/bin:/bin"}
+
+	m := &ProcessModel{
+		ProcessSpec: ProcessSpec{
+			Program:     "sleep",
+			Arguments:   []string{"60"},
+			Environment: env,
+		},
+	}
+	if !assert.NoError(t, ctrl.CreateResource(ctx, cfg, m)) {
+		return
+	}
+	defer func() {
+		_ = ctrl.DeleteResource(ctx, cfg, m)
+	}()
+	if !assert.NotNil(t, m.Pid) {
+		return
+	}
+	assert.NotEmpty(t, m.ProgramPath)
+	assert.NoError(t, ctrl.ReadResource(ctx, cfg, m))
+
+	// An unchanged spec leaves the process running.
+	same := &ProcessModel{ProcessSpec: m.ProcessSpec}
+	assert.NoError(t, ctrl.UpdateResource(ctx, cfg, m, same))
+	assert.Equal(t, *m.Pid, *same.Pid)
+
+	// A changed spec restarts the process.
+	oldPid := *m.Pid
+	next := &ProcessModel{
+		ProcessSpec: ProcessSpec{
+			Program:     "sleep",
+			Arguments:   []string{"61"},
+			Environment: env,
+		},
+	}
+	if !assert.NoError(t, ctrl.UpdateResource(ctx, cfg, m, next)) {
+		return
+	}
+	m = next
+	if assert.NotNil(t, m.Pid) {
+		assert.NotEqual(t, oldPid, *m.Pid)
+	}
+	assert.False(t, osutil.IsValidPid(oldPid))
+	assert.NoError(t, ctrl.ReadResource(ctx, cfg, m))
+
+	assert.NoError(t, ctrl.ShutdownResource(ctx, cfg, m))
+	assert.ErrorIs(t, ctrl.ReadResource(ctx, cfg, m), sdk.ErrResourceGone)
+	assert.ErrorIs(t, ctrl.ShutdownResource(ctx, cfg, m), sdk.ErrResourceGone)
+
+	// Updating with an unchanged spec starts a process that was shut down.
+	restarted := &ProcessModel{ProcessSpec: m.ProcessSpec}
+	if !assert.NoError(t, ctrl.UpdateResource(ctx, cfg, m, restarted)) {
+		return
+	}
+	m = restarted
+	assert.NoError(t, ctrl.ReadResource(ctx, cfg, m))
+	assert.NoError(t, ctrl.DeleteResource(ctx, cfg, m))
+}
@@ -7,6 +7,7 @@ type Process struct {
 	State
 
 	SyslogPort uint
+	VarDir     string
 }
 
 type Spec struct {
@@ -15,6 +16,35 @@ type Spec struct {
 	Arguments                  []string          `json:"arguments"`
 	Environment                map[string]string `json:"environment"`
 	ShutdownGracePeriodSeconds *int              `json:"shutdownGracePeriodSeconds"`
+	Readiness                  *Readiness        `json:"readiness,omitempty"`
+	Restart                    string            `json:"restart,omitempty"`
+	MaxRestarts                *int              `json:"maxRestarts,omitempty"`
+	Watch                      *Watch            `json:"watch,omitempty"`
+	// One of json, logfmt, or plain. Fields of structured output lines are
+	// recorded as event tags.
+	LogFormat string `json:"logFormat,omitempty"`
+	// If true, the process runs under a pseudo-terminal, which clients may
+	// attach to with `exo attach`. Not supported by the processes of stacks,
+	// which run without a supervisor.
+	TTY bool `json:"tty,omitempty"`
+	// Resource limits, which are enforced on Linux hosts with cgroup v2 and
+	// otherwise ignored. CPU is limited to a number of CPUs, which may be
+	// fractional. Pids limits the number of processes and threads.
+	CPULimit    *float64  `json:"cpu_limit,omitempty"`
+	MemoryLimit *ByteSize `json:"memory_limit,omitempty"`
+	PidsLimit   *int64    `json:"pids_limit,omitempty"`
+}
+
+// Watch configures the daemon to restart a process when files in its
+// directory change.
+type Watch struct {
+	// Globs relative to the process directory. If empty, all files are watched.
+	Include []string `json:"include,omitempty"`
+	// Globs of files to ignore, in addition to filewatch.DefaultExclude.
+	Exclude              []string `json:"exclude,omitempty"`
+	DebounceMilliseconds *int     `json:"debounceMilliseconds,omitempty"`
+	// If provided, this signal is sent to the process instead of restarting it.
+	Signal string `json:"signal,omitempty"`
 }
 
 type State struct {
@@ -23,11 +53,25 @@ type State struct {
 	Arguments                  []string          `json:"arguments"`
 	Environment                map[string]string `json:"environment"`
 	ShutdownGracePeriodSeconds *int              `json:"shutdownGracePeriodSeconds"`
+	Readiness                  *Readiness        `json:"readiness,omitempty"`
+	RestartPolicy              string            `json:"restart,omitempty"`
+	MaxRestarts                *int              `json:"maxRestarts,omitempty"`
+	LogFormat                  string            `json:"logFormat,omitempty"`
+	TTY                        bool              `json:"tty,omitempty"`
+	CPULimit                   *float64          `json:"cpu_limit,omitempty"`
+	MemoryLimit                *ByteSize         `json:"memory_limit,omitempty"`
+	PidsLimit                  *int64            `json:"pids_limit,omitempty"`
 
 	Pgid            int               `json:"pgid"`
 	SupervisorPid   int               `json:"supervisorPid"`
 	Pid             int               `json:"pid"`
 	FullEnvironment map[string]string `json:"fullEnvironment"`
+	StatusFile      string            `json:"statusFile,omitempty"`
+	Restarts        int               `json:"restarts"`
+	// Path of the supervisor's socket for attaching to the process terminal.
+	AttachSocket string `json:"attachSocket,omitempty"`
+	// Path of the cgroup containing the process, if it has resource limits.
+	Cgroup string `json:"cgroup,omitempty"`
 }
 
 func (state *State) reset() {
@@ -35,4 +79,6 @@ func (state *State) reset() {
 	state.SupervisorPid = 0
 	state.Pid = 0
 	state.FullEnvironment = nil
+	state.AttachSocket = ""
+	state.Cgroup = ""
 }
@@ -8,23 +8,34 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/supervise"
+	"github.com/deref/exo/internal/util/cgrouputil"
 	"github.com/deref/exo/internal/util/jsonutil"
+	"github.com/deref/exo/internal/util/logging"
 )
 
 // TODO: Make part of the resource controller?
 
-func GetProcessDescription(ctx context.Context, component api.ComponentDescription) (api.ProcessDescription, error) {
+func GetProcessDescription(ctx context.Context, logger logging.Logger, component api.ComponentDescription) (api.ProcessDescription, error) {
 	var state State
 	if err := jsonutil.UnmarshalStringOrEmpty(component.State, &state); err != nil {
 		return api.ProcessDescription{}, fmt.Errorf("unmarshalling container state: %v\n", err)
 	}
 
+	if state.StatusFile != "" {
+		if status, err := supervise.ReadStatus(state.StatusFile); err == nil {
+			state.Pid = status.Pid
+			state.Restarts = status.Restarts
+		}
+	}
+
 	process := api.ProcessDescription{
 		ID:       component.ID,
 		Name:     component.Name,
 		Provider: "unix",
 		EnvVars:  state.FullEnvironment,
 		Spec:     component.Spec,
+		Restarts: state.Restarts,
 	}
 
 	proc, err := psprocess.NewProcess(int32(state.Pid))
@@ -90,6 +101,26 @@ func GetProcessDescription(ctx context.Context, component api.ComponentDescripti
 		return nil
 	})
 
+	if state.Cgroup != "" {
+		eg.Go(func() error {
+			cgroup := &cgrouputil.Group{Dir: state.Cgroup}
+			stats, err := cgroup.Stats()
+			if err != nil {
+				// The cgroup may have been removed by an exiting supervisor, or may
+				// lack some controllers. The usage fields are left unknown, rather
+				// than failing, since the remainder of the description is useful.
+				logger.Infof("reading cgroup stats of %s: %v", component.Name, err)
+				return nil
+			}
+			cpuUsageSeconds := stats.CPUUsage.Seconds()
+			process.MemoryUsage = &stats.MemoryBytes
+			process.CPUUsageSeconds = &cpuUsageSeconds
+			process.Pids = &stats.Pids
+			process.OomKills = &stats.OOMKills
+			return nil
+		})
+	}
+
 	eg.Go(func() error {
 		cpuPercent, err := proc.CPUPercentWithContext(ctx)
 		if err != nil {
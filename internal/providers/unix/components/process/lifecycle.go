@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	core "github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/logparse"
+	"github.com/deref/exo/internal/supervise"
+	"github.com/deref/exo/internal/util/errutil"
 	"github.com/deref/exo/internal/util/jsonutil"
 	"github.com/deref/exo/internal/util/osutil"
 )
@@ -24,16 +28,33 @@ func (p *Process) Initialize(ctx context.Context, input *core.InitializeInput) (
 		return nil, fmt.Errorf("unmarshalling spec: %w", err)
 	}
 
+	if _, err := logparse.ParseFormat(spec.LogFormat); err != nil {
+		return nil, errutil.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := validateLimits(&spec); err != nil {
+		return nil, errutil.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	// Resolve spec into state.
 	p.State.Directory = spec.Directory
 	p.State.Program = spec.Program
 	p.State.Arguments = spec.Arguments
 	p.State.Environment = spec.Environment
 	p.State.ShutdownGracePeriodSeconds = spec.ShutdownGracePeriodSeconds
+	p.State.Readiness = spec.Readiness
+	p.State.RestartPolicy = spec.Restart
+	p.State.MaxRestarts = spec.MaxRestarts
+	p.State.LogFormat = spec.LogFormat
+	p.State.TTY = spec.TTY
+	p.State.CPULimit = spec.CPULimit
+	p.State.MemoryLimit = spec.MemoryLimit
+	p.State.PidsLimit = spec.PidsLimit
 
 	// Processes are started by default.
-	if err := p.start(ctx); err != nil {
-		return nil, err
+	if !input.Stopped {
+		if err := p.start(ctx); err != nil {
+			return nil, err
+		}
 	}
 	return &core.InitializeOutput{}, nil
 }
@@ -63,18 +84,51 @@ func (p *Process) Refresh(ctx context.Context, input *core.RefreshInput) (*core.
 	p.State.Arguments = spec.Arguments
 	p.State.Environment = spec.Environment
 	p.State.ShutdownGracePeriodSeconds = spec.ShutdownGracePeriodSeconds
+	p.State.Readiness = spec.Readiness
+	p.State.RestartPolicy = spec.Restart
+	p.State.MaxRestarts = spec.MaxRestarts
+	p.State.LogFormat = spec.LogFormat
+	p.State.TTY = spec.TTY
+	p.State.CPULimit = spec.CPULimit
+	p.State.MemoryLimit = spec.MemoryLimit
+	p.State.PidsLimit = spec.PidsLimit
 
 	p.refresh()
 	return &core.RefreshOutput{}, nil
 }
 
 func (p *Process) refresh() {
-	if osutil.IsValidPid(p.SupervisorPid) && osutil.IsValidPid(p.Pid) {
-		return
+	if osutil.IsValidPid(p.SupervisorPid) {
+		p.readStatus()
+		// A live supervisor with a dead child may be waiting to restart it.
+		if osutil.IsValidPid(p.Pid) || p.restarting() {
+			return
+		}
 	}
 	p.State.reset()
 }
 
+// readStatus updates state from the supervisor's status file, which tracks
+// the current child process across restarts.
+func (p *Process) readStatus() {
+	if p.StatusFile == "" {
+		return
+	}
+	status, err := supervise.ReadStatus(p.StatusFile)
+	if err != nil {
+		return
+	}
+	if status.Pid != 0 {
+		p.State.Pid = status.Pid
+	}
+	p.State.Restarts = status.Restarts
+}
+
+func (p *Process) restarting() bool {
+	policy := supervise.RestartPolicy(p.RestartPolicy)
+	return policy != "" && policy != supervise.RestartNever
+}
+
 func (p *Process) Dispose(ctx context.Context, input *core.DisposeInput) (*core.DisposeOutput, error) {
 	if err := p.stop(nil); err != nil {
 		return nil, err
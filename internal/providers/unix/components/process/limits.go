@@ -0,0 +1,59 @@
+package process
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/deref/exo/internal/util/cgrouputil"
+	"github.com/docker/go-units"
+)
+
+// ByteSize is given either as a number of bytes, or as a string with a unit
+// suffix, such as "512m".
+type ByteSize int64
+
+func (bs *ByteSize) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*bs = ByteSize(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("expected number of bytes or size string")
+	}
+	n, err := units.RAMInBytes(s)
+	if err != nil {
+		return err
+	}
+	*bs = ByteSize(n)
+	return nil
+}
+
+func validateLimits(spec *Spec) error {
+	if spec.CPULimit != nil && *spec.CPULimit <= 0 {
+		return fmt.Errorf("cpu_limit must be positive, got %v", *spec.CPULimit)
+	}
+	if spec.MemoryLimit != nil && *spec.MemoryLimit <= 0 {
+		return fmt.Errorf("memory_limit must be positive, got %d", *spec.MemoryLimit)
+	}
+	if spec.PidsLimit != nil && *spec.PidsLimit <= 0 {
+		return fmt.Errorf("pids_limit must be positive, got %d", *spec.PidsLimit)
+	}
+	return nil
+}
+
+func (state *State) limits() cgrouputil.Limits {
+	var limits cgrouputil.Limits
+	if state.CPULimit != nil {
+		limits.CPUs = *state.CPULimit
+	}
+	if state.MemoryLimit != nil {
+		limits.MemoryBytes = int64(*state.MemoryLimit)
+	}
+	if state.PidsLimit != nil {
+		limits.Pids = *state.PidsLimit
+	}
+	return limits
+}
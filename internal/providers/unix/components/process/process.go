@@ -8,12 +8,14 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"syscall"
 	"time"
 
 	core "github.com/deref/exo/internal/core/api"
 	"github.com/deref/exo/internal/supervise"
+	"github.com/deref/exo/internal/util/cgrouputil"
 	"github.com/deref/exo/internal/util/errutil"
 	"github.com/deref/exo/internal/util/osutil"
 	"github.com/deref/exo/internal/util/which"
@@ -74,14 +76,56 @@ func (p *Process) start(ctx context.Context) error {
 	}
 	p.State.FullEnvironment = envMap
 
-	// Pipe JSON config to supervise on stdin.
+	restart := supervise.RestartPolicy(p.RestartPolicy)
+	if err := restart.Validate(); err != nil {
+		return errutil.WithHTTPStatus(http.StatusBadRequest, err)
+	}
+	maxRestarts := 0
+	if p.MaxRestarts != nil {
+		maxRestarts = *p.MaxRestarts
+	}
+	p.State.StatusFile = ""
+	if p.VarDir != "" {
+		p.State.StatusFile = supervise.StatusPath(p.VarDir, p.ComponentID)
+		_ = os.Remove(p.State.StatusFile)
+	}
+	p.State.Restarts = 0
+	if p.TTY && p.VarDir != "" {
+		p.State.AttachSocket = filepath.Join(p.VarDir, "supervise", p.ComponentID+".sock")
+	}
+	limits := p.State.limits()
+	if !limits.IsZero() {
+		if cgrouputil.Supported() {
+			parent, err := cgrouputil.DefaultParent()
+			if err != nil {
+				return fmt.Errorf("locating cgroup: %w", err)
+			}
+			p.State.Cgroup = filepath.Join(parent, p.ComponentID)
+		} else {
+			p.Logger.Infof("ignoring resource limits of %s, since cgroup v2 is not available", p.ComponentID)
+			limits = cgrouputil.Limits{}
+		}
+	}
+
+	// Pipe JSON config to supervise on stdin. Logs are sent over tcp, which the
+	// syslog server also accepts on its port, since unlike udp, it does not drop
+	// messages when the server falls behind.
 	configJSON := supervise.MustEncodeConfig(&supervise.Config{
 		ComponentID:      p.ComponentID,
 		WorkingDirectory: p.WorkspaceRoot,
 		SyslogPort:       p.SyslogPort,
+		SyslogNetwork:    "tcp",
 		Environment:      envMap,
 		Program:          program,
 		Arguments:        p.Arguments,
+		Restart:          restart,
+		MaxRestarts:      maxRestarts,
+		StatusFile:       p.State.StatusFile,
+		LogFormat:        p.State.LogFormat,
+		TTY:              p.State.TTY,
+		AttachSocket:     p.State.AttachSocket,
+		Cgroup:           p.State.Cgroup,
+		Limits:           limits,
 	})
 	cmd.Stdin = bytes.NewBuffer(configJSON)
 
@@ -0,0 +1,55 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deref/exo/internal/supervise"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// Processes are started by running this binary's supervise command, which
+	// the test binary must therefore provide.
+	if len(os.Args) > 1 && os.Args[1] == "supervise" {
+		supervise.Main()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestStartLogsOverTCP(t *testing.T) {
+	// Only listen for tcp, so that messages sent over udp are never received.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	p := &Process{SyslogPort: uint(listener.Addr().(*net.TCPAddr).Port)}
+	p.ComponentID = "test"
+	p.WorkspaceRoot = t.TempDir()
+	p.Program = "sh"
+	p.Arguments = []string{"-c", "echo hello"}
+	require.NoError(t, p.start(context.Background()))
+	defer func() {
+		_ = p.stop(nil)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	require.NoError(t, listener.(*net.TCPListener).SetDeadline(deadline))
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(deadline))
+
+	var received []byte
+	buf := make([]byte, 4096)
+	for !bytes.Contains(received, []byte("hello")) {
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+		require.NoError(t, err, "received: %q", received)
+	}
+}
@@ -0,0 +1,136 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"time"
+
+	core "github.com/deref/exo/internal/core/api"
+	"github.com/deref/exo/internal/util/osutil"
+)
+
+var _ core.Readiness = (*Process)(nil)
+
+// Readiness configures a probe that must succeed before a process is
+// considered ready to serve the components that depend on it. At most one of
+// TCP, HTTP, or Command should be set. If none are set, a process is ready as
+// soon as it is running.
+type Readiness struct {
+	// Address to dial, such as "localhost:5432". A port number alone is dialed
+	// on localhost.
+	TCP string `json:"tcp,omitempty"`
+	// URL to GET. Any 2xx response is considered ready.
+	HTTP string `json:"http,omitempty"`
+	// Program and arguments to run. An exit status of 0 is considered ready.
+	Command []string `json:"command,omitempty"`
+
+	IntervalSeconds *int `json:"intervalSeconds,omitempty"`
+	TimeoutSeconds  *int `json:"timeoutSeconds,omitempty"`
+}
+
+const DefaultReadinessInterval = time.Second
+const DefaultReadinessTimeout = 60 * time.Second
+
+func (r *Readiness) interval() time.Duration {
+	if r.IntervalSeconds == nil || *r.IntervalSeconds <= 0 {
+		return DefaultReadinessInterval
+	}
+	return time.Duration(*r.IntervalSeconds) * time.Second
+}
+
+func (r *Readiness) timeout() time.Duration {
+	if r.TimeoutSeconds == nil {
+		return DefaultReadinessTimeout
+	}
+	return time.Duration(*r.TimeoutSeconds) * time.Second
+}
+
+func (p *Process) AwaitReady(ctx context.Context, input *core.AwaitReadyInput) (*core.AwaitReadyOutput, error) {
+	if err := p.awaitReady(ctx); err != nil {
+		return nil, err
+	}
+	return &core.AwaitReadyOutput{}, nil
+}
+
+func (p *Process) awaitReady(ctx context.Context) error {
+	readiness := p.State.Readiness
+	if readiness == nil {
+		readiness = &Readiness{}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readiness.timeout())
+	defer cancel()
+
+	ticker := time.NewTicker(readiness.interval())
+	defer ticker.Stop()
+
+	var err error
+	for {
+		p.readStatus()
+		if osutil.IsValidPid(p.Pid) {
+			probeCtx, cancelProbe := context.WithTimeout(ctx, readiness.interval())
+			err = p.probe(probeCtx, readiness)
+			cancelProbe()
+			if err == nil {
+				return nil
+			}
+		} else {
+			err = errors.New("process is not running")
+			if !p.restarting() {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out awaiting readiness: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Process) probe(ctx context.Context, readiness *Readiness) error {
+	switch {
+	case readiness.TCP != "":
+		addr := readiness.TCP
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort("localhost", addr)
+		}
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+
+	case readiness.HTTP != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, readiness.HTTP, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || 300 <= resp.StatusCode {
+			return fmt.Errorf("unexpected http status: %s", resp.Status)
+		}
+		return nil
+
+	case len(readiness.Command) > 0:
+		cmd := exec.CommandContext(ctx, readiness.Command[0], readiness.Command[1:]...)
+		cmd.Dir = p.Directory
+		if cmd.Dir == "" {
+			cmd.Dir = p.WorkspaceRoot
+		}
+		cmd.Env = osutil.EnvMapToEnvv(p.FullEnvironment)
+		return cmd.Run()
+
+	default:
+		return nil
+	}
+}
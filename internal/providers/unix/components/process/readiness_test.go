@@ -0,0 +1,96 @@
+package process
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// Returns a process whose pid is the test process itself, so that it is
+// considered running.
+func runningProcess(readiness *Readiness) *Process {
+	p := &Process{}
+	p.Pid = os.Getpid()
+	p.Readiness = readiness
+	return p
+}
+
+func TestAwaitReadyTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	p := runningProcess(&Readiness{TCP: "127.0.0.1:" + port})
+	assert.NoError(t, p.awaitReady(context.Background()))
+}
+
+func TestAwaitReadyHTTP(t *testing.T) {
+	// Written by the server's handler goroutine.
+	var ready atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !ready.Swap(true) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	p := runningProcess(&Readiness{HTTP: server.URL})
+	assert.NoError(t, p.awaitReady(context.Background()))
+	assert.True(t, ready.Load())
+}
+
+func TestAwaitReadyCommand(t *testing.T) {
+	p := runningProcess(&Readiness{Command: []string{"true"}})
+	assert.NoError(t, p.awaitReady(context.Background()))
+}
+
+func TestAwaitReadyTimeout(t *testing.T) {
+	// Find a port that nothing is listening on.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	p := runningProcess(&Readiness{
+		TCP:            strconv.Itoa(port),
+		TimeoutSeconds: intPtr(1),
+	})
+	err = p.awaitReady(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out awaiting readiness")
+	}
+}
+
+func TestAwaitReadyNotRunning(t *testing.T) {
+	p := &Process{}
+	p.Pid = 0
+	err := p.awaitReady(context.Background())
+	assert.EqualError(t, err, "process is not running")
+}
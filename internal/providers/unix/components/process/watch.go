@@ -0,0 +1,50 @@
+package process
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/deref/exo/internal/filewatch"
+	"github.com/moby/moby/pkg/signal"
+)
+
+// NewWatcher constructs a file watcher for a process spec. Returns nil if the
+// spec does not request watching.
+func NewWatcher(workspaceRoot string, spec *Spec, onChange func(paths []string)) (*filewatch.Watcher, error) {
+	watch := spec.Watch
+	if watch == nil {
+		return nil, nil
+	}
+	if watch.Signal != "" {
+		if _, err := signal.ParseSignal(watch.Signal); err != nil {
+			return nil, fmt.Errorf("invalid watch signal: %w", err)
+		}
+	}
+	include, err := filewatch.CompileGlobs(watch.Include)
+	if err != nil {
+		return nil, fmt.Errorf("compiling include globs: %w", err)
+	}
+	excludePatterns := make([]string, 0, len(filewatch.DefaultExclude)+len(watch.Exclude))
+	excludePatterns = append(excludePatterns, filewatch.DefaultExclude...)
+	excludePatterns = append(excludePatterns, watch.Exclude...)
+	exclude, err := filewatch.CompileGlobs(excludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("compiling exclude globs: %w", err)
+	}
+	debounce := filewatch.DefaultDebounce
+	if watch.DebounceMilliseconds != nil {
+		debounce = time.Duration(*watch.DebounceMilliseconds) * time.Millisecond
+	}
+	root := spec.Directory
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(workspaceRoot, root)
+	}
+	return &filewatch.Watcher{
+		Root:     root,
+		Include:  include,
+		Exclude:  exclude,
+		Debounce: debounce,
+		OnChange: onChange,
+	}, nil
+}
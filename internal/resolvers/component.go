@@ -32,6 +32,7 @@ type ComponentRow struct {
 	RawModel             RawJSON    `db:"model"`
 	EnvironmentVariables JSONObject `db:"environment_variables"`
 	Disposed             *Instant   `db:"disposed"`
+	RawProfiles          RawJSON    `db:"profiles"`
 }
 
 func (r *QueryResolver) ComponentByID(ctx context.Context, args struct {
@@ -98,21 +99,6 @@ func (r *QueryResolver) componentByRef(ctx context.Context, ref string, stack *s
 	return component, err
 }
 
-func (r *QueryResolver) componentByResourceID(ctx context.Context, resourceID *string) (*ComponentResolver, error) {
-	component := &ComponentResolver{
-		Q: r,
-	}
-	err := r.getRowByKey(ctx, &component.ComponentRow, `
-		SELECT *
-		FROM component
-		WHERE resource_id = ?
-	`, resourceID)
-	if component.ID == "" {
-		component = nil
-	}
-	return component, err
-}
-
 type componentSetResolver struct {
 	Q         *RootResolver
 	StackID   string
@@ -211,7 +197,7 @@ func (r *MutationResolver) CreateComponent(ctx context.Context, args struct {
 	} else {
 		definition.Environment = *args.Environment
 	}
-	row, err := r.createComponent(ctx, stack.ID /* parentID: */, nil, definition)
+	row, err := r.createComponent(ctx, r.db, stack.ID /* parentID: */, nil, definition)
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +214,8 @@ type ComponentDefinition struct {
 	Key         string
 	Spec        CueValue
 	Environment JSONObject
+	// Profiles that the component is assigned to by its manifest.
+	Profiles []string
 }
 
 // Composite-key for uniquely identifying components within a parent.  If a
@@ -242,9 +230,15 @@ func (def ComponentDefinition) Ident() string {
 	return fmt.Sprintf("%s:%s:%s", def.Type, def.Name, key)
 }
 
-func (r *MutationResolver) createComponent(ctx context.Context, stackID string, parentID *string, def ComponentDefinition) (*ComponentResolver, error) {
+func (r *MutationResolver) createComponent(ctx context.Context, db dbtx, stackID string, parentID *string, def ComponentDefinition) (*ComponentResolver, error) {
 	// TODO: Validate type, name, & key.
 
+	// The spec is CUE, but the model is JSON, so it must not be marshalled as a
+	// CueValue, which encodes as a string of CUE source.
+	model, err := cue.Value(def.Spec).MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("encoding model: %w", err)
+	}
 	row := ComponentRow{
 		ID:       gensym.RandomBase32(),
 		StackID:  stackID,
@@ -253,9 +247,12 @@ func (r *MutationResolver) createComponent(ctx context.Context, stackID string,
 		Type:     def.Type,
 		Key:      def.Key,
 		Spec:     def.Spec,
-		RawModel: jsonutil.MustMarshal(def.Spec),
+		RawModel: model,
+	}
+	if len(def.Profiles) > 0 {
+		row.RawProfiles = jsonutil.MustMarshal(def.Profiles)
 	}
-	if err := r.insertRow(ctx, "component", row); err != nil {
+	if err := insertRowEx(ctx, db, "component", row, ""); err != nil {
 		if isSqlConflict(err) {
 			return nil, conflictErrorf("a component named %q already exists", row.Name)
 		}
@@ -288,7 +285,7 @@ func (r *MutationResolver) UpdateComponent(ctx context.Context, args struct {
 		name = *args.NewName
 	}
 
-	component, err = r.updateComponent(ctx, component.ID, name, spec)
+	component, err = r.updateComponent(ctx, r.db, component.ID, name, spec)
 	if err != nil {
 		return nil, err
 	}
@@ -300,11 +297,11 @@ func (r *MutationResolver) UpdateComponent(ctx context.Context, args struct {
 	return reconciliation, err
 }
 
-func (r *MutationResolver) updateComponent(ctx context.Context, id string, name string, spec CueValue) (*ComponentResolver, error) {
+func (r *MutationResolver) updateComponent(ctx context.Context, db dbtx, id string, name string, spec CueValue) (*ComponentResolver, error) {
 	// TODO: Validate name.
 
 	var row ComponentRow
-	if err := r.db.GetContext(ctx, &row, `
+	if err := db.GetContext(ctx, &row, `
 		UPDATE component
 		SET spec = ?, name = ?
 		WHERE id = ?
@@ -318,6 +315,19 @@ func (r *MutationResolver) updateComponent(ctx context.Context, id string, name
 	}, nil
 }
 
+func setComponentProfiles(ctx context.Context, db dbtx, id string, profiles []string) error {
+	var raw RawJSON
+	if len(profiles) > 0 {
+		raw = jsonutil.MustMarshal(profiles)
+	}
+	_, err := db.ExecContext(ctx, `
+		UPDATE component
+		SET profiles = ?
+		WHERE id = ?
+	`, raw, id)
+	return err
+}
+
 func (r *MutationResolver) DestroyComponents(ctx context.Context, args struct {
 	Stack *string
 	Refs  []string
@@ -343,17 +353,17 @@ func (r *MutationResolver) DestroyComponent(ctx context.Context, args struct {
 	if err := validateResolve("component", args.Ref, component, err); err != nil {
 		return nil, err
 	}
-	component, err = r.disposeComponent(ctx, component.ID)
+	component, err = r.disposeComponent(ctx, r.db, component.ID)
 	if err != nil {
 		return nil, err
 	}
 	return r.startComponentReconciliation(ctx, component)
 }
 
-func (r *MutationResolver) disposeComponent(ctx context.Context, id string) (*ComponentResolver, error) {
+func (r *MutationResolver) disposeComponent(ctx context.Context, db dbtx, id string) (*ComponentResolver, error) {
 	now := Now(ctx)
 	var row ComponentRow
-	if err := r.db.GetContext(ctx, &row, `
+	if err := db.GetContext(ctx, &row, `
 		UPDATE component
 		SET disposed = COALESCE(disposed, ?)
 		WHERE id IN (
@@ -404,6 +414,15 @@ func (r *ComponentResolver) Model() (obj JSONObject, err error) {
 	return
 }
 
+func (r *ComponentResolver) Profiles() ([]string, error) {
+	profiles := []string{}
+	if len(r.RawProfiles) == 0 {
+		return profiles, nil
+	}
+	err := json.Unmarshal(r.RawProfiles, &profiles)
+	return profiles, err
+}
+
 func (r *ComponentResolver) Configuration(ctx context.Context, args struct {
 	Recursive *bool
 	Final     *bool
@@ -448,6 +467,23 @@ func (r *ComponentResolver) configuration(ctx context.Context, recursive, final
 			return nil, fmt.Errorf("resolving model: %w", err)
 		}
 		component["model"] = model
+
+		resources, err := r.Resources(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolving resources: %w", err)
+		}
+		resourceConfigs := make(map[string]any, len(resources))
+		for _, resource := range resources {
+			resourceConfig := map[string]any{
+				"id":   resource.ID,
+				"type": resource.Type,
+			}
+			if resource.IRI != nil && *resource.IRI != "" {
+				resourceConfig["iri"] = *resource.IRI
+			}
+			resourceConfigs[resource.ID] = resourceConfig
+		}
+		component["resources"] = resourceConfigs
 	}
 
 	if recursive {
@@ -481,6 +517,8 @@ func (r *ComponentResolver) finalConfiguration(ctx context.Context) (*sdk.Compon
 	if err := cueutil.EncodeValue(value).Decode(&result); err != nil {
 		return nil, fmt.Errorf("decoding: %w", err)
 	}
+	// Decoding does not populate fields of type cue.Value.
+	result.RawSpec = cue.Value(r.Spec)
 	return result, nil
 }
 
@@ -570,21 +608,65 @@ func (r *MutationResolver) handleChildrenUpdated(ctx context.Context, component
 	})
 }
 
-func (r *MutationResolver) shutdownComponent(ctx context.Context, id string) (*ComponentResolver, error) {
-	return r.controlComponentByID(ctx, id, func(controller sdk.AComponentController, cfg *sdk.ComponentConfig, model *RawJSON) error {
-		// XXX if there are still children, abort and try again later.
-		// after done, trigger reconciliation of parent.
-		// ^^^ actually, this doesn't make sense, the parent reconcilliation should wait?
-		return errors.New("TODO: shutdown component")
+func (r *MutationResolver) shutdownComponent(ctx context.Context, component *ComponentResolver) (*ComponentResolver, error) {
+	return r.controlComponent(ctx, component, func(controller sdk.AComponentController, cfg *sdk.ComponentConfig, model *RawJSON) error {
+		return controller.ShutdownComponent(ctx, cfg, model)
 	})
 }
 
+// Deletes the external resources of a disposed component, then the component
+// itself. The component should already have been shutdown.
+func (r *MutationResolver) deleteComponent(ctx context.Context, component *ComponentResolver) error {
+	if _, err := r.controlComponent(ctx, component, func(controller sdk.AComponentController, cfg *sdk.ComponentConfig, model *RawJSON) error {
+		return controller.DeleteComponent(ctx, cfg, model)
+	}); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM component
+		WHERE id = ?
+	`, component.ID); err != nil {
+		return fmt.Errorf("deleting row: %w", err)
+	}
+	return nil
+}
+
+func (r *QueryResolver) disposedComponentsByParent(ctx context.Context, parentID string) ([]*ComponentResolver, error) {
+	var rows []ComponentRow
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT *
+		FROM component
+		WHERE parent_id = ?
+		AND disposed IS NOT NULL
+		ORDER BY name ASC
+	`, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return componentRowsToResolvers(r, rows), nil
+}
+
 func (r *ComponentResolver) Reconciling() bool {
 	return false // XXX
 }
 
-func (r *ComponentResolver) Running() bool {
-	return true // XXX
+func (r *ComponentResolver) Running(ctx context.Context) (bool, error) {
+	if process := r.Q.processFromComponent(r); process != nil {
+		return process.Running(ctx)
+	}
+	return true, nil // XXX
+}
+
+func (r *ComponentResolver) Stream() *StreamResolver {
+	return r.Q.streamForSource("Component", r.ID)
+}
+
+func (r *ComponentResolver) eventPrototype(ctx context.Context) (EventRow, error) {
+	return EventRow{
+		SourceType:  "Component",
+		StackID:     &r.StackID,
+		ComponentID: &r.ID,
+	}, nil
 }
 
 func (r *ComponentResolver) AsProcess(ctx context.Context) *ProcessComponentResolver {
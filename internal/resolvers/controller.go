@@ -2,29 +2,114 @@ package resolvers
 
 import (
 	"context"
+	"path/filepath"
 
+	"github.com/deref/exo/internal/config"
+	"github.com/deref/exo/internal/controllers"
+	compose "github.com/deref/exo/internal/providers/docker/components"
+	docker "github.com/deref/exo/internal/providers/docker/resources"
 	"github.com/deref/exo/internal/providers/os"
+	"github.com/deref/exo/internal/util/atom"
+	"github.com/deref/exo/internal/util/logging"
 	"github.com/deref/exo/sdk"
 )
 
-// TODO: Dynamic registry with qualified type identifiers.
+// Registers the controllers that are compiled in to exo. Components created
+// before type names were qualified refer to these types by their aliases.
+func (r *RootResolver) registerBuiltinControllers() error {
+	type builtin struct {
+		Type      string
+		Alias     string
+		Component sdk.AComponentController
+		Resource  sdk.AResourceController
+	}
+	file := os.NewFileController(r.Service)
+	process := os.NewProcessController(r.Service)
+	container := docker.NewContainerController(r.Service, r.Docker)
+	volume := docker.NewVolumeController(r.Service, r.Docker)
+	network := docker.NewNetworkController(r.Service, r.Docker)
+	composeContainer := compose.NewContainerController(r.Service, r.Docker)
+	composeVolume := compose.NewVolumeController(r.Service, r.Docker)
+	composeNetwork := compose.NewNetworkController(r.Service, r.Docker)
+	builtins := []builtin{
+		{"deref.io/os/daemon", "daemon", os.NewDaemonController(r.Service), nil},
+		{"deref.io/os/file", "file", file, file},
+		{"deref.io/os/process", "process", process, process},
+		// Unaliased, since "container", "volume", and "network" refer to the
+		// compose-derived component types.
+		{docker.ContainerType, "", container, container},
+		{docker.VolumeType, "", volume, volume},
+		{docker.NetworkType, "", network, network},
+		{compose.ContainerType, "container", composeContainer, composeContainer},
+		{compose.VolumeType, "volume", composeVolume, composeVolume},
+		{compose.NetworkType, "network", composeNetwork, composeNetwork},
+	}
+	for _, b := range builtins {
+		if err := r.Controllers.RegisterComponent(b.Type, b.Component); err != nil {
+			return err
+		}
+		if b.Resource != nil {
+			if err := r.Controllers.RegisterResource(b.Type, b.Resource); err != nil {
+				return err
+			}
+		}
+		if b.Alias != "" {
+			r.Controllers.Alias(b.Alias, b.Type)
+		}
+	}
+	return nil
+}
 
-func (r *QueryResolver) componentControllerByType(ctx context.Context, typ string) sdk.AComponentController {
-	switch typ {
-	case "daemon":
-		return os.NewDaemonController(r.Service)
-	default:
-		return r.resourceControllerByType(ctx, typ)
+// Plugins are started by the daemon, which records their sockets so that
+// other peers can connect to them.
+func (r *RootResolver) startPlugins(ctx context.Context) {
+	sockets := atom.NewFileAtom(filepath.Join(r.VarDir, "plugins.json"), atom.CodecJSON)
+	if r.Daemon {
+		r.plugins = controllers.StartPlugins(ctx, r.Controllers, r.Service, r.Plugins)
+		running := make([]pluginSocket, len(r.plugins))
+		for i, plugin := range r.plugins {
+			running[i] = pluginSocket{
+				Config: plugin.Config,
+				Socket: plugin.SocketPath,
+			}
+		}
+		if err := sockets.Reset(running); err != nil {
+			logging.Infof(ctx, "error recording plugin sockets: %v", err)
+		}
+		return
+	}
+
+	var running []pluginSocket
+	if err := sockets.Deref(&running); err != nil {
+		logging.Infof(ctx, "error reading plugin sockets: %v", err)
+		return
+	}
+	for _, socket := range running {
+		plugin, err := controllers.ConnectPlugin(ctx, r.Controllers, r.Service, socket.Config, socket.Socket)
+		if err != nil {
+			logging.Infof(ctx, "error connecting to plugin %q: %v", socket.Config.Path, err)
+			continue
+		}
+		r.plugins = append(r.plugins, plugin)
 	}
 }
 
-func (r *QueryResolver) resourceControllerByType(ctx context.Context, typ string) *sdk.ResourceComponentController {
-	switch typ {
-	case "file":
-		return os.NewFileController(r.Service)
-	case "process":
-		return os.NewProcessController(r.Service)
-	default:
-		return nil
+type pluginSocket struct {
+	Config config.PluginConfig `json:"config"`
+	Socket string              `json:"socket"`
+}
+
+func (r *RootResolver) stopPlugins() {
+	for _, plugin := range r.plugins {
+		plugin.Stop()
 	}
+	r.plugins = nil
+}
+
+func (r *QueryResolver) componentControllerByType(ctx context.Context, typ string) sdk.AComponentController {
+	return r.Controllers.ComponentController(typ)
+}
+
+func (r *QueryResolver) resourceControllerByType(ctx context.Context, typ string) sdk.AResourceController {
+	return r.Controllers.ResourceController(typ)
 }
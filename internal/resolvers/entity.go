@@ -32,6 +32,8 @@ func (r *QueryResolver) findEntity(ctx context.Context, typ string, id string) (
 	switch typ {
 	case "System":
 		res.Underlying = r.System()
+	case "Component":
+		res.Underlying, err = r.componentByID(ctx, &id)
 	case "Task":
 		res.Underlying, err = r.taskByID(ctx, &id)
 	default:
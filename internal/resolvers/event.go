@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/logparse"
 	. "github.com/deref/exo/internal/scalars"
 	"github.com/deref/exo/internal/util/mathutil"
 )
@@ -120,6 +121,20 @@ type eventFilter struct {
 	JobID       string
 	TaskID      string
 	IContains   string
+	Tags        logparse.Filter
+}
+
+// Arguments for the source and message conditions shared by event queries.
+func (filter eventFilter) whereArgs() []any {
+	return []any{
+		filter.System,
+		filter.WorkspaceID, filter.WorkspaceID,
+		filter.StackID, filter.StackID,
+		filter.ComponentID, filter.ComponentID,
+		filter.JobID, filter.JobID,
+		filter.TaskID, filter.TaskID,
+		filter.IContains,
+	}
 }
 
 type EventPageResolver struct {
@@ -154,6 +169,7 @@ func (r *QueryResolver) findEvents(ctx context.Context, q eventQuery) (*EventPag
 	if before == (ULID{}) {
 		before = InfiniteULID
 	}
+	tagsExpr, tagsArgs := filter.Tags.SQL("tags")
 
 	var query string
 	if reverse {
@@ -169,6 +185,7 @@ func (r *QueryResolver) findEvents(ctx context.Context, q eventQuery) (*EventPag
 				OR (? != '' AND task_id = ?)
 			)
 			AND instr(lower(message), ?) <> 0
+			AND ` + tagsExpr + `
 			AND ulid BETWEEN ? AND ?
 			ORDER BY ulid DESC
 			LIMIT ?
@@ -187,23 +204,18 @@ func (r *QueryResolver) findEvents(ctx context.Context, q eventQuery) (*EventPag
 				OR (? != '' AND task_id = ?)
 			)
 			AND instr(lower(message), ?) <> 0
+			AND ` + tagsExpr + `
 			AND ulid BETWEEN ? AND ?
 			ORDER BY ulid ASC
 			LIMIT ?
 		`
 		after = ULIDMax(after, cursor)
 	}
+	args := filter.whereArgs()
+	args = append(args, tagsArgs...)
+	args = append(args, after.String(), before.String(), limit)
 	var rows []EventRow
-	if err := r.db.SelectContext(ctx, &rows, query,
-		filter.System,
-		filter.WorkspaceID, filter.WorkspaceID,
-		filter.StackID, filter.StackID,
-		filter.ComponentID, filter.ComponentID,
-		filter.JobID, filter.JobID,
-		filter.TaskID, filter.TaskID,
-		filter.IContains,
-		after.String(), before.String(), limit,
-	); err != nil {
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
 		return nil, fmt.Errorf("querying: %w", err)
 	}
 
@@ -254,6 +266,7 @@ func (r *QueryResolver) latestEventCursor(ctx context.Context, filter eventFilte
 }
 
 func (r *QueryResolver) latestEvent(ctx context.Context, filter eventFilter) (*EventResolver, error) {
+	tagsExpr, tagsArgs := filter.Tags.SQL("tags")
 	var row EventRow
 	err := r.db.GetContext(ctx, &row, `
 		SELECT *
@@ -267,17 +280,10 @@ func (r *QueryResolver) latestEvent(ctx context.Context, filter eventFilter) (*E
 			OR (? != '' AND task_id = ?)
 		)
 		AND instr(lower(message), ?) <> 0
+		AND `+tagsExpr+`
 		ORDER BY ulid DESC
 		LIMIT 1
-	`,
-		filter.System,
-		filter.WorkspaceID, filter.WorkspaceID,
-		filter.StackID, filter.StackID,
-		filter.ComponentID, filter.ComponentID,
-		filter.JobID, filter.JobID,
-		filter.TaskID, filter.TaskID,
-		filter.IContains,
-	)
+	`, append(filter.whereArgs(), tagsArgs...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -364,6 +370,8 @@ func (r *EventResolver) SourceID() string {
 	switch r.SourceType {
 	case "System":
 		return "SYSTEM"
+	case "Component":
+		return *r.ComponentID
 	case "Job":
 		return *r.JobID
 	case "Task":
@@ -0,0 +1,137 @@
+package resolvers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"cuelang.org/go/cue"
+	composeexport "github.com/deref/exo/internal/manifest/compose"
+	"github.com/deref/exo/internal/manifest/exohcl"
+	"github.com/deref/exo/internal/manifest/procfile"
+	compose "github.com/deref/exo/internal/providers/docker/components"
+	dockercompose "github.com/deref/exo/internal/providers/docker/compose"
+	"github.com/deref/exo/internal/providers/os"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/errutil"
+	"gopkg.in/yaml.v3"
+)
+
+func (r *StackResolver) ExportManifest(ctx context.Context, args struct {
+	Format string
+}) (string, error) {
+	components, err := r.components(ctx)
+	if err != nil {
+		return "", fmt.Errorf("resolving components: %w", err)
+	}
+	var out bytes.Buffer
+	switch args.Format {
+	case "compose":
+		err = r.exportCompose(&out, components)
+	case "procfile":
+		err = r.exportProcfile(&out, components)
+	default:
+		return "", errutil.HTTPErrorf(http.StatusBadRequest, "cannot export manifest of format %q", args.Format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func (r *StackResolver) exportCompose(out *bytes.Buffer, components []*ComponentResolver) error {
+	var project dockercompose.Project
+	var processes []composeexport.Process
+	for _, component := range components {
+		profiles, err := component.Profiles()
+		if err != nil {
+			return fmt.Errorf("decoding profiles of %q: %w", component.Name, err)
+		}
+		// Specs are decoded without interpolation, so that variable references
+		// are preserved in the export.
+		spec, err := specYAML(component.Spec)
+		if err != nil {
+			return fmt.Errorf("encoding spec of %q: %w", component.Name, err)
+		}
+		switch r.Q.Controllers.QualifyType(component.Type) {
+		case compose.ContainerType:
+			var service dockercompose.Service
+			err = yaml.Unmarshal(spec, &service)
+			service.Key = component.Name
+			for _, profile := range profiles {
+				service.Profiles = append(service.Profiles, dockercompose.MakeString(profile))
+			}
+			project.Services = append(project.Services, service)
+		case compose.VolumeType:
+			var volume dockercompose.Volume
+			err = yaml.Unmarshal(spec, &volume)
+			volume.Key = component.Name
+			project.Volumes = append(project.Volumes, volume)
+		case compose.NetworkType:
+			var network dockercompose.Network
+			err = yaml.Unmarshal(spec, &network)
+			network.Key = component.Name
+			project.Networks = append(project.Networks, network)
+		case "deref.io/os/process":
+			var process os.ProcessSpec
+			err = cue.Value(component.Spec).Decode(&process)
+			processes = append(processes, composeexport.Process{
+				Name:        component.Name,
+				Directory:   process.Directory,
+				Program:     process.Program,
+				Arguments:   process.Arguments,
+				Environment: process.Environment,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("decoding spec of %s %q: %w", component.Type, component.Name, err)
+		}
+	}
+
+	exporter := &composeexport.Exporter{
+		ProjectName: exohcl.MangleName(r.Name),
+	}
+	if err := exporter.Export(out, &project, processes); err != nil {
+		return fmt.Errorf("generating compose file: %w", err)
+	}
+	return nil
+}
+
+func (r *StackResolver) exportProcfile(out *bytes.Buffer, components []*ComponentResolver) error {
+	var processes []procfile.Process
+	for _, component := range components {
+		if r.Q.Controllers.QualifyType(component.Type) != "deref.io/os/process" {
+			continue
+		}
+		var process os.ProcessSpec
+		if err := cue.Value(component.Spec).Decode(&process); err != nil {
+			return fmt.Errorf("decoding spec of %q: %w", component.Name, err)
+		}
+		processes = append(processes, procfile.Process{
+			Name:        component.Name,
+			Program:     process.Program,
+			Arguments:   process.Arguments,
+			Environment: process.Environment,
+		})
+	}
+
+	// The original order of an imported Procfile is not recorded, so produce a
+	// stable order instead.
+	procfile.Organize(&processes)
+
+	if err := procfile.Generate(out, processes); err != nil {
+		return fmt.Errorf("generating procfile: %w", err)
+	}
+	return nil
+}
+
+// Re-encodes a spec as YAML. The compose types preserve the style of scalars,
+// so decoding the JSON form of a spec would quote every string in the export.
+func specYAML(spec CueValue) ([]byte, error) {
+	var v any
+	if err := cue.Value(spec).Decode(&v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
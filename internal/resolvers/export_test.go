@@ -0,0 +1,60 @@
+package resolvers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/deref/exo/internal/controllers"
+	compose "github.com/deref/exo/internal/providers/docker/components"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCompose(t *testing.T) {
+	stack := &StackResolver{
+		Q:        &QueryResolver{Controllers: controllers.NewRegistry()},
+		StackRow: StackRow{Name: "testproj"},
+	}
+	component := func(typ, name string, spec map[string]any, profiles RawJSON) *ComponentResolver {
+		return &ComponentResolver{
+			ComponentRow: ComponentRow{
+				Type:        typ,
+				Name:        name,
+				Spec:        EncodeCueValue(spec),
+				RawProfiles: profiles,
+			},
+		}
+	}
+	components := []*ComponentResolver{
+		component(compose.ContainerType, "web", map[string]any{
+			"image":          "nginx",
+			"container_name": "testproj_web_1",
+		}, RawJSON(`["frontend"]`)),
+		component(compose.VolumeType, "data", map[string]any{
+			"name": "testproj_data",
+		}, nil),
+		component("deref.io/os/process", "worker", map[string]any{
+			"program": "node",
+		}, nil),
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, stack.exportCompose(&buf, components)) {
+		return
+	}
+	assert.Equal(t, strings.TrimSpace(`
+services:
+  web:
+    image: nginx
+    profiles:
+      - frontend
+volumes:
+  data:
+    name: testproj_data
+x-exo:
+  processes:
+    worker:
+      program: node
+`), strings.TrimSpace(buf.String()))
+}
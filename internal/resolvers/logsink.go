@@ -0,0 +1,326 @@
+package resolvers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/deref/exo/internal/config"
+	"github.com/deref/exo/internal/logsink"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/internal/util/jsonutil"
+)
+
+type LogSinkResolver struct {
+	cfg config.LogSinkConfig
+}
+
+func (r *LogSinkResolver) Type() string {
+	return r.cfg.Type
+}
+
+func (r *LogSinkResolver) Endpoint() *string {
+	return emptyToNil(r.cfg.Endpoint)
+}
+
+func (r *LogSinkResolver) Headers() *JSONObject {
+	if len(r.cfg.Headers) == 0 {
+		return nil
+	}
+	headers := make(JSONObject, len(r.cfg.Headers))
+	for k, v := range r.cfg.Headers {
+		headers[k] = v
+	}
+	return &headers
+}
+
+func (r *LogSinkResolver) Path() *string {
+	return emptyToNil(r.cfg.Path)
+}
+
+func (r *LogSinkResolver) MaxBytes() *int32 {
+	return zeroToNil(int32(r.cfg.MaxBytes))
+}
+
+func (r *LogSinkResolver) MaxFiles() *int32 {
+	return zeroToNil(int32(r.cfg.MaxFiles))
+}
+
+func emptyToNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func zeroToNil(i int32) *int32 {
+	if i == 0 {
+		return nil
+	}
+	return &i
+}
+
+type LogSinkInput struct {
+	Type     string
+	Endpoint *string
+	Headers  *JSONObject
+	Path     *string
+	MaxBytes *int32
+	MaxFiles *int32
+}
+
+// Only the daemon exports events, so that each event is exported once. Since
+// every peer records events, the daemon reads them back from the database.
+func (r *RootResolver) initLogSinks(ctx context.Context) error {
+	if !r.Daemon {
+		return nil
+	}
+	global, err := logsink.NewAll(r.LogSinks)
+	if err != nil {
+		return fmt.Errorf("configuring global log sinks: %w", err)
+	}
+	r.logSinks = logsink.NewForwarder(ctx, r.SystemLog, global)
+	r.workspaceLogSinks = make(map[string]string)
+	if err := r.syncWorkspaceLogSinks(ctx); err != nil {
+		return err
+	}
+
+	// Export only events recorded from now on.
+	var latest ULID
+	if err := r.db.GetContext(ctx, &latest, `
+		SELECT ulid
+		FROM event
+		ORDER BY ulid DESC
+		LIMIT 1
+	`); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("querying latest event: %w", err)
+	}
+	cursor := IncrementULID(latest)
+
+	ctx, r.stopEventExport = context.WithCancel(ctx)
+	r.eventExportDone = make(chan struct{})
+	go func() {
+		defer close(r.eventExportDone)
+		ticker := time.NewTicker(eventExportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				var err error
+				cursor, err = r.exportEvents(ctx, cursor)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					r.SystemLog.Infof("exporting events: %v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *RootResolver) stopLogSinks() {
+	if r.logSinks == nil {
+		return
+	}
+	r.stopEventExport()
+	<-r.eventExportDone
+	r.logSinks.Close()
+}
+
+const (
+	eventExportInterval = time.Second
+	eventExportPageSize = 1000
+	// Events are exported once they are at least this old, so that events
+	// committed late by other peers are not skipped.
+	eventExportLag = time.Second
+)
+
+// Forwards the events after cursor to the log sinks, returning the cursor of
+// the next unexported event.
+func (r *RootResolver) exportEvents(ctx context.Context, cursor ULID) (ULID, error) {
+	if err := r.syncWorkspaceLogSinks(ctx); err != nil {
+		return cursor, err
+	}
+	before := InstantToULID(GoTimeToInstant(Now(ctx).GoTime().Add(-eventExportLag)))
+	for {
+		var rows []EventRow
+		if err := r.db.SelectContext(ctx, &rows, `
+			SELECT *
+			FROM event
+			WHERE ulid >= ? AND ulid < ?
+			ORDER BY ulid ASC
+			LIMIT ?
+		`, cursor.String(), before.String(), eventExportPageSize); err != nil {
+			return cursor, fmt.Errorf("querying events: %w", err)
+		}
+		for _, row := range rows {
+			r.forwardEvent(row)
+		}
+		if len(rows) > 0 {
+			cursor = IncrementULID(rows[len(rows)-1].ULID)
+		}
+		if len(rows) < eventExportPageSize {
+			return cursor, nil
+		}
+		// Avoid overflowing the forwarder's queue.
+		r.logSinks.Flush()
+	}
+}
+
+// Configures the sinks of each workspace, which may be changed by any peer.
+func (r *RootResolver) syncWorkspaceLogSinks(ctx context.Context) error {
+	workspaces, err := r.AllWorkspaces(ctx)
+	if err != nil {
+		return fmt.Errorf("querying workspaces: %w", err)
+	}
+	live := make(map[string]bool, len(workspaces))
+	for _, workspace := range workspaces {
+		live[workspace.ID] = true
+		raw := string(workspace.RawLogSinks)
+		if prev, ok := r.workspaceLogSinks[workspace.ID]; ok && prev == raw {
+			continue
+		}
+		r.workspaceLogSinks[workspace.ID] = raw
+		cfgs, err := workspace.logSinkConfigs()
+		if err != nil {
+			r.SystemLog.Infof("decoding log sinks of workspace %s: %v", workspace.ID, err)
+			continue
+		}
+		sinks, err := logsink.NewAll(cfgs)
+		if err != nil {
+			// Don't stop exporting, since the workspace can be reconfigured.
+			r.SystemLog.Infof("configuring log sinks of workspace %s: %v", workspace.ID, err)
+			continue
+		}
+		r.logSinks.SetWorkspaceSinks(workspace.ID, sinks)
+	}
+	for id := range r.workspaceLogSinks {
+		if !live[id] {
+			delete(r.workspaceLogSinks, id)
+			r.logSinks.SetWorkspaceSinks(id, nil)
+		}
+	}
+	return nil
+}
+
+// Enqueues an event for export to the log sinks.
+func (r *RootResolver) forwardEvent(row EventRow) {
+	event := logsink.Event{
+		Resource: logsink.Resource{
+			WorkspaceID: stringOrEmpty(row.WorkspaceID),
+			StackID:     stringOrEmpty(row.StackID),
+			ComponentID: stringOrEmpty(row.ComponentID),
+		},
+		Timestamp: row.Timestamp().GoTime(),
+		Type:      row.Type,
+		Message:   row.Message,
+	}
+	if len(row.Tags) > 0 {
+		event.Tags = make(map[string]string, len(row.Tags))
+		for k, v := range row.Tags {
+			if s, ok := v.(string); ok {
+				event.Tags[k] = s
+			} else {
+				event.Tags[k] = jsonutil.MustMarshalString(v)
+			}
+		}
+	}
+	r.logSinks.Send(event)
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (r *WorkspaceResolver) logSinkConfigs() ([]config.LogSinkConfig, error) {
+	var cfgs []config.LogSinkConfig
+	if len(r.RawLogSinks) == 0 {
+		return cfgs, nil
+	}
+	err := json.Unmarshal(r.RawLogSinks, &cfgs)
+	return cfgs, err
+}
+
+func (r *WorkspaceResolver) LogSinks() ([]*LogSinkResolver, error) {
+	cfgs, err := r.logSinkConfigs()
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*LogSinkResolver, len(cfgs))
+	for i, cfg := range cfgs {
+		resolvers[i] = &LogSinkResolver{
+			cfg: cfg,
+		}
+	}
+	return resolvers, nil
+}
+
+func (r *MutationResolver) SetWorkspaceLogSinks(ctx context.Context, args struct {
+	Workspace string
+	Sinks     []LogSinkInput
+}) (*WorkspaceResolver, error) {
+	workspace, err := r.workspaceByRef(ctx, &args.Workspace)
+	if err := validateResolve("workspace", args.Workspace, workspace, err); err != nil {
+		return nil, err
+	}
+
+	cfgs := make([]config.LogSinkConfig, len(args.Sinks))
+	for i, input := range args.Sinks {
+		cfg := &cfgs[i]
+		cfg.Type = input.Type
+		cfg.Endpoint = stringOrEmpty(input.Endpoint)
+		if input.Headers != nil {
+			cfg.Headers = make(map[string]string, len(*input.Headers))
+			for k, v := range *input.Headers {
+				s, ok := v.(string)
+				if !ok {
+					return nil, errutil.HTTPErrorf(http.StatusBadRequest, "sink %d: header %q must be a string", i+1, k)
+				}
+				cfg.Headers[k] = s
+			}
+		}
+		if path := stringOrEmpty(input.Path); path != "" && !filepath.IsAbs(path) {
+			cfg.Path = filepath.Join(workspace.Root, path)
+		} else {
+			cfg.Path = path
+		}
+		if input.MaxBytes != nil {
+			cfg.MaxBytes = int64(*input.MaxBytes)
+		}
+		if input.MaxFiles != nil {
+			cfg.MaxFiles = int(*input.MaxFiles)
+		}
+	}
+	// The daemon configures the sinks when it next exports events.
+	sinks, err := logsink.NewAll(cfgs)
+	if err != nil {
+		return nil, errutil.HTTPErrorf(http.StatusBadRequest, "%v", err)
+	}
+	for _, sink := range sinks {
+		_ = sink.Close()
+	}
+
+	var raw RawJSON
+	if len(cfgs) > 0 {
+		raw = jsonutil.MustMarshal(cfgs)
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE workspace
+		SET log_sinks = ?
+		WHERE id = ?
+	`, raw, workspace.ID); err != nil {
+		return nil, fmt.Errorf("updating workspace: %w", err)
+	}
+	workspace.RawLogSinks = raw
+	return workspace, nil
+}
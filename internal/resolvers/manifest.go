@@ -3,9 +3,17 @@ package resolvers
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"path/filepath"
 	"strings"
 
+	"github.com/deref/exo/internal/manifest"
+	"github.com/deref/exo/internal/manifest/exohcl"
+	. "github.com/deref/exo/internal/scalars"
 	"github.com/deref/exo/internal/util/cueutil"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/internal/util/yamlutil"
+	"github.com/jmoiron/sqlx"
 	"github.com/natefinch/atomic"
 )
 
@@ -144,3 +152,275 @@ func (r *MutationResolver) FormatManifest(ctx context.Context, args struct {
 	}
 	return nil, nil
 }
+
+type manifestArgs struct {
+	Stack     string
+	Manifest  string
+	Format    *string
+	Path      *string
+	Profiles  *[]string
+	Variables *JSONObject
+}
+
+// Resolves the stack that a manifest is to be applied to, and loads the
+// definitions of the manifest's components. See loadManifestComponents.
+func (r *QueryResolver) loadStackManifest(ctx context.Context, args manifestArgs) (*StackResolver, []ComponentDefinition, map[string]bool, error) {
+	stack, err := r.stackByRef(ctx, &args.Stack)
+	if err := validateResolve("stack", args.Stack, stack, err); err != nil {
+		return nil, nil, nil, err
+	}
+
+	format := "exohcl"
+	if args.Format != nil {
+		format = *args.Format
+	}
+	// The manifest is read by the client, so the path must not be resolved
+	// against the working directory of this process.
+	filename := ""
+	if args.Path != nil {
+		if !filepath.IsAbs(*args.Path) {
+			return nil, nil, nil, errutil.HTTPErrorf(http.StatusBadRequest, "manifest path must be absolute: %q", *args.Path)
+		}
+		filename = *args.Path
+	}
+	var profiles []string
+	if args.Profiles != nil {
+		profiles = *args.Profiles
+	} else if stack.WorkspaceID != nil {
+		workspace, err := stack.Workspace(ctx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("resolving workspace: %w", err)
+		}
+		if workspace != nil {
+			profiles, err = workspace.Profiles()
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("resolving workspace profiles: %w", err)
+			}
+		}
+	}
+	var variables map[string]string
+	if args.Variables != nil {
+		variables = make(map[string]string, len(*args.Variables))
+		for name, value := range *args.Variables {
+			s, ok := value.(string)
+			if !ok {
+				return nil, nil, nil, errutil.HTTPErrorf(http.StatusBadRequest, "variable %q must be a string, got %T", name, value)
+			}
+			variables[name] = s
+		}
+	}
+	defs, disabled, err := loadManifestComponents(ctx, exohcl.MangleName(stack.Name), format, filename, args.Manifest, profiles, variables)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return stack, defs, disabled, nil
+}
+
+func (r *MutationResolver) ApplyManifest(ctx context.Context, args manifestArgs) (*ReconciliationResolver, error) {
+	stack, defs, disabled, err := r.loadStackManifest(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	oldComponents, err := stack.components(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolving components: %w", err)
+	}
+	changes, err := diffManifest(oldComponents, defs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Components outside the active profiles are created, but shutdown rather
+	// than reconciled, so that they stop if they were previously running.
+	// Shutdown retains their resources, such as the data of volumes. Disposed
+	// components are always reconciled.
+	reconciled := []string{}
+	var shutdown []string
+	idByName := make(map[string]string, len(oldComponents)+len(defs))
+	for _, oldComponent := range oldComponents {
+		idByName[oldComponent.Name] = oldComponent.ID
+	}
+	if err := transact(ctx, r.db, func(tx *sqlx.Tx) error {
+		for _, change := range changes {
+			switch change.Action {
+			case "create":
+				component, err := r.createComponent(ctx, tx, stack.ID /* parentID: */, nil, *change.New)
+				if err != nil {
+					return fmt.Errorf("creating %q: %w", change.Name, err)
+				}
+				idByName[change.Name] = component.ID
+			case "update":
+				if _, err := r.updateComponent(ctx, tx, change.Old.ID, change.Name, change.New.Spec); err != nil {
+					return fmt.Errorf("updating %q: %w", change.Name, err)
+				}
+			case "dispose":
+				if _, err := r.disposeComponent(ctx, tx, change.Old.ID); err != nil {
+					return fmt.Errorf("disposing %q: %w", change.Name, err)
+				}
+				reconciled = append(reconciled, change.Old.ID)
+			default:
+				panic(fmt.Errorf("unexpected manifest change action: %q", change.Action))
+			}
+		}
+		// Profiles are not part of the spec, so they are recorded for unchanged
+		// components too.
+		for _, def := range defs {
+			if err := setComponentProfiles(ctx, tx, idByName[def.Name], def.Profiles); err != nil {
+				return fmt.Errorf("setting profiles of %q: %w", def.Name, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		if disabled[def.Name] {
+			shutdown = append(shutdown, idByName[def.Name])
+		} else {
+			reconciled = append(reconciled, idByName[def.Name])
+		}
+	}
+
+	if len(disabled) == 0 {
+		// Reconcile the entire stack.
+		reconciled = nil
+	}
+	reconciliation, err := r.startStackReconciliation(ctx, stack, reconciled, shutdown)
+	if err != nil {
+		return nil, fmt.Errorf("starting stack reconciliation: %w", err)
+	}
+	return reconciliation, nil
+}
+
+// A change to a stack's components that is needed to apply a manifest.
+type manifestChange struct {
+	// One of "create", "update", or "dispose".
+	Action string
+	Name   string
+	// Nil when creating.
+	Old *ComponentResolver
+	// Nil when disposing.
+	New *ComponentDefinition
+}
+
+// Compares a stack's components to the component definitions of a manifest.
+// Components are matched by name. A component whose type changed is disposed
+// and created anew, and those disposals come first, since live components
+// must have unique names. Otherwise, creations and updates come before the
+// disposal of components that are absent from the manifest.
+func diffManifest(oldComponents []*ComponentResolver, defs []ComponentDefinition) ([]manifestChange, error) {
+	oldByName := make(map[string]*ComponentResolver, len(oldComponents))
+	for _, oldComponent := range oldComponents {
+		oldByName[oldComponent.Name] = oldComponent
+	}
+	newByName := make(map[string]*ComponentDefinition, len(defs))
+	for i := range defs {
+		def := &defs[i]
+		if _, exists := newByName[def.Name]; exists {
+			return nil, errutil.HTTPErrorf(http.StatusBadRequest, "component name conflict: %q", def.Name)
+		}
+		newByName[def.Name] = def
+	}
+
+	var replacements, changes, removals []manifestChange
+	for _, oldComponent := range oldComponents {
+		def := newByName[oldComponent.Name]
+		switch {
+		case def == nil:
+			removals = append(removals, manifestChange{
+				Action: "dispose",
+				Name:   oldComponent.Name,
+				Old:    oldComponent,
+			})
+		case def.Type != oldComponent.Type:
+			replacements = append(replacements, manifestChange{
+				Action: "dispose",
+				Name:   oldComponent.Name,
+				Old:    oldComponent,
+			})
+		}
+	}
+	for _, def := range defs {
+		def := newByName[def.Name]
+		oldComponent := oldByName[def.Name]
+		switch {
+		case oldComponent == nil, oldComponent.Type != def.Type:
+			changes = append(changes, manifestChange{
+				Action: "create",
+				Name:   def.Name,
+				New:    def,
+			})
+		case oldComponent.Spec.String() != def.Spec.String():
+			changes = append(changes, manifestChange{
+				Action: "update",
+				Name:   def.Name,
+				Old:    oldComponent,
+				New:    def,
+			})
+		}
+	}
+	return append(append(replacements, changes...), removals...), nil
+}
+
+// Maps resolver manifest formats to the formats understood by the manifest
+// loader. The loader predates Cue manifests and calls HCL manifests "exo".
+var manifestLoaderFormats = map[string]string{
+	"exo":      "cue",
+	"cue":      "cue",
+	"exohcl":   "exo",
+	"compose":  "compose",
+	"procfile": "procfile",
+	"k8s":      "k8s",
+}
+
+// Returns the definitions of the manifest's components, along with the set of
+// names of components that are disabled by the active profiles. Variables
+// override the defaults of the manifest's variables. The filename may be empty
+// if the manifest is not from a file.
+func loadManifestComponents(ctx context.Context, stackName string, format string, filename string, content string, profiles []string, variables map[string]string) ([]ComponentDefinition, map[string]bool, error) {
+	loaderFormat, ok := manifestLoaderFormats[format]
+	if !ok {
+		return nil, nil, errutil.HTTPErrorf(http.StatusBadRequest, "cannot apply manifest of format %q", format)
+	}
+	analysisContext := &exohcl.AnalysisContext{
+		Context:   ctx,
+		Variables: variables,
+	}
+	loader := &manifest.Loader{
+		WorkspaceName: stackName,
+		Format:        loaderFormat,
+		Filename:      filename,
+		Bytes:         []byte(content),
+	}
+	m, err := loader.Load(analysisContext)
+	if err != nil {
+		return nil, nil, errutil.WithHTTPStatus(http.StatusBadRequest, err)
+	}
+	componentSet := exohcl.NewComponentSet(m)
+	componentSet.Analyze(analysisContext)
+	if analysisContext.Diagnostics.HasErrors() {
+		return nil, nil, errutil.WithHTTPStatus(http.StatusBadRequest, analysisContext.Diagnostics)
+	}
+
+	defs := make([]ComponentDefinition, len(componentSet.Components))
+	disabled := make(map[string]bool)
+	for i, component := range componentSet.Components {
+		// Component specs are either JSON or YAML, which is a superset of JSON.
+		var spec any
+		if err := yamlutil.UnmarshalString(component.Spec, &spec); err != nil {
+			return nil, nil, errutil.HTTPErrorf(http.StatusBadRequest, "parsing spec of %q: %v", component.Name, err)
+		}
+		defs[i] = ComponentDefinition{
+			Type:        component.Type,
+			Name:        component.Name,
+			Spec:        EncodeCueValue(spec),
+			Environment: make(JSONObject),
+			Profiles:    component.Profiles,
+		}
+		if !component.Enabled(profiles) {
+			disabled[component.Name] = true
+		}
+	}
+	return defs, disabled, nil
+}
@@ -0,0 +1,392 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"github.com/deref/exo/internal/api"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/jsonutil"
+	"github.com/deref/exo/internal/util/logging"
+	dockerclient "github.com/docker/docker/client"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifestComponents(t *testing.T) {
+	defs, disabled, err := loadManifestComponents(context.Background(), "test", "exohcl", "", `
+exo = "0.1"
+components {
+  process "web" {
+    program = "node"
+    arguments = ["server.js"]
+  }
+  volume "data" {}
+}
+`, nil, nil)
+	if !assert.NoError(t, err) || !assert.Len(t, defs, 2) {
+		return
+	}
+	assert.Equal(t, "process", defs[0].Type)
+	assert.Equal(t, "web", defs[0].Name)
+	spec, err := cue.Value(defs[0].Spec).MarshalJSON()
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"program": "node", "arguments": ["server.js"]}`, string(spec))
+	}
+	assert.Equal(t, "volume", defs[1].Type)
+	assert.Equal(t, "data", defs[1].Name)
+	assert.Empty(t, disabled)
+}
+
+func TestLoadManifestComponentsProfiles(t *testing.T) {
+	manifest := `
+exo = "0.1"
+components {
+  process "web" {
+    program = "node"
+  }
+  process "debugger" {
+    program = "node"
+    arguments = ["--inspect"]
+    _ {
+      profiles = ["debug"]
+    }
+  }
+}
+`
+	defs, disabled, err := loadManifestComponents(context.Background(), "test", "exohcl", "", manifest, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, defs, 2) {
+		return
+	}
+	assert.Empty(t, defs[0].Profiles)
+	assert.Equal(t, []string{"debug"}, defs[1].Profiles)
+	assert.Equal(t, map[string]bool{"debugger": true}, disabled)
+
+	_, disabled, err = loadManifestComponents(context.Background(), "test", "exohcl", "", manifest, []string{"debug"}, nil)
+	if assert.NoError(t, err) {
+		assert.Empty(t, disabled)
+	}
+}
+
+func TestLoadManifestComponentsUnsupportedFormat(t *testing.T) {
+	_, _, err := loadManifestComponents(context.Background(), "test", "bogus", "", ``, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestLoadManifestComponentsVariables(t *testing.T) {
+	manifest := `
+exo = "0.1"
+variable "program" {
+  default = "node"
+}
+components {
+  process "web" {
+    program = var.program
+  }
+}
+`
+	defs, _, err := loadManifestComponents(context.Background(), "test", "exohcl", "", manifest, nil, map[string]string{
+		"program": "deno",
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, defs, 1) {
+		return
+	}
+	spec, err := cue.Value(defs[0].Spec).MarshalJSON()
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"program": "deno"}`, string(spec))
+	}
+}
+
+func TestDiffManifest(t *testing.T) {
+	oldComponent := func(id, typ, name string, spec any) *ComponentResolver {
+		return &ComponentResolver{
+			ComponentRow: ComponentRow{
+				ID:   id,
+				Type: typ,
+				Name: name,
+				Spec: EncodeCueValue(spec),
+			},
+		}
+	}
+	def := func(typ, name string, spec any) ComponentDefinition {
+		return ComponentDefinition{
+			Type: typ,
+			Name: name,
+			Spec: EncodeCueValue(spec),
+		}
+	}
+	oldComponents := []*ComponentResolver{
+		oldComponent("1", "process", "unchanged", map[string]any{"program": "a"}),
+		oldComponent("2", "process", "updated", map[string]any{"program": "a"}),
+		oldComponent("3", "process", "retyped", map[string]any{"program": "a"}),
+		oldComponent("4", "process", "removed", map[string]any{"program": "a"}),
+	}
+	defs := []ComponentDefinition{
+		def("process", "unchanged", map[string]any{"program": "a"}),
+		def("process", "updated", map[string]any{"program": "b"}),
+		def("container", "retyped", map[string]any{"image": "a"}),
+		def("process", "created", map[string]any{"program": "a"}),
+	}
+	changes, err := diffManifest(oldComponents, defs)
+	if !assert.NoError(t, err) {
+		return
+	}
+	type summary struct {
+		Action string
+		Name   string
+		OldID  string
+	}
+	actual := make([]summary, len(changes))
+	for i, change := range changes {
+		actual[i] = summary{Action: change.Action, Name: change.Name}
+		if change.Old != nil {
+			actual[i].OldID = change.Old.ID
+		}
+		if change.Action == "dispose" {
+			assert.Nil(t, change.New)
+		} else {
+			assert.NotNil(t, change.New)
+		}
+	}
+	assert.Equal(t, []summary{
+		{"dispose", "retyped", "3"},
+		{"update", "updated", "2"},
+		{"create", "retyped", ""},
+		{"create", "created", ""},
+		{"dispose", "removed", "4"},
+	}, actual)
+}
+
+func TestDiffManifestUnchanged(t *testing.T) {
+	spec := EncodeCueValue(map[string]any{"program": "a"})
+	changes, err := diffManifest([]*ComponentResolver{
+		{ComponentRow: ComponentRow{ID: "1", Type: "process", Name: "web", Spec: spec}},
+	}, []ComponentDefinition{
+		{Type: "process", Name: "web", Spec: spec},
+	})
+	if assert.NoError(t, err) {
+		assert.Empty(t, changes)
+	}
+}
+
+func TestDiffManifestNameConflict(t *testing.T) {
+	spec := EncodeCueValue(map[string]any{})
+	_, err := diffManifest(nil, []ComponentDefinition{
+		{Type: "process", Name: "web", Spec: spec},
+		{Type: "container", Name: "web", Spec: spec},
+	})
+	assert.Error(t, err)
+}
+
+func TestLoadManifestComponentsFilename(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "program.txt"), []byte("node"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `
+exo = "0.1"
+components {
+  process "web" {
+    program = file("program.txt")
+  }
+}
+`
+	defs, _, err := loadManifestComponents(context.Background(), "test", "exohcl", filepath.Join(dir, "exo.hcl"), manifest, nil, nil)
+	if !assert.NoError(t, err) || !assert.Len(t, defs, 1) {
+		return
+	}
+	spec, err := cue.Value(defs[0].Spec).MarshalJSON()
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"program": "node"}`, string(spec))
+	}
+}
+
+// Executes operations against the schema in process, as the peer does.
+type schemaService struct {
+	schema *graphql.Schema
+}
+
+func (svc *schemaService) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (svc *schemaService) Do(ctx context.Context, out any, doc string, vars map[string]any) error {
+	if vars != nil {
+		vars = jsonutil.MustSimplify(vars).(map[string]any)
+	}
+	resp := svc.schema.Exec(ctx, doc, "", vars)
+	if len(resp.Errors) > 0 {
+		return api.QueryErrorSet(resp.Errors)
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+func (svc *schemaService) Subscribe(ctx context.Context, newRes func() any, doc string, vars map[string]any) api.Subscription {
+	panic("not implemented")
+}
+
+// Serves the subset of the Docker Engine API that volumes use, recording the
+// names of the volumes that exist and how many have been removed.
+type fakeVolumeDaemon struct {
+	mu       sync.Mutex
+	volumes  map[string]bool
+	removals int
+}
+
+func (d *fakeVolumeDaemon) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, resource, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	switch {
+	case req.Method == http.MethodPost && resource == "volumes/create":
+		var body struct {
+			Name string
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.volumes[body.Name] = true
+		_ = json.NewEncoder(w).Encode(map[string]any{"Name": body.Name})
+	case strings.HasPrefix(resource, "volumes/"):
+		name := strings.TrimPrefix(resource, "volumes/")
+		if !d.volumes[name] {
+			http.Error(w, `{"message":"no such volume"}`, http.StatusNotFound)
+			return
+		}
+		if req.Method == http.MethodDelete {
+			delete(d.volumes, name)
+			d.removals++
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"Name": name})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (d *fakeVolumeDaemon) exists(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.volumes[name]
+}
+
+func (d *fakeVolumeDaemon) removalCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.removals
+}
+
+// Returns a resolver whose Docker controllers use a fake daemon, and a new
+// stack to apply manifests to.
+func newVolumeTestStack(t *testing.T, ctx context.Context) (*RootResolver, *StackResolver, *fakeVolumeDaemon) {
+	// Stack environments are read from a login shell, which should be quick.
+	t.Setenv("SHELL", "/bin/sh")
+
+	daemon := &fakeVolumeDaemon{volumes: make(map[string]bool)}
+	server := httptest.NewServer(daemon)
+	t.Cleanup(server.Close)
+	docker, err := dockerclient.NewClientWithOpts(
+		dockerclient.WithHost("tcp://"+server.Listener.Addr().String()),
+		dockerclient.WithVersion("1.41"),
+	)
+	require.NoError(t, err)
+
+	root := &RootResolver{
+		SystemLog: logging.Default(),
+		VarDir:    t.TempDir(),
+		Docker:    docker,
+	}
+	root.Service = &schemaService{schema: NewSchema(root)}
+	require.NoError(t, root.Init(ctx))
+	t.Cleanup(func() {
+		_ = root.Shutdown(ctx)
+	})
+
+	stack, err := root.CreateStack(ctx, struct {
+		Workspace   *string
+		Name        *string
+		Cluster     *string
+		Environment *JSONObject
+	}{})
+	require.NoError(t, err)
+	return root, stack, daemon
+}
+
+const volumeTestManifest = `
+exo = "0.1"
+components {
+  volume "data" {
+    name = "test_data"
+    _ {
+      profiles = ["db"]
+    }
+  }
+}
+`
+
+// Works jobs until every task has finished, then fails if any task failed.
+// Reconciliation starts further jobs, such as those that initialize resources.
+func runJobs(t *testing.T, ctx context.Context, root *RootResolver) {
+	for {
+		tasks, err := root.AllTasks(ctx)
+		require.NoError(t, err)
+		jobID := ""
+		for _, task := range tasks {
+			if task.Finished == nil {
+				jobID = task.JobID
+				break
+			}
+		}
+		if jobID == "" {
+			break
+		}
+		workers := &api.WorkerPool{
+			Service:      root.Service,
+			Concurrency:  1,
+			WorkerPrefix: "test",
+			JobID:        jobID,
+		}
+		require.NoError(t, workers.Run(ctx))
+	}
+	tasks, err := root.AllTasks(ctx)
+	require.NoError(t, err)
+	for _, task := range tasks {
+		require.Nil(t, task.Error, "%s task failed: %s", task.Mutation, stringOrEmpty(task.Error))
+	}
+}
+
+func TestApplyManifestRetainsProfileDisabledVolume(t *testing.T) {
+	ctx := logging.ContextWithLogger(context.Background(), logging.Default())
+	root, stack, daemon := newVolumeTestStack(t, ctx)
+
+	apply := func(profiles ...string) {
+		_, err := root.ApplyManifest(ctx, manifestArgs{
+			Stack:    stack.ID,
+			Manifest: volumeTestManifest,
+			Profiles: &profiles,
+		})
+		require.NoError(t, err)
+		runJobs(t, ctx, root)
+	}
+
+	apply("db")
+	assert.True(t, daemon.exists("test_data"), "volume created")
+
+	apply()
+	assert.True(t, daemon.exists("test_data"), "volume retained")
+	assert.Equal(t, 0, daemon.removalCount())
+}
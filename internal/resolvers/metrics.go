@@ -0,0 +1,251 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deref/exo/internal/metrics"
+	. "github.com/deref/exo/internal/scalars"
+)
+
+const defaultMetricsMaxAge = 24 * time.Hour
+
+// How far back metrics are reported when not specified.
+const defaultMetricsWindow = time.Hour
+
+// Samples older than this are not considered to reflect current usage.
+const latestMetricsMaxAge = 30 * time.Second
+
+type MetricsSampleRow struct {
+	ComponentID         string   `db:"component_id"`
+	Timestamp           int64    `db:"timestamp"`
+	CPUPercent          float64  `db:"cpu_percent"`
+	ResidentBytes       uint64   `db:"resident_bytes"`
+	NetworkReceiveRate  *float64 `db:"network_receive_rate"`
+	NetworkTransmitRate *float64 `db:"network_transmit_rate"`
+	DiskReadRate        *float64 `db:"disk_read_rate"`
+	DiskWriteRate       *float64 `db:"disk_write_rate"`
+}
+
+func (row *MetricsSampleRow) sample() metrics.Sample {
+	return metrics.Sample{
+		Timestamp:           time.Unix(0, row.Timestamp).UTC(),
+		CPUPercent:          row.CPUPercent,
+		ResidentBytes:       row.ResidentBytes,
+		NetworkReceiveRate:  row.NetworkReceiveRate,
+		NetworkTransmitRate: row.NetworkTransmitRate,
+		DiskReadRate:        row.DiskReadRate,
+		DiskWriteRate:       row.DiskWriteRate,
+	}
+}
+
+type MetricsSampleResolver struct {
+	Sample metrics.Sample
+}
+
+func (r *MetricsSampleResolver) Timestamp() Instant {
+	return GoTimeToInstant(r.Sample.Timestamp)
+}
+
+func (r *MetricsSampleResolver) CPUPercent() float64 {
+	return r.Sample.CPUPercent
+}
+
+func (r *MetricsSampleResolver) ResidentBytes() float64 {
+	return float64(r.Sample.ResidentBytes)
+}
+
+func (r *MetricsSampleResolver) NetworkReceiveRate() *float64 {
+	return r.Sample.NetworkReceiveRate
+}
+
+func (r *MetricsSampleResolver) NetworkTransmitRate() *float64 {
+	return r.Sample.NetworkTransmitRate
+}
+
+func (r *MetricsSampleResolver) DiskReadRate() *float64 {
+	return r.Sample.DiskReadRate
+}
+
+func (r *MetricsSampleResolver) DiskWriteRate() *float64 {
+	return r.Sample.DiskWriteRate
+}
+
+// Metrics reports the recorded resource usage of the process. Components that
+// run their processes as children, such as daemons, include the usage of
+// those children.
+func (r *ProcessComponentResolver) Metrics(ctx context.Context, args struct {
+	Since      *Instant
+	Resolution *int32
+}) ([]*MetricsSampleResolver, error) {
+	since := Now(ctx).GoTime().Add(-defaultMetricsWindow)
+	if args.Since != nil {
+		since = args.Since.GoTime()
+	}
+	var resolution time.Duration
+	if args.Resolution != nil {
+		if *args.Resolution < 0 {
+			return nil, fmt.Errorf("resolution must not be negative, got %d", *args.Resolution)
+		}
+		resolution = time.Duration(*args.Resolution) * time.Second
+	}
+
+	samples, err := r.samplesSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	samples = metrics.Downsample(samples, resolution)
+
+	resolvers := make([]*MetricsSampleResolver, len(samples))
+	for i, sample := range samples {
+		resolvers[i] = &MetricsSampleResolver{Sample: sample}
+	}
+	return resolvers, nil
+}
+
+// LatestMetrics is the most recent sample of the process's resource usage, or
+// null if the process has not been sampled recently.
+func (r *ProcessComponentResolver) LatestMetrics(ctx context.Context) (*MetricsSampleResolver, error) {
+	samples, err := r.samplesSince(ctx, Now(ctx).GoTime().Add(-latestMetricsMaxAge))
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, nil
+	}
+	return &MetricsSampleResolver{Sample: samples[len(samples)-1]}, nil
+}
+
+// Samples of the process and its children, in chronological order.
+func (r *ProcessComponentResolver) samplesSince(ctx context.Context, since time.Time) ([]metrics.Sample, error) {
+	componentIDs, err := r.componentIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []MetricsSampleRow
+	query, queryArgs := mustSqlIn(`
+		SELECT *
+		FROM metrics_sample
+		WHERE component_id IN (?)
+		AND timestamp >= ?
+		ORDER BY timestamp ASC
+	`, componentIDs, since.UnixNano())
+	if err := r.Q.db.SelectContext(ctx, &rows, query, queryArgs...); err != nil {
+		return nil, err
+	}
+
+	samples := make([]metrics.Sample, len(rows))
+	for i, row := range rows {
+		samples[i] = row.sample()
+	}
+	return metrics.Combine(samples), nil
+}
+
+type metricsSampler struct {
+	Q      *RootResolver
+	MaxAge time.Duration
+
+	readings map[processTarget]metrics.Reading
+}
+
+func (r *RootResolver) startMetricsSampler(ctx context.Context) {
+	maxAge := r.MetricsMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultMetricsMaxAge
+	}
+	sampler := &metricsSampler{
+		Q:        r,
+		MaxAge:   maxAge,
+		readings: make(map[processTarget]metrics.Reading),
+	}
+	ctx, r.stopMetrics = context.WithCancel(ctx)
+	r.metricsDone = make(chan struct{})
+	go func() {
+		defer close(r.metricsDone)
+		ticker := time.NewTicker(r.MetricsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := sampler.sample(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					r.SystemLog.Infof("sampling metrics: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (r *RootResolver) stopMetricsSampler() {
+	if r.stopMetrics == nil {
+		return
+	}
+	r.stopMetrics()
+	<-r.metricsDone
+}
+
+// Records a sample of each running process or container, then discards
+// expired samples.
+func (s *metricsSampler) sample(ctx context.Context) error {
+	targets, err := s.Q.processTargets(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var procs *metrics.ProcessTable
+	for _, target := range targets {
+		if target.Pid != 0 {
+			procs, err = metrics.SnapshotProcesses(ctx)
+			if err != nil {
+				return fmt.Errorf("listing processes: %w", err)
+			}
+			break
+		}
+	}
+
+	now := Now(ctx).GoTime()
+	readings := make(map[processTarget]metrics.Reading, len(targets))
+	for _, target := range targets {
+		var usage metrics.Usage
+		if target.Pid != 0 {
+			usage, err = procs.ReadUsage(ctx, target.Pid)
+		} else {
+			usage, err = metrics.ReadContainerUsage(ctx, s.Q.Docker, target.ContainerID)
+		}
+		if err != nil {
+			// Not running.
+			continue
+		}
+		reading := metrics.Reading{Time: now, Usage: usage}
+		readings[target] = reading
+		// Rates are not known until the second reading.
+		prev, ok := s.readings[target]
+		if !ok {
+			continue
+		}
+		sample := metrics.Between(prev, reading)
+		if _, err := s.Q.db.ExecContext(ctx, `
+			INSERT INTO metrics_sample (
+				component_id, timestamp, cpu_percent, resident_bytes,
+				network_receive_rate, network_transmit_rate, disk_read_rate, disk_write_rate
+			)
+			VALUES ( ?, ?, ?, ?, ?, ?, ?, ? )
+		`, target.ComponentID, now.UnixNano(), sample.CPUPercent, sample.ResidentBytes,
+			sample.NetworkReceiveRate, sample.NetworkTransmitRate, sample.DiskReadRate, sample.DiskWriteRate,
+		); err != nil {
+			return fmt.Errorf("inserting sample: %w", err)
+		}
+	}
+	s.readings = readings
+
+	if _, err := s.Q.db.ExecContext(ctx, `
+		DELETE FROM metrics_sample
+		WHERE timestamp < ?
+	`, now.Add(-s.MaxAge).UnixNano()); err != nil {
+		return fmt.Errorf("removing expired samples: %w", err)
+	}
+	return nil
+}
@@ -62,11 +62,20 @@ func (r *MutationResolver) Migrate(ctx context.Context) error {
 		CREATE TABLE IF NOT EXISTS workspace (
 			id TEXT NOT NULL PRIMARY KEY,
 			root TEXT NOT NULL,
-			project_id TEXT NOT NULL
+			project_id TEXT NOT NULL,
+			log_sinks TEXT,
+			profiles TEXT
 	);`); err != nil {
 		return fmt.Errorf("creating workspace table: %w", err)
 	}
 
+	if err := r.addColumn(ctx, "workspace", "log_sinks", "TEXT"); err != nil {
+		return err
+	}
+	if err := r.addColumn(ctx, "workspace", "profiles", "TEXT"); err != nil {
+		return err
+	}
+
 	if _, err := r.db.ExecContext(ctx, `
 		CREATE UNIQUE INDEX IF NOT EXISTS
 		workspace_root ON workspace ( root )
@@ -109,11 +118,16 @@ func (r *MutationResolver) Migrate(ctx context.Context) error {
 			spec TEXT NOT NULL,
 			model TEXT NOT NULL,
 			environment_variables TEXT,
-			disposed TEXT
+			disposed TEXT,
+			profiles TEXT
 	);`); err != nil {
 		return fmt.Errorf("creating component table: %w", err)
 	}
 
+	if err := r.addColumn(ctx, "component", "profiles", "TEXT"); err != nil {
+		return err
+	}
+
 	// TODO: Validate that these indexes are getting hit.
 
 	// Nulls are distinct from themselves, so the stack serves as the parent id
@@ -273,5 +287,57 @@ func (r *MutationResolver) Migrate(ctx context.Context) error {
 		return fmt.Errorf("creating vault table: %w", err)
 	}
 
+	// Metrics.
+
+	// Timestamps are stored as Unix nanoseconds, so that they sort numerically.
+	if _, err := r.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS metrics_sample (
+			component_id TEXT NOT NULL,
+			timestamp INTEGER NOT NULL,
+			cpu_percent REAL NOT NULL,
+			resident_bytes INTEGER NOT NULL,
+			network_receive_rate REAL,
+			network_transmit_rate REAL,
+			disk_read_rate REAL,
+			disk_write_rate REAL
+	);`); err != nil {
+		return fmt.Errorf("creating metrics_sample table: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS
+		metrics_sample_component ON metrics_sample ( component_id, timestamp )
+	`); err != nil {
+		return fmt.Errorf("creating metrics_sample_component index: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS
+		metrics_sample_timestamp ON metrics_sample ( timestamp )
+	`); err != nil {
+		return fmt.Errorf("creating metrics_sample_timestamp index: %w", err)
+	}
+
+	return nil
+}
+
+// Adds a column to tables that were created before the column was introduced.
+func (r *MutationResolver) addColumn(ctx context.Context, table, column, typ string) error {
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, `
+		SELECT COUNT(*) > 0
+		FROM pragma_table_info(?)
+		WHERE name = ?
+	`, table, column); err != nil {
+		return fmt.Errorf("checking for %s.%s column: %w", table, column, err)
+	}
+	if exists {
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx, fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN %s %s", table, column, typ,
+	)); err != nil {
+		return fmt.Errorf("adding %s.%s column: %w", table, column, err)
+	}
 	return nil
 }
@@ -1,6 +1,11 @@
 package resolvers
 
-import "context"
+import (
+	"context"
+
+	compose "github.com/deref/exo/internal/providers/docker/components"
+	docker "github.com/deref/exo/internal/providers/docker/resources"
+)
 
 type NetworkResolver struct {
 	Q    *RootResolver
@@ -16,8 +21,8 @@ type NetworkComponentResolver struct {
 
 func (r *QueryResolver) isNetworkType(typ string) bool {
 	// TODO: Extensible.
-	switch typ {
-	case "network":
+	switch r.Controllers.QualifyType(typ) {
+	case compose.NetworkType, docker.NetworkType:
 		return true
 	default:
 		return false
@@ -2,8 +2,20 @@ package resolvers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
 
+	"github.com/deref/exo/internal/metrics"
+	compose "github.com/deref/exo/internal/providers/docker/components"
+	docker "github.com/deref/exo/internal/providers/docker/resources"
 	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/supervise"
+	"github.com/deref/exo/internal/util/osutil"
+	dockerclient "github.com/docker/docker/client"
+	psnet "github.com/shirou/gopsutil/v3/net"
 )
 
 type ProcessResolver struct {
@@ -20,8 +32,8 @@ type ProcessComponentResolver struct {
 
 func (r *QueryResolver) isProcessType(typ string) bool {
 	// TODO: Extensible.
-	switch typ {
-	case "daemon", "process", "container":
+	switch r.Controllers.QualifyType(typ) {
+	case "deref.io/os/daemon", "deref.io/os/process", compose.ContainerType, docker.ContainerType:
 		return true
 	default:
 		return false
@@ -52,7 +64,11 @@ func (r *QueryResolver) processesByStack(ctx context.Context, stackID string) ([
 	processes := make([]*ProcessComponentResolver, 0, len(components))
 	for _, component := range components {
 		// TODO: Some callers may also want to see recently terminated processes.
-		if !component.Running() {
+		running, err := component.Running(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("checking if %s is running: %w", component.Name, err)
+		}
+		if !running {
 			continue
 		}
 		process := r.processFromComponent(component)
@@ -68,6 +84,21 @@ func (r *ProcessComponentResolver) Component(ctx context.Context) (*ComponentRes
 	return r.Q.componentByID(ctx, &r.ComponentID)
 }
 
+// Restarts reports how many times the process has been restarted by its
+// supervisor, as recorded in the supervisor's status file. Null for processes
+// without a supervisor.
+func (r *ProcessComponentResolver) Restarts() (*int32, error) {
+	status, err := supervise.ReadStatus(supervise.StatusPath(r.Q.VarDir, r.ComponentID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading supervisor status: %w", err)
+	}
+	restarts := int32(status.Restarts)
+	return &restarts, nil
+}
+
 func (r *ProcessResolver) Started() *Instant {
 	return nil // TODO!
 }
@@ -87,3 +118,181 @@ func (r *ProcessResolver) Ports() *[]int32 {
 func (r *ProcessResolver) Environment() *EnvironmentResolver {
 	return nil // TODO!
 }
+
+// A process or container that belongs to a component, via the component's
+// resources.
+type processTarget struct {
+	ComponentID string
+	Pid         int
+	ContainerID string
+}
+
+// Finds the processes and containers of the given components, or of all live
+// components if componentIDs is nil.
+func (r *QueryResolver) processTargets(ctx context.Context, componentIDs []string) ([]processTarget, error) {
+	query := `
+		SELECT resource.type, resource.component_id, resource.model
+		FROM resource
+		INNER JOIN component ON component.id = resource.component_id
+		WHERE component.disposed IS NULL
+	`
+	var args []any
+	if componentIDs != nil {
+		query, args = mustSqlIn(query+`AND component.id IN (?)`, componentIDs)
+	}
+	var rows []struct {
+		Type        string  `db:"type"`
+		ComponentID string  `db:"component_id"`
+		RawModel    RawJSON `db:"model"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("listing resources: %w", err)
+	}
+	var targets []processTarget
+	for _, row := range rows {
+		var model struct {
+			Pid *int   `json:"pid"`
+			ID  string `json:"id"`
+			Exo struct {
+				State struct {
+					ContainerID string `json:"containerId"`
+				} `json:"state"`
+			} `json:"x-exo"`
+		}
+		if err := json.Unmarshal(row.RawModel, &model); err != nil {
+			continue
+		}
+		target := processTarget{ComponentID: row.ComponentID}
+		switch r.Controllers.QualifyType(row.Type) {
+		case "deref.io/os/process":
+			if model.Pid == nil {
+				continue
+			}
+			target.Pid = *model.Pid
+		case docker.ContainerType:
+			if model.ID == "" {
+				continue
+			}
+			target.ContainerID = model.ID
+		case compose.ContainerType:
+			if model.Exo.State.ContainerID == "" {
+				continue
+			}
+			target.ContainerID = model.Exo.State.ContainerID
+		default:
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// Components such as daemons run their processes as children, so the
+// processes of the children are considered to be part of the component.
+func (r *ProcessComponentResolver) componentIDs(ctx context.Context) ([]string, error) {
+	ids := []string{r.ComponentID}
+	children, err := r.Q.componentsByParent(ctx, r.ComponentID)
+	if err != nil {
+		return nil, fmt.Errorf("resolving children: %w", err)
+	}
+	for _, child := range children {
+		ids = append(ids, child.ID)
+	}
+	return ids, nil
+}
+
+func (r *ProcessComponentResolver) targets(ctx context.Context) ([]processTarget, error) {
+	componentIDs, err := r.componentIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Q.processTargets(ctx, componentIDs)
+}
+
+// Running reports whether any of the component's processes are running.
+func (r *ProcessComponentResolver) Running(ctx context.Context) (bool, error) {
+	targets, err := r.targets(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, target := range targets {
+		if target.Pid != 0 {
+			if osutil.IsValidPid(target.Pid) {
+				return true, nil
+			}
+			continue
+		}
+		inspection, err := r.Q.Docker.ContainerInspect(ctx, target.ContainerID)
+		if dockerclient.IsErrNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("inspecting container: %w", err)
+		}
+		if inspection.State != nil && inspection.State.Running {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Ports lists the TCP ports that the component's processes, or their
+// descendants, are listening on. For containers, these are the exposed ports
+// of the container.
+func (r *ProcessComponentResolver) Ports(ctx context.Context) (*[]int32, error) {
+	targets, err := r.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var procs *metrics.ProcessTable
+	seen := make(map[int32]bool)
+	ports := []int32{}
+	add := func(port int32) {
+		if port > 0 && !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+	for _, target := range targets {
+		if target.ContainerID != "" {
+			inspection, err := r.Q.Docker.ContainerInspect(ctx, target.ContainerID)
+			if dockerclient.IsErrNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("inspecting container: %w", err)
+			}
+			if inspection.NetworkSettings != nil {
+				for port := range inspection.NetworkSettings.Ports {
+					add(int32(port.Int()))
+				}
+			}
+			continue
+		}
+		if !osutil.IsValidPid(target.Pid) {
+			continue
+		}
+		if procs == nil {
+			procs, err = metrics.SnapshotProcesses(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("listing processes: %w", err)
+			}
+		}
+		pids := append([]int32{int32(target.Pid)}, procs.Descendants(int32(target.Pid))...)
+		for _, pid := range pids {
+			conns, err := psnet.ConnectionsPidWithContext(ctx, "tcp", pid)
+			if err != nil {
+				continue
+			}
+			for _, conn := range conns {
+				if conn.Status == "LISTEN" {
+					add(int32(conn.Laddr.Port))
+				}
+			}
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i] < ports[j]
+	})
+	return &ports, nil
+}
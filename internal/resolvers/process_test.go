@@ -0,0 +1,33 @@
+package resolvers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deref/exo/internal/supervise"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessComponentRestarts(t *testing.T) {
+	root := &RootResolver{VarDir: t.TempDir()}
+	process := &ProcessComponentResolver{
+		ProcessResolver: ProcessResolver{Q: root},
+		ComponentID:     "c1",
+	}
+
+	// Unsupervised.
+	restarts, err := process.Restarts()
+	if assert.NoError(t, err) {
+		assert.Nil(t, restarts)
+	}
+
+	path := supervise.StatusPath(root.VarDir, "c1")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(`{"pid":123,"restarts":2}`), 0600))
+	restarts, err = process.Restarts()
+	if assert.NoError(t, err) && assert.NotNil(t, restarts) {
+		assert.Equal(t, int32(2), *restarts)
+	}
+}
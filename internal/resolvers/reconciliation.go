@@ -14,11 +14,20 @@ type ReconciliationResolver struct {
 	Job       *JobResolver
 }
 
-// Starts a reconciliation job (top-level async task) to reconcile all components in a stack.
-func (r *MutationResolver) startStackReconciliation(ctx context.Context, stack *StackResolver) (*ReconciliationResolver, error) {
-	job, err := r.createJob(ctx, "reconcileStack", map[string]any{
+// Starts a reconciliation job for a stack. If components is non-nil, only the
+// components with those IDs are reconciled. The components in shutdown are
+// shutdown instead.
+func (r *MutationResolver) startStackReconciliation(ctx context.Context, stack *StackResolver, components []string, shutdown []string) (*ReconciliationResolver, error) {
+	arguments := map[string]any{
 		"ref": stack.ID,
-	})
+	}
+	if components != nil {
+		arguments["components"] = components
+	}
+	if len(shutdown) > 0 {
+		arguments["shutdown"] = shutdown
+	}
+	job, err := r.createJob(ctx, "reconcileStack", arguments)
 	if err != nil {
 		return nil, fmt.Errorf("creating reconciliation job: %w", err)
 	}
@@ -70,7 +79,9 @@ func (r *MutationResolver) ReconcileStack_label(ctx context.Context, args struct
 }
 
 func (r *MutationResolver) ReconcileStack(ctx context.Context, args struct {
-	Ref string
+	Ref        string
+	Components *[]string
+	Shutdown   *[]string
 }) (*VoidResolver, error) {
 	stack, err := r.stackByRef(ctx, &args.Ref)
 	if err := validateResolve("stack", args.Ref, stack, err); err != nil {
@@ -82,15 +93,35 @@ func (r *MutationResolver) ReconcileStack(ctx context.Context, args struct {
 		All:     true,
 	}
 	components, err := componentSet.Items(ctx)
-	taskInputs := make([]TaskInput, len(components))
-	for i, component := range components {
-		taskInputs[i] = TaskInput{
-			Mutation: "reconcileComponent",
+	var selected map[string]bool
+	if args.Components != nil {
+		selected = make(map[string]bool, len(*args.Components))
+		for _, id := range *args.Components {
+			selected[id] = true
+		}
+	}
+	shutdown := make(map[string]bool)
+	if args.Shutdown != nil {
+		for _, id := range *args.Shutdown {
+			shutdown[id] = true
+		}
+	}
+	taskInputs := make([]TaskInput, 0, len(components))
+	for _, component := range components {
+		mutation := "reconcileComponent"
+		switch {
+		case shutdown[component.ID]:
+			mutation = "shutdownComponent"
+		case selected != nil && !selected[component.ID]:
+			continue
+		}
+		taskInputs = append(taskInputs, TaskInput{
+			Mutation: mutation,
 			Arguments: map[string]any{
 				"stack": stack.ID,
 				"ref":   component.ID,
 			},
-		}
+		})
 	}
 	_, err = r.createTasks(ctx, taskInputs)
 	return nil, err
@@ -118,6 +149,55 @@ func (r *MutationResolver) ReconcileComponent(ctx context.Context, args struct {
 	return nil, r.reconcileComponent(ctx, component)
 }
 
+func (r *MutationResolver) ShutdownComponent_label(ctx context.Context, args struct {
+	Stack *string
+	Ref   string
+}) (string, error) {
+	component, _ := r.componentByRef(ctx, args.Ref, args.Stack)
+	if component == nil {
+		return "shutting down unknown component", nil
+	}
+	return fmt.Sprintf("shutdown %s", component.Name), nil
+}
+
+func (r *MutationResolver) ShutdownComponent(ctx context.Context, args struct {
+	Stack *string
+	Ref   string
+}) (*VoidResolver, error) {
+	component, err := r.componentByRef(ctx, args.Ref, args.Stack)
+	if err := validateResolve("component", args.Ref, component, err); err != nil {
+		return nil, err
+	}
+	_, err = r.shutdownComponent(ctx, component)
+	return nil, err
+}
+
+func (r *MutationResolver) RestartComponent_label(ctx context.Context, args struct {
+	Stack *string
+	Ref   string
+}) (string, error) {
+	component, _ := r.componentByRef(ctx, args.Ref, args.Stack)
+	if component == nil {
+		return "restarting unknown component", nil
+	}
+	return fmt.Sprintf("restart %s", component.Name), nil
+}
+
+func (r *MutationResolver) RestartComponent(ctx context.Context, args struct {
+	Stack *string
+	Ref   string
+}) (*VoidResolver, error) {
+	component, err := r.componentByRef(ctx, args.Ref, args.Stack)
+	if err := validateResolve("component", args.Ref, component, err); err != nil {
+		return nil, err
+	}
+	component, err = r.shutdownComponent(ctx, component)
+	if err != nil {
+		return nil, fmt.Errorf("shutting down: %w", err)
+	}
+	return nil, r.reconcileComponent(ctx, component)
+}
+
 func (r *MutationResolver) reconcileComponent(ctx context.Context, component *ComponentResolver) error {
 	if component.Disposed == nil {
 		var err error
@@ -135,14 +215,32 @@ func (r *MutationResolver) reconcileComponent(ctx context.Context, component *Co
 			return fmt.Errorf("handling children updated: %w", err)
 		}
 	} else {
-		var err error
-		if component, err = r.shutdownComponent(ctx, component.ID); err != nil {
-			return fmt.Errorf("shutting down: %w", err)
+		return r.destroyComponent(ctx, component)
+	}
+
+	return nil
+}
+
+// Shuts down and deletes a disposed component. Disposal is recursive, so
+// children are destroyed first.
+func (r *MutationResolver) destroyComponent(ctx context.Context, component *ComponentResolver) error {
+	children, err := r.disposedComponentsByParent(ctx, component.ID)
+	if err != nil {
+		return fmt.Errorf("resolving children: %w", err)
+	}
+	for _, child := range children {
+		if err := r.destroyComponent(ctx, child); err != nil {
+			return fmt.Errorf("destroying %s: %w", child.Name, err)
 		}
-		// TODO: On success, delete the component.
-		return nil
 	}
 
+	component, err = r.shutdownComponent(ctx, component)
+	if err != nil {
+		return fmt.Errorf("shutting down: %w", err)
+	}
+	if err := r.deleteComponent(ctx, component); err != nil {
+		return fmt.Errorf("deleting: %w", err)
+	}
 	return nil
 }
 
@@ -202,19 +300,19 @@ func (r *MutationResolver) reconcileChildren(ctx context.Context, parent *Compon
 		switch {
 		case child.Old == nil:
 			def := *child.New
-			component, err = r.createComponent(ctx, parent.StackID, &parent.ID, def)
+			component, err = r.createComponent(ctx, r.db, parent.StackID, &parent.ID, def)
 			if err != nil {
 				return fmt.Errorf("creating %q: %w", def.Name, err)
 			}
 		case child.New == nil:
 			def := *child.Old
-			component, err = r.disposeComponent(ctx, child.ID)
+			component, err = r.disposeComponent(ctx, r.db, child.ID)
 			if err != nil {
 				return fmt.Errorf("disposing %q: %w", def.Name, err)
 			}
 		case child.New.Spec.String() != child.Old.Spec.String():
 			def := *child.New
-			component, err = r.updateComponent(ctx, child.ID, def.Name, def.Spec)
+			component, err = r.updateComponent(ctx, r.db, child.ID, def.Name, def.Spec)
 			if err != nil {
 				return fmt.Errorf("updating %q: %w", def.Name, err)
 			}
@@ -0,0 +1,34 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartComponentRetainsResources(t *testing.T) {
+	ctx := logging.ContextWithLogger(context.Background(), logging.Default())
+	root, stack, daemon := newVolumeTestStack(t, ctx)
+
+	_, err := root.ApplyManifest(ctx, manifestArgs{
+		Stack:    stack.ID,
+		Manifest: volumeTestManifest,
+		Profiles: &[]string{"db"},
+	})
+	require.NoError(t, err)
+	runJobs(t, ctx, root)
+	require.True(t, daemon.exists("test_data"))
+
+	// Resource operations must be performed by tasks.
+	_, err = root.createJob(ctx, "restartComponent", map[string]any{
+		"stack": stack.ID,
+		"ref":   "data",
+	})
+	require.NoError(t, err)
+	runJobs(t, ctx, root)
+	assert.True(t, daemon.exists("test_data"))
+	assert.Equal(t, 0, daemon.removalCount())
+}
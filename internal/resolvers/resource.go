@@ -2,6 +2,7 @@ package resolvers
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -97,7 +98,9 @@ func (r *QueryResolver) ResourceByID(ctx context.Context, args struct {
 }
 
 func (r *QueryResolver) resourceByID(ctx context.Context, id *string) (*ResourceResolver, error) {
-	s := &ResourceResolver{}
+	s := &ResourceResolver{
+		Q: r,
+	}
 	err := r.getRowByKey(ctx, &s.ResourceRow, `
 		SELECT *
 		FROM resource
@@ -241,7 +244,7 @@ func (r *ResourceResolver) Stack(ctx context.Context) (*StackResolver, error) {
 }
 
 func (r *ResourceResolver) Component(ctx context.Context) (*ComponentResolver, error) {
-	return r.Q.componentByResourceID(ctx, &r.ID)
+	return r.Q.componentByID(ctx, r.ComponentID)
 }
 
 func (r *ResourceResolver) Task(ctx context.Context) (*TaskResolver, error) {
@@ -284,10 +287,10 @@ func (r *MutationResolver) CreateResource(ctx context.Context, args struct {
 	row.ID = gensym.RandomBase32()
 	row.Type = args.Type
 
+	// When not adopting, the model is replaced by that of the controller once
+	// the resource has been initialized.
+	row.RawModel = jsonutil.MustMarshal(args.Model)
 	adopt := args.Adopt != nil && *args.Adopt
-	if adopt {
-		row.RawModel = jsonutil.MustMarshal(args.Model)
-	}
 
 	var project *ProjectResolver
 	if args.Project != nil {
@@ -369,6 +372,116 @@ func (r *MutationResolver) CreateResource(ctx context.Context, args struct {
 	return resource, nil
 }
 
+// Begins tracking a pre-existing external resource. The controller locates
+// the resource by IRI, or by a controller-specific shorthand such as a name,
+// ID, or pid. The located resource is then adopted by createResource, which
+// reads the full model asynchronously.
+func (r *MutationResolver) AdoptResource(ctx context.Context, args struct {
+	Type      string
+	IRI       string
+	Project   *string
+	Stack     *string
+	Component *string
+}) (*ResourceResolver, error) {
+	typ := r.Controllers.QualifyType(args.Type)
+	ctrl := r.resourceControllerByType(ctx, typ)
+	if ctrl == nil {
+		return nil, errutil.HTTPErrorf(http.StatusBadRequest, "no resource controller for type: %q", args.Type)
+	}
+	locator, ok := ctrl.(sdk.AResourceLocator)
+	if !ok {
+		return nil, sdk.NewNotImplementedErrorf("%s resources cannot be adopted", typ)
+	}
+
+	cfg := &sdk.ResourceConfig{
+		Type: typ,
+	}
+	model := RawJSON("{}")
+	if err := locator.LocateResource(ctx, cfg, args.IRI, &model); err != nil {
+		return nil, fmt.Errorf("locating: %w", err)
+	}
+	iri, err := ctrl.IdentifyResource(ctx, cfg, &model)
+	if err != nil {
+		return nil, fmt.Errorf("identifying: %w", err)
+	}
+	iri = strings.TrimSpace(iri)
+	if iri == "" {
+		return nil, errutil.HTTPErrorf(http.StatusUnprocessableEntity, "cannot identify resource: %q", args.IRI)
+	}
+	existing, err := r.resourcesByIRI(ctx, iri)
+	if err != nil {
+		return nil, fmt.Errorf("resolving existing resources: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, conflictErrorf("resource %q is already tracked as %s", iri, existing[0].ID)
+	}
+
+	var modelObj JSONObject
+	if err := json.Unmarshal(model, &modelObj); err != nil {
+		return nil, fmt.Errorf("unmarshalling model: %w", err)
+	}
+	adopt := true
+	return r.CreateResource(ctx, struct {
+		Type      string
+		Model     JSONObject
+		Project   *string
+		Stack     *string
+		Component *string
+		Adopt     *bool
+	}{
+		Type:      typ,
+		Model:     modelObj,
+		Project:   args.Project,
+		Stack:     args.Stack,
+		Component: args.Component,
+		Adopt:     &adopt,
+	})
+}
+
+// Moves ownership of a resource to another component, such as when a
+// component is replaced by one with a different name or type. Resources that
+// are locked by another task cannot be transferred.
+func (r *MutationResolver) TransferResource(ctx context.Context, args struct {
+	Ref       string
+	Component string
+}) (*ResourceResolver, error) {
+	resource, err := r.resourceByRef(ctx, &args.Ref)
+	if err := validateResolve("resource", args.Ref, resource, err); err != nil {
+		return nil, err
+	}
+	component, err := r.componentByRef(ctx, args.Component, nil)
+	if err := validateResolve("component", args.Component, component, err); err != nil {
+		return nil, err
+	}
+	stack, err := component.Stack(ctx)
+	if err := validateResolve("stack", component.StackID, stack, err); err != nil {
+		return nil, err
+	}
+
+	var taskID string
+	if ctxVars := api.CurrentContextVariables(ctx); ctxVars != nil {
+		taskID = ctxVars.TaskID
+	}
+	var row ResourceRow
+	err = r.db.GetContext(ctx, &row, `
+		UPDATE resource
+		SET project_id = ?, stack_id = ?, component_id = ?
+		WHERE id = ?
+		AND (task_id IS NULL OR task_id = ?)
+		RETURNING *
+	`, stack.ProjectID, stack.ID, component.ID, resource.ID, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, conflictErrorf("resource %s is busy", resource.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("updating owner: %w", err)
+	}
+	return &ResourceResolver{
+		Q:           r,
+		ResourceRow: row,
+	}, nil
+}
+
 type controlResourceFunc func(ctx context.Context, controller sdk.AResourceController, cfg *sdk.ResourceConfig, model *RawJSON) error
 
 func (r *MutationResolver) InitializeResource(ctx context.Context, args struct {
@@ -397,19 +510,40 @@ func (r *MutationResolver) UpdateResource(ctx context.Context, args struct {
 	Ref   string
 	Model JSONObject
 }) (*ResourceResolver, error) {
-	return r.controlResource(ctx, args.Ref,
+	// Updates may replace the underlying resource, as when a process is
+	// restarted with a new spec, so the resource may be re-identified.
+	return r.controlResourceEx(ctx, args.Ref, true,
 		func(ctx context.Context, ctrl sdk.AResourceController, cfg *sdk.ResourceConfig, model *RawJSON) error {
 			prev := *model
+			*model = jsonutil.MustMarshal(args.Model)
 			return ctrl.UpdateResource(ctx, cfg, &prev, model)
 		},
 	)
 }
 
+func (r *MutationResolver) ShutdownResource(ctx context.Context, args struct {
+	Ref string
+}) (*ResourceResolver, error) {
+	return r.controlResource(ctx, args.Ref,
+		func(ctx context.Context, ctrl sdk.AResourceController, cfg *sdk.ResourceConfig, model *RawJSON) error {
+			// A resource that is already gone needs no shutdown.
+			if err := ctrl.ShutdownResource(ctx, cfg, model); err != nil && httputil.StatusOf(err) != http.StatusGone {
+				return err
+			}
+			return nil
+		},
+	)
+}
+
 func (r *MutationResolver) DisposeResource(ctx context.Context, args struct {
 	Ref string
 }) (*VoidResolver, error) {
 	if _, err := r.controlResource(ctx, args.Ref,
 		func(ctx context.Context, ctrl sdk.AResourceController, cfg *sdk.ResourceConfig, model *RawJSON) error {
+			// A resource that is already gone needs no shutdown.
+			if err := ctrl.ShutdownResource(ctx, cfg, model); err != nil && httputil.StatusOf(err) != http.StatusGone {
+				return fmt.Errorf("shutting down: %w", err)
+			}
 			return ctrl.DeleteResource(ctx, cfg, model)
 		},
 	); err != nil {
@@ -427,7 +561,13 @@ func (r *ResourceResolver) controller(ctx context.Context) (sdk.AResourceControl
 	return controller, nil
 }
 
-func (r *MutationResolver) controlResource(ctx context.Context, ref string, f controlResourceFunc) (_ *ResourceResolver, doErr error) {
+func (r *MutationResolver) controlResource(ctx context.Context, ref string, f controlResourceFunc) (*ResourceResolver, error) {
+	return r.controlResourceEx(ctx, ref, false, f)
+}
+
+// Like controlResource, but if reidentify is true, a successful f may change
+// the IRI of an already identified resource.
+func (r *MutationResolver) controlResourceEx(ctx context.Context, ref string, reidentify bool, f controlResourceFunc) (_ *ResourceResolver, doErr error) {
 	ctxVars := api.CurrentContextVariables(ctx)
 	if ctxVars == nil || ctxVars.TaskID == "" {
 		return nil, errors.New("resource operations must be asynchronous")
@@ -471,19 +611,19 @@ func (r *MutationResolver) controlResource(ctx context.Context, ref string, f co
 	model := resource.RawModel
 
 	fErr := f(ctx, ctrl, cfg, &model)
-	if fErr != nil {
-		return nil, fmt.Errorf("controller failed: %w", err)
-	}
 
 	// Attempt to identify resource, even if f failed.
 	iri, identifyErr := ctrl.IdentifyResource(ctx, cfg, &model)
 	iri = strings.TrimSpace(iri)
-	if identifyErr == nil && iri != "" {
-		if resource.IRI != nil {
+	if resource.IRI != nil && *resource.IRI != "" {
+		switch {
+		case identifyErr != nil || iri == "" || iri == *resource.IRI:
+			iri = *resource.IRI
+		case reidentify && fErr == nil:
+			// Keep the new IRI.
+		default:
+			identifyErr = fmt.Errorf("cannot change IRI from %q to %q", *resource.IRI, iri)
 			iri = *resource.IRI
-			if resource.IRI != nil && *resource.IRI != iri {
-				identifyErr = fmt.Errorf("cannot change IRI from %q to %q", *resource.IRI, iri)
-			}
 		}
 	}
 
@@ -497,6 +637,9 @@ func (r *MutationResolver) controlResource(ctx context.Context, ref string, f co
 	`, model, iri, resource.ID); err != nil {
 		return nil, fmt.Errorf("recording model: %w", err)
 	}
+	if fErr != nil {
+		return nil, fmt.Errorf("controller failed: %w", fErr)
+	}
 	if identifyErr != nil {
 		return nil, fmt.Errorf("identifying: %w", identifyErr)
 	}
@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/deref/exo/internal/api"
+	"github.com/deref/exo/internal/config"
+	"github.com/deref/exo/internal/controllers"
 	"github.com/deref/exo/internal/gensym"
+	"github.com/deref/exo/internal/logsink"
 	"github.com/deref/exo/internal/token"
 	"github.com/deref/exo/internal/util/logging"
+	dockerclient "github.com/docker/docker/client"
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/jmoiron/sqlx"
 )
@@ -31,9 +36,33 @@ type RootResolver struct {
 	GUIEndpoint string
 	// Public interface of this RootResolver.
 	Service api.Service
+	// Defaults to a registry of the built-in controllers.
+	Controllers *controllers.Registry
+	// True for the exo daemon. Only the daemon runs plugins, which other peers
+	// connect to.
+	Daemon  bool
+	Plugins []config.PluginConfig
+	// Destinations that the daemon exports the events of all workspaces to.
+	LogSinks []config.LogSinkConfig
+	// Used by the built-in Docker controllers. Defaults to a client configured
+	// from the environment.
+	Docker *dockerclient.Client
+	// Interval at which the resource usage of running processes is recorded.
+	// Zero disables sampling, which only the daemon should perform.
+	MetricsInterval time.Duration
+	// Age at which recorded samples are discarded. Defaults to 24 hours.
+	MetricsMaxAge time.Duration
 
-	ulidgen *gensym.ULIDGenerator
-	db      *sqlx.DB
+	ulidgen  *gensym.ULIDGenerator
+	db       *sqlx.DB
+	plugins  []*controllers.Plugin
+	logSinks *logsink.Forwarder
+	// Raw log sink configs of each workspace, as last applied to logSinks.
+	workspaceLogSinks map[string]string
+	stopEventExport   context.CancelFunc
+	eventExportDone   chan struct{}
+	stopMetrics       context.CancelFunc
+	metricsDone       chan struct{}
 }
 
 func (r *RootResolver) Init(ctx context.Context) error {
@@ -61,10 +90,37 @@ func (r *RootResolver) Init(ctx context.Context) error {
 		return fmt.Errorf("migrating db: %w", err)
 	}
 
+	if err := r.initLogSinks(ctx); err != nil {
+		return err
+	}
+
+	if r.Docker == nil {
+		r.Docker, err = dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation())
+		if err != nil {
+			return fmt.Errorf("creating docker client: %w", err)
+		}
+	}
+
+	if r.Controllers == nil {
+		r.Controllers = controllers.NewRegistry()
+		if err := r.registerBuiltinControllers(); err != nil {
+			return fmt.Errorf("registering controllers: %w", err)
+		}
+	}
+	r.startPlugins(ctx)
+
+	if r.MetricsInterval > 0 {
+		r.startMetricsSampler(ctx)
+	}
+
 	return nil
 }
 
 func (r *RootResolver) Shutdown(ctx context.Context) error {
+	r.stopMetricsSampler()
+	r.stopPlugins()
+	r.stopLogSinks()
+
 	if err := r.db.Close(); err != nil {
 		return fmt.Errorf("closing sqlite db: %w", err)
 	}
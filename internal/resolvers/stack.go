@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/deref/exo/internal/gensym"
 	. "github.com/deref/exo/internal/scalars"
@@ -123,6 +124,46 @@ func (r *QueryResolver) stackByRef(ctx context.Context, ref *string) (*StackReso
 	return ws.Stack(ctx)
 }
 
+// How often watched stacks are re-resolved, so that subscribers observe
+// changes to process state and resource usage.
+const watchStackInterval = time.Second
+
+func (r *SubscriptionResolver) WatchStack(ctx context.Context, args struct {
+	Ref string
+}) (<-chan *StackResolver, error) {
+	stack, err := r.stackByRef(ctx, &args.Ref)
+	if err := validateResolve("stack", args.Ref, stack, err); err != nil {
+		return nil, err
+	}
+	c := make(chan *StackResolver, 1)
+	c <- stack
+	go func() {
+		defer close(c)
+		ticker := time.NewTicker(watchStackInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			stack, err := r.stackByID(ctx, &stack.ID)
+			if stack == nil || err != nil {
+				if err != nil && ctx.Err() == nil {
+					r.SystemLog.Infof("resolving watched stack: %v", err)
+				}
+				return
+			}
+			select {
+			case c <- stack:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
 func (r *QueryResolver) stackByProjectIDAndRef(ctx context.Context, projectID string, ref string) (*StackResolver, error) {
 	// Could move the filtering to the db-side, but not a big deal.
 	stack, err := r.stackByRef(ctx, &ref)
@@ -268,7 +309,7 @@ func (r *MutationResolver) DestroyStack(ctx context.Context, args struct {
 	if err != nil {
 		return nil, err
 	}
-	return r.startStackReconciliation(ctx, stack)
+	return r.startStackReconciliation(ctx, stack, nil, nil)
 }
 
 func (r *MutationResolver) disposeStack(ctx context.Context, id string) (*StackResolver, error) {
@@ -2,6 +2,9 @@ package resolvers
 
 import (
 	"context"
+
+	compose "github.com/deref/exo/internal/providers/docker/components"
+	docker "github.com/deref/exo/internal/providers/docker/resources"
 )
 
 type StoreResolver struct {
@@ -18,8 +21,8 @@ type StoreComponentResolver struct {
 
 func (r *QueryResolver) isStoreType(typ string) bool {
 	// TODO: Extensible.
-	switch typ {
-	case "volume":
+	switch r.Controllers.QualifyType(typ) {
+	case compose.VolumeType, docker.VolumeType:
 		return true
 	default:
 		return false
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/deref/exo/internal/logparse"
 	. "github.com/deref/exo/internal/scalars"
 )
 
@@ -65,6 +66,7 @@ func (r *StreamResolver) Events(ctx context.Context, args struct {
 	Prev      *int32
 	Next      *int32
 	IContains *string
+	Filter    *string
 }) (*EventPageResolver, error) {
 	q := eventQuery{
 		Filter: r.eventFilter(),
@@ -81,5 +83,12 @@ func (r *StreamResolver) Events(ctx context.Context, args struct {
 	if args.IContains != nil {
 		q.Filter.IContains = *args.IContains
 	}
+	if args.Filter != nil {
+		var err error
+		q.Filter.Tags, err = logparse.ParseFilter(*args.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("parsing filter: %w", err)
+		}
+	}
 	return r.Q.findEvents(ctx, q)
 }
@@ -53,6 +53,17 @@ func (r *RootResolver) insertRow(ctx context.Context, table string, row any) err
 
 // If row is a pointer, it will be updated with the results of `RETURNING *`.
 func (r *RootResolver) insertRowEx(ctx context.Context, table string, row any, extra string) error {
+	return insertRowEx(ctx, r.db, table, row, extra)
+}
+
+// Queries common to *sqlx.DB and *sqlx.Tx, so that helpers may be used
+// within or outside of a transaction.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+func insertRowEx(ctx context.Context, db dbtx, table string, row any, extra string) error {
 	v := reflect.ValueOf(row)
 	returning := false
 	if v.Kind() == reflect.Ptr {
@@ -97,9 +108,9 @@ func (r *RootResolver) insertRowEx(ctx context.Context, table string, row any, e
 	q := b.String()
 
 	if returning {
-		return r.db.GetContext(ctx, row, q, values...)
+		return db.GetContext(ctx, row, q, values...)
 	} else {
-		_, err := r.db.ExecContext(ctx, q, values...)
+		_, err := db.ExecContext(ctx, q, values...)
 		return err
 	}
 }
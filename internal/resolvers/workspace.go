@@ -2,11 +2,16 @@ package resolvers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"path/filepath"
 
 	"github.com/deref/exo/internal/gensym"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/internal/util/jsonutil"
 	"github.com/deref/exo/internal/util/pathutil"
 )
 
@@ -19,6 +24,10 @@ type WorkspaceRow struct {
 	ID        string `db:"id"`
 	Root      string `db:"root"`
 	ProjectID string `db:"project_id"`
+	// JSON array of config.LogSinkConfig, or null.
+	RawLogSinks RawJSON `db:"log_sinks"`
+	// JSON array of active profile names, or null.
+	RawProfiles RawJSON `db:"profiles"`
 }
 
 func workspaceRowsToResolvers(r *RootResolver, rows []WorkspaceRow) []*WorkspaceResolver {
@@ -193,6 +202,44 @@ func (r *WorkspaceResolver) FileSystem() *FileSystemResolver {
 	return r.Q.fileSystemByHostPath(r.Root)
 }
 
+func (r *WorkspaceResolver) Profiles() ([]string, error) {
+	profiles := []string{}
+	if len(r.RawProfiles) == 0 {
+		return profiles, nil
+	}
+	err := json.Unmarshal(r.RawProfiles, &profiles)
+	return profiles, err
+}
+
+func (r *MutationResolver) SetWorkspaceProfiles(ctx context.Context, args struct {
+	Workspace string
+	Profiles  []string
+}) (*WorkspaceResolver, error) {
+	workspace, err := r.workspaceByRef(ctx, &args.Workspace)
+	if err := validateResolve("workspace", args.Workspace, workspace, err); err != nil {
+		return nil, err
+	}
+	for _, profile := range args.Profiles {
+		if profile == "" {
+			return nil, errutil.HTTPErrorf(http.StatusBadRequest, "profile name must not be empty")
+		}
+	}
+
+	var raw RawJSON
+	if len(args.Profiles) > 0 {
+		raw = jsonutil.MustMarshal(args.Profiles)
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE workspace
+		SET profiles = ?
+		WHERE id = ?
+	`, raw, workspace.ID); err != nil {
+		return nil, fmt.Errorf("updating workspace: %w", err)
+	}
+	workspace.RawProfiles = raw
+	return workspace, nil
+}
+
 func (r *MutationResolver) BuildWorkspace(ctx context.Context, args struct {
 	Workspace string
 }) (*VoidResolver, error) {
@@ -222,40 +269,85 @@ func (r *MutationResolver) RefreshWorkspaceComponents(ctx context.Context, args
 func (r *MutationResolver) StartWorkspace(ctx context.Context, args struct {
 	Workspace string
 }) (*VoidResolver, error) {
-	return nil, errors.New("NOT YET IMPLEMENTED")
+	return nil, r.controlWorkspaceComponents(ctx, args.Workspace, nil, "reconcileComponent")
 }
 
 func (r *MutationResolver) StartWorkspaceComponents(ctx context.Context, args struct {
 	Workspace  string
 	Components []string
 }) (*VoidResolver, error) {
-	return nil, errors.New("NOT YET IMPLEMENTED")
+	return nil, r.controlWorkspaceComponents(ctx, args.Workspace, args.Components, "reconcileComponent")
 }
 
 func (r *MutationResolver) RestartWorkspace(ctx context.Context, args struct {
 	Workspace string
 }) (*VoidResolver, error) {
-	return nil, errors.New("NOT YET IMPLEMENTED")
+	return nil, r.controlWorkspaceComponents(ctx, args.Workspace, nil, "restartComponent")
 }
 
 func (r *MutationResolver) RestartWorkspaceComponents(ctx context.Context, args struct {
 	Workspace  string
 	Components []string
 }) (*VoidResolver, error) {
-	return nil, errors.New("NOT YET IMPLEMENTED")
+	return nil, r.controlWorkspaceComponents(ctx, args.Workspace, args.Components, "restartComponent")
 }
 
 func (r *MutationResolver) StopWorkspace(ctx context.Context, args struct {
 	Workspace string
 }) (*VoidResolver, error) {
-	return nil, errors.New("NOT YET IMPLEMENTED")
+	return nil, r.controlWorkspaceComponents(ctx, args.Workspace, nil, "shutdownComponent")
 }
 
 func (r *MutationResolver) StopWorkspaceComponents(ctx context.Context, args struct {
 	Workspace  string
 	Components []string
 }) (*VoidResolver, error) {
-	return nil, errors.New("NOT YET IMPLEMENTED")
+	return nil, r.controlWorkspaceComponents(ctx, args.Workspace, args.Components, "shutdownComponent")
+}
+
+// Creates a task for each of the named components of a workspace's stack,
+// or for all of its components if refs is nil. Stopping a component shuts it
+// down, which retains its resources, and starting it reconciles it, which
+// starts them again.
+func (r *MutationResolver) controlWorkspaceComponents(ctx context.Context, workspaceRef string, refs []string, mutation string) error {
+	workspace, err := r.workspaceByRef(ctx, &workspaceRef)
+	if err := validateResolve("workspace", workspaceRef, workspace, err); err != nil {
+		return err
+	}
+	stack, err := workspace.Stack(ctx)
+	if err != nil {
+		return fmt.Errorf("resolving stack: %w", err)
+	}
+	if stack == nil {
+		return errutil.HTTPErrorf(http.StatusConflict, "workspace %q has no stack", workspaceRef)
+	}
+	var components []*ComponentResolver
+	if refs == nil {
+		components, err = stack.components(ctx)
+		if err != nil {
+			return fmt.Errorf("resolving components: %w", err)
+		}
+	} else {
+		components = make([]*ComponentResolver, len(refs))
+		for i, ref := range refs {
+			components[i], err = stack.componentByRef(ctx, ref)
+			if err := validateResolve("component", ref, components[i], err); err != nil {
+				return err
+			}
+		}
+	}
+	taskInputs := make([]TaskInput, len(components))
+	for i, component := range components {
+		taskInputs[i] = TaskInput{
+			Mutation: mutation,
+			Arguments: map[string]any{
+				"stack": stack.ID,
+				"ref":   component.ID,
+			},
+		}
+	}
+	_, err = r.createTasks(ctx, taskInputs)
+	return err
 }
 
 func (r *WorkspaceResolver) Components(ctx context.Context, args struct {
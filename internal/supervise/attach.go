@@ -0,0 +1,243 @@
+package supervise
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/deref/exo/internal/util/sysutil"
+)
+
+// Attach messages are exchanged with clients of a supervisor's attach socket.
+// Each message is framed as a one byte type, followed by a big-endian uint32
+// payload length, followed by the payload.
+const (
+	// Terminal output when sent by the supervisor, or input when sent by a
+	// client.
+	AttachData = 'd'
+	// Sent by clients when their window size changes. The payload is the number
+	// of rows followed by the number of columns, each as a big-endian uint16.
+	AttachResize = 'r'
+)
+
+// Bounds the payload of attach messages, so that a misbehaving peer cannot
+// cause unbounded allocation.
+const maxAttachPayload = 64 * 1024
+
+type AttachMessage struct {
+	Type byte
+	// Payload of data messages.
+	Data []byte
+	// Payload of resize messages.
+	Rows uint16
+	Cols uint16
+}
+
+func WriteAttachMessage(w io.Writer, msg AttachMessage) error {
+	payload := msg.Data
+	if msg.Type == AttachResize {
+		payload = make([]byte, 4)
+		binary.BigEndian.PutUint16(payload[0:2], msg.Rows)
+		binary.BigEndian.PutUint16(payload[2:4], msg.Cols)
+	}
+	if len(payload) > maxAttachPayload {
+		return fmt.Errorf("attach message payload of %d bytes exceeds maximum of %d", len(payload), maxAttachPayload)
+	}
+	// Written with a single call, so that message-oriented transports such as
+	// websockets deliver each message in one frame.
+	frame := make([]byte, 5+len(payload))
+	frame[0] = msg.Type
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	_, err := w.Write(frame)
+	return err
+}
+
+func ReadAttachMessage(r io.Reader) (AttachMessage, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return AttachMessage{}, err
+	}
+	msg := AttachMessage{Type: header[0]}
+	n := binary.BigEndian.Uint32(header[1:5])
+	if n > maxAttachPayload {
+		return AttachMessage{}, fmt.Errorf("attach message payload of %d bytes exceeds maximum of %d", n, maxAttachPayload)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return AttachMessage{}, err
+	}
+	switch msg.Type {
+	case AttachData:
+		msg.Data = payload
+	case AttachResize:
+		if len(payload) != 4 {
+			return AttachMessage{}, fmt.Errorf("expected 4 byte resize payload, got %d", len(payload))
+		}
+		msg.Rows = binary.BigEndian.Uint16(payload[0:2])
+		msg.Cols = binary.BigEndian.Uint16(payload[2:4])
+	default:
+		return AttachMessage{}, fmt.Errorf("unknown attach message type: %q", msg.Type)
+	}
+	return msg, nil
+}
+
+// Recent output is replayed to newly attached clients, so that they are not
+// greeted by a blank screen while the child waits for input.
+const attachReplaySize = 8 * 1024
+
+// Clients that do not accept output within this time are detached, rather
+// than stalling the child.
+const attachWriteTimeout = time.Second
+
+// attachServer relays a child's pseudo-terminal to clients connected to a
+// Unix domain socket. The socket outlives individual children, so that
+// clients may remain attached across restarts.
+type attachServer struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	pty     *os.File
+	rows    uint16
+	cols    uint16
+	clients map[net.Conn]struct{}
+	replay  []byte
+}
+
+func listenAttach(path string) (*attachServer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on attach socket: %w", err)
+	}
+	srv := &attachServer{
+		listener: listener,
+		clients:  make(map[net.Conn]struct{}),
+	}
+	go srv.serve()
+	return srv, nil
+}
+
+func (srv *attachServer) Close() error {
+	srv.mu.Lock()
+	for conn := range srv.clients {
+		_ = conn.Close()
+	}
+	srv.mu.Unlock()
+	// Closing a Unix listener also removes its socket file.
+	return srv.listener.Close()
+}
+
+func (srv *attachServer) serve() {
+	for {
+		conn, err := srv.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			log.Printf("accepting attach connection: %v", err)
+			continue
+		}
+		go srv.handle(conn)
+	}
+}
+
+func (srv *attachServer) handle(conn net.Conn) {
+	srv.mu.Lock()
+	_ = conn.SetWriteDeadline(time.Now().Add(attachWriteTimeout))
+	err := WriteAttachMessage(conn, AttachMessage{Type: AttachData, Data: srv.replay})
+	if err == nil {
+		srv.clients[conn] = struct{}{}
+	}
+	srv.mu.Unlock()
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	defer srv.detach(conn)
+
+	for {
+		msg, err := ReadAttachMessage(conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("reading attach message: %v", err)
+			}
+			return
+		}
+		srv.mu.Lock()
+		pty := srv.pty
+		if msg.Type == AttachResize {
+			srv.rows, srv.cols = msg.Rows, msg.Cols
+		}
+		srv.mu.Unlock()
+		if pty == nil {
+			// Input is discarded while the child is restarting.
+			continue
+		}
+		switch msg.Type {
+		case AttachData:
+			_, err = pty.Write(msg.Data)
+		case AttachResize:
+			err = sysutil.SetPtySize(pty, msg.Rows, msg.Cols)
+		}
+		if err != nil {
+			log.Printf("handling attach message: %v", err)
+		}
+	}
+}
+
+func (srv *attachServer) detach(conn net.Conn) {
+	srv.mu.Lock()
+	delete(srv.clients, conn)
+	srv.mu.Unlock()
+	_ = conn.Close()
+}
+
+// Sets the terminal of the current child, or nil if there is none. The most
+// recently requested window size is applied to the new terminal.
+func (srv *attachServer) setPty(pty *os.File) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.pty = pty
+	if pty != nil && srv.rows > 0 && srv.cols > 0 {
+		if err := sysutil.SetPtySize(pty, srv.rows, srv.cols); err != nil {
+			log.Printf("setting pty size: %v", err)
+		}
+	}
+}
+
+// Write broadcasts terminal output to attached clients.
+func (srv *attachServer) Write(p []byte) (int, error) {
+	n := len(p)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.replay = append(srv.replay, p...)
+	if excess := len(srv.replay) - attachReplaySize; excess > 0 {
+		srv.replay = append(srv.replay[:0], srv.replay[excess:]...)
+	}
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > maxAttachPayload {
+			chunk = chunk[:maxAttachPayload]
+		}
+		p = p[len(chunk):]
+		for conn := range srv.clients {
+			_ = conn.SetWriteDeadline(time.Now().Add(attachWriteTimeout))
+			if err := WriteAttachMessage(conn, AttachMessage{Type: AttachData, Data: chunk}); err != nil {
+				delete(srv.clients, conn)
+				_ = conn.Close()
+			}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package supervise
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachMessageRoundTrip(t *testing.T) {
+	messages := []AttachMessage{
+		{Type: AttachData, Data: []byte("hello\r\n")},
+		{Type: AttachResize, Rows: 24, Cols: 80},
+		{Type: AttachData, Data: []byte{}},
+	}
+	var buf bytes.Buffer
+	for _, msg := range messages {
+		require.NoError(t, WriteAttachMessage(&buf, msg))
+	}
+	for _, expected := range messages {
+		actual, err := ReadAttachMessage(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, err := ReadAttachMessage(bytes.NewReader([]byte{'x', 0, 0, 0, 0}))
+	assert.Error(t, err)
+	assert.Error(t, WriteAttachMessage(&buf, AttachMessage{
+		Type: AttachData,
+		Data: make([]byte, maxAttachPayload+1),
+	}))
+}
+
+func TestAttachServerReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attach.sock")
+	srv, err := listenAttach(path)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	_, _ = srv.Write([]byte(strings.Repeat("x", attachReplaySize)))
+	_, _ = srv.Write([]byte("prompt> "))
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	msg, err := ReadAttachMessage(conn)
+	require.NoError(t, err)
+	assert.Len(t, msg.Data, attachReplaySize)
+	assert.True(t, bytes.HasSuffix(msg.Data, []byte("prompt> ")))
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/deref/exo/internal/logparse"
+	"github.com/deref/exo/internal/util/cgrouputil"
 )
 
 type Config struct {
@@ -11,8 +14,37 @@ type Config struct {
 	WorkingDirectory string
 	Environment      map[string]string
 	SyslogPort       uint
-	Program          string
-	Arguments        []string
+	// Transport used to send logs. One of "tcp", "udp", or "unix". Defaults to
+	// "udp". Unlike udp, the stream transports do not lose messages when the
+	// log collector falls behind, but they require the collector to be
+	// listening when the supervisor starts. If it is not, the supervisor falls
+	// back to udp, provided SyslogPort is set.
+	SyslogNetwork string
+	// Path of the log collector's Unix domain socket, if SyslogNetwork is
+	// "unix".
+	SyslogSocket string
+	Program      string
+	Arguments    []string
+
+	// Restart policy applied when the child exits.
+	Restart RestartPolicy
+	// If positive, limits the number of times the child will be restarted.
+	MaxRestarts int
+	// If non-empty, path of file to which the supervisor writes its Status.
+	StatusFile string
+	// Format of the child's output, which is sent along with each message as
+	// syslog structured data, so that fields may be parsed in to event tags.
+	LogFormat string
+	// If true, the child is run under a pseudo-terminal, rather than with piped
+	// stdio. Its output is logged as stdout.
+	TTY bool
+	// If non-empty, path of a Unix domain socket on which the supervisor relays
+	// the child's terminal to attached clients. Requires TTY.
+	AttachSocket string
+	// If non-empty, path of a cgroup v2 control group in which to place the
+	// child, which the supervisor creates with Limits applied.
+	Cgroup string
+	Limits cgrouputil.Limits
 }
 
 func (cfg *Config) Validate() error {
@@ -23,12 +55,33 @@ func (cfg *Config) Validate() error {
 	if cfg.WorkingDirectory == "" {
 		errorMessages = append(errorMessages, "missing WorkingDirectory")
 	}
-	if cfg.SyslogPort == 0 {
-		errorMessages = append(errorMessages, "missing SyslogPort")
+	switch cfg.syslogNetwork() {
+	case "tcp", "udp":
+		if cfg.SyslogPort == 0 {
+			errorMessages = append(errorMessages, "missing SyslogPort")
+		}
+	case "unix":
+		if cfg.SyslogSocket == "" {
+			errorMessages = append(errorMessages, "missing SyslogSocket")
+		}
+	default:
+		errorMessages = append(errorMessages, fmt.Sprintf("invalid SyslogNetwork: %q", cfg.SyslogNetwork))
 	}
 	if cfg.Program == "" {
 		errorMessages = append(errorMessages, "missing Program")
 	}
+	if err := cfg.Restart.Validate(); err != nil {
+		errorMessages = append(errorMessages, err.Error())
+	}
+	if _, err := logparse.ParseFormat(cfg.LogFormat); err != nil {
+		errorMessages = append(errorMessages, err.Error())
+	}
+	if !cfg.Limits.IsZero() && cfg.Cgroup == "" {
+		errorMessages = append(errorMessages, "Limits requires Cgroup")
+	}
+	if cfg.AttachSocket != "" && !cfg.TTY {
+		errorMessages = append(errorMessages, "AttachSocket requires TTY")
+	}
 
 	if len(errorMessages) > 0 {
 		return fmt.Errorf("invalid supervisor config: %s", strings.Join(errorMessages, "; "))
@@ -37,6 +90,13 @@ func (cfg *Config) Validate() error {
 	return nil
 }
 
+func (cfg *Config) syslogNetwork() string {
+	if cfg.SyslogNetwork == "" {
+		return "udp"
+	}
+	return cfg.SyslogNetwork
+}
+
 func MustEncodeConfig(cfg *Config) []byte {
 	out, err := json.Marshal(cfg)
 	if err != nil {
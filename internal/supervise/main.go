@@ -9,30 +9,42 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
-	"net"
 	"os"
 	"os/exec"
 	"os/signal"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/deref/exo/internal/chrono"
 	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/logparse"
+	"github.com/deref/exo/internal/util/cgrouputil"
 	"github.com/deref/exo/internal/util/osutil"
 	"github.com/deref/exo/internal/util/sysutil"
-	"github.com/influxdata/go-syslog/v3/rfc5424"
 )
 
 var pgrp int
 
+// Process group of the child, if it runs under a pseudo-terminal. Such
+// children lead their own session, so are not in the supervisor's group.
+var ttyPgrp int32
+
 func Main() {
 	var crashFile *os.File
+	var attach *attachServer
+	var cgroup *cgrouputil.Group
 	cleanExit := func() {
 		if crashFile != nil {
 			_ = os.Remove(crashFile.Name())
 		}
+		if attach != nil {
+			_ = attach.Close()
+		}
+		if cgroup != nil {
+			_ = cgroup.Remove()
+		}
 		os.Exit(0)
 	}
 
@@ -47,11 +59,137 @@ func Main() {
 		fatalf("validating config: %v", err)
 	}
 
-	udpAddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("localhost:%d", cfg.SyslogPort))
+	// Dial syslog.
+	conn, err := dialSyslog(cfg)
 	if err != nil {
-		fatalf("resolving udp address: %v", err)
+		fatalf("%v", err)
+	}
+	defer conn.Close()
+
+	if cfg.AttachSocket != "" {
+		attach, err = listenAttach(cfg.AttachSocket)
+		if err != nil {
+			fatalf("%v", err)
+		}
 	}
 
+	if cfg.Cgroup != "" {
+		cgroup, err = cgrouputil.Create(cfg.Cgroup, cfg.Limits)
+		if err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	// Register for signals.  Do this before starting the child to
+	// guarantee we see any termination requests before deciding whether or not
+	// to restart an exited child.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	var terminating int32
+	go func() {
+		for sig := range c {
+			// We expect exo to send these to the whole group. This means that a
+			// well behaved child will handle SIGTERM and exit. However, we must
+			// ignore these signals so that we don't stop processing logs before
+			// the child stops sending them! We also must not restart the child.
+			atomic.StoreInt32(&terminating, 1)
+			// Children with a terminal are outside of the group, so must be
+			// signalled directly.
+			if pid := atomic.LoadInt32(&ttyPgrp); pid != 0 {
+				_ = syscall.Kill(-int(pid), sig.(syscall.Signal))
+			}
+		}
+	}()
+
+	var restartBackoff backoff
+	status := Status{}
+	for {
+		started := time.Now()
+		oomKills := countOOMKills(cgroup)
+		exitCode, err := runChild(ctx, cfg, conn, attach, func(pid int) {
+			// Descendants of the child are placed in its cgroup too. Those started
+			// before it is moved escape the limits, but the child has not had
+			// much opportunity to start any.
+			if cgroup != nil {
+				if err := cgroup.AddProcess(pid); err != nil {
+					// The child must not run without its limits, nor be orphaned by
+					// the supervisor exiting.
+					killChild(pid)
+					fatalf("placing child in cgroup: %v", err)
+				}
+			}
+			status.Pid = pid
+			status.ExitCode = nil
+			if err := writeStatus(cfg.StatusFile, status); err != nil {
+				log.Printf("writing status: %v", err)
+			}
+			if status.Restarts > 0 {
+				logSystemEventf(ctx, conn, cfg.ComponentID, pid, "restarted process (restart %d)", status.Restarts)
+				return
+			}
+
+			// Reporting child pid to stdout.
+			if _, err := fmt.Println(pid); err != nil {
+				fatalf("reporting pid: %v", err)
+			}
+
+			// NOTE [SUPERVISE_STDERR]: The "started ok" message will release any readers
+			// who are waiting for a message on stderr. Then we redirect stderr to a temp
+			// file so that if any supervision failures happen, we have a crash log we
+			// can inspect.
+			_, _ = fmt.Fprintf(os.Stderr, "started ok\n")
+			crashFile, _ = ioutil.TempFile("", "supervise.*.stderr")
+			if crashFile != nil {
+				_ = sysutil.Dup2(int(crashFile.Fd()), 2)
+			}
+
+			log.Println("supervisor pid:", os.Getpid())
+			log.Println("child pid:", pid)
+		})
+		if err != nil {
+			if status.Restarts == 0 {
+				fatalf("%v", err)
+			}
+			logSystemEventf(ctx, conn, cfg.ComponentID, status.Pid, "error restarting process: %v", err)
+			exitCode = -1
+		}
+		status.ExitCode = &exitCode
+		if countOOMKills(cgroup) > oomKills {
+			if cfg.Limits.MemoryBytes > 0 {
+				logSystemEventf(ctx, conn, cfg.ComponentID, status.Pid, "process was killed for exceeding its memory limit of %d bytes", cfg.Limits.MemoryBytes)
+			} else {
+				logSystemEventf(ctx, conn, cfg.ComponentID, status.Pid, "process was killed for running out of memory")
+			}
+		}
+
+		restart := atomic.LoadInt32(&terminating) == 0 && cfg.Restart.ShouldRestart(exitCode)
+		if restart && cfg.MaxRestarts > 0 && status.Restarts >= cfg.MaxRestarts {
+			logSystemEventf(ctx, conn, cfg.ComponentID, status.Pid, "process exited with status %d; not restarting after %d restarts", exitCode, status.Restarts)
+			restart = false
+		}
+		if !restart {
+			if err := writeStatus(cfg.StatusFile, status); err != nil {
+				log.Printf("writing status: %v", err)
+			}
+			cleanExit()
+		}
+
+		delay := restartBackoff.next(time.Since(started))
+		status.Restarts++
+		if err := writeStatus(cfg.StatusFile, status); err != nil {
+			log.Printf("writing status: %v", err)
+		}
+		logSystemEventf(ctx, conn, cfg.ComponentID, status.Pid, "process exited with status %d; restarting in %s", exitCode, delay)
+		time.Sleep(delay)
+		if atomic.LoadInt32(&terminating) != 0 {
+			cleanExit()
+		}
+	}
+}
+
+// runChild starts the configured program, forwards its output to syslog, and
+// waits for it to exit. If the child cannot be started, an error is returned.
+func runChild(ctx context.Context, cfg *Config, conn *syslogWriter, attach *attachServer, onStart func(pid int)) (exitCode int, err error) {
 	cmd := exec.Command(cfg.Program, cfg.Arguments...)
 	cmd.Dir = cfg.WorkingDirectory
 	cmd.Env = make([]string, 0, len(cfg.Environment))
@@ -59,73 +197,33 @@ func Main() {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, val))
 	}
 
-	// Connect pipes.
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		panic(err)
-	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		panic(err)
+	var stdio *childStdio
+	if cfg.TTY {
+		stdio, err = connectTTY(cmd, attach)
+	} else {
+		stdio, err = connectPipes(cmd)
 	}
-
-	// Dial syslog.
-	conn, err := net.DialUDP("udp", nil, udpAddr)
 	if err != nil {
-		fatalf("dialing udp: %v", err)
+		return 0, err
 	}
-	defer conn.Close()
-
-	// Register for signals.  Do this before starting the child to
-	// guarantee we see any exits of a child process.
-	c := make(chan os.Signal)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGCHLD)
+	defer stdio.close()
 
 	// Start child process.
-	if err := cmd.Start(); err != nil {
-		fatalf("%v", err)
+	err = cmd.Start()
+	stdio.started()
+	if err != nil {
+		return 0, err
 	}
 	child := cmd.Process
-
-	// Reporting child pid to stdout.
-	if _, err := fmt.Println(child.Pid); err != nil {
-		fatalf("reporting pid: %v", err)
+	if cfg.TTY {
+		atomic.StoreInt32(&ttyPgrp, int32(child.Pid))
+		defer atomic.StoreInt32(&ttyPgrp, 0)
 	}
-
-	// NOTE [SUPERVISE_STDERR]: The "started ok" message will release any readers
-	// who are waiting for a message on stderr. Then we redirect stderr to a temp
-	// file so that if any supervision failures happen, we have a crash log we
-	// can inspect.
-	_, _ = fmt.Fprintf(os.Stderr, "started ok\n")
-	crashFile, _ = ioutil.TempFile("", "supervise.*.stderr")
-	if crashFile != nil {
-		_ = sysutil.Dup2(int(crashFile.Fd()), 2)
-	}
-
-	log.Println("supervisor pid:", os.Getpid())
-	log.Println("child pid:", child.Pid)
-
-	// Asynchronously handle SIGCHILD. We spawn this goroutine after starting the
-	// child, so that we don't have to coordinate concurrent access to the child
-	// variable.
-	go func() {
-		for sig := range c {
-			switch sig {
-			case syscall.SIGINT, syscall.SIGTERM:
-				// We expect exo to send these to the whole group. This means that a
-				// well behaved child will handle SIGTERM, leading to us receiving
-				// SIGCHLD. However, we must ignore these signals so that we don't stop
-				// processing logs before the child stops sending them!
-			case syscall.SIGCHLD:
-				// Allow a little extra time to gather shutdown logs from the child.
-				<-time.After(1 * time.Second)
-				cleanExit()
-			}
-		}
-	}()
+	onStart(child.Pid)
 
 	// Proxy logs.
 	syslogProcID := strconv.Itoa(child.Pid)
+	logFormat, _ := logparse.ParseFormat(cfg.LogFormat) // Validated with config.
 
 	var wg sync.WaitGroup
 	work := func(f func()) {
@@ -135,70 +233,98 @@ func Main() {
 			f()
 		}()
 	}
-	work(func() {
-		pipeToSyslog(ctx, conn, cfg.ComponentID, "out", syslogProcID, stdout)
-	})
-	work(func() {
-		pipeToSyslog(ctx, conn, cfg.ComponentID, "err", syslogProcID, stderr)
-	})
+	for name, r := range stdio.outputs {
+		name, r := name, r
+		work(func() {
+			pipeToSyslog(ctx, conn, cfg.ComponentID, name, syslogProcID, logFormat, r)
+		})
+	}
 
-	// Wait for child process and log forwarding to exit.
+	// Wait for child process to exit.
 	err = cmd.Wait()
-	wg.Wait()
+	exitCode = cmd.ProcessState.ExitCode()
 	if _, ok := err.(*exec.ExitError); ok {
-		cleanExit()
+		err = nil
 	}
 	if err != nil {
 		fatalf("wait error: %v", err)
 	}
+
+	// Allow a little extra time to gather shutdown logs from the child. Logs
+	// may remain open beyond this, if the child has passed its stdio on to
+	// background processes of its own.
+	logsDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(logsDone)
+	}()
+	select {
+	case <-logsDone:
+	case <-time.After(1 * time.Second):
+	}
+	return exitCode, nil
 }
 
-func pipeToSyslog(ctx context.Context, conn net.Conn, componentID string, name string, procID string, r io.Reader) {
-	b := bufio.NewReaderSize(r, api.MaxMessageSize)
-	readLine := func() (string, error) {
-		// Usage of ReadLine in preference to ReadString is intentional, since
-		// ReadString will perform unbounded buffering.
-		// See discussion here: https://github.com/deref/exo/pull/322
-		message, isPrefix, err := b.ReadLine()
-		for isPrefix {
-			// Skip remainder of line.
-			_, isPrefix, err = b.ReadLine()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				fatalf("reading %s: %v", name, err)
-			}
-		}
-		return string(message), err
+// Kills the child immediately. Children with a terminal lead their own
+// process group, which is killed along with them.
+func killChild(pid int) {
+	if atomic.LoadInt32(&ttyPgrp) == int32(pid) {
+		_ = syscall.Kill(-pid, syscall.SIGKILL)
+		return
+	}
+	_ = syscall.Kill(pid, syscall.SIGKILL)
+}
+
+func countOOMKills(cgroup *cgrouputil.Group) uint64 {
+	if cgroup == nil {
+		return 0
+	}
+	stats, err := cgroup.Stats()
+	if err != nil {
+		log.Printf("reading cgroup stats: %v", err)
+	}
+	return stats.OOMKills
+}
+
+func logSystemEventf(ctx context.Context, conn *syslogWriter, componentID string, pid int, format string, v ...any) {
+	message := fmt.Sprintf(format, v...)
+	if err := conn.Send(ctx, componentID, "sys", strconv.Itoa(pid), message, logparse.FormatPlain, false); err != nil {
+		log.Printf("sending syslog message: %v", err)
 	}
+}
 
+// Sends each line read from r as a message. Lines longer than the read buffer
+// are sent in parts as they are read, so that they need not be buffered. Any
+// remainder of a line beyond api.MaxLineSize is discarded.
+func pipeToSyslog(ctx context.Context, conn *syslogWriter, componentID string, name string, procID string, format logparse.Format, r io.Reader) {
+	// Usage of ReadLine in preference to ReadString is intentional, since
+	// ReadString will perform unbounded buffering.
+	// See discussion here: https://github.com/deref/exo/pull/322
+	b := bufio.NewReaderSize(r, api.MaxMessageSize)
+	lineSize := 0
+	// True if parts of the current line have been sent.
+	continuing := false
 	for {
-		message, err := readLine()
+		chunk, isPrefix, err := b.ReadLine()
+		if remaining := api.MaxLineSize - lineSize; len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		lineSize += len(chunk)
+		partial := isPrefix && err == nil
 
 		// Error handling is performed after piping the message to syslog since we
-		// always want to write the message, even if an error has occurred.
-		if message != "" {
-			if message[len(message)-1] == '\n' {
-				message = message[:len(message)-1]
-			}
-			sm := &rfc5424.SyslogMessage{}
-			sm.SetVersion(1)
-			sm.SetPriority(syslogPriority)
-			sm.SetTimestamp(chrono.Now(ctx).Format(chrono.RFC3339MicroUTC))
-			sm.SetAppname(componentID)
-			sm.SetProcID(procID)
-			sm.SetMsgID(name) // See note: [SYSLOG_MSG_ID].
-			sm.SetMessage(message)
-			packet, err := sm.String()
-			if err != nil {
-				fatalf("building syslog message: %w", err)
-			}
-			if _, err := io.WriteString(conn, packet); err != nil {
+		// always want to write the message, even if an error has occurred. The
+		// final part of a long line is always sent, even if empty, to complete it.
+		if len(chunk) > 0 || (continuing && !partial) {
+			if err := conn.Send(ctx, componentID, name, procID, string(chunk), format, partial); err != nil {
 				log.Printf("sending syslog message: %v", err)
 			}
+			continuing = partial
 		}
-		if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) {
+		if !partial {
+			lineSize = 0
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) || errors.Is(err, io.ErrClosedPipe) {
 			return
 		}
 		if err != nil {
@@ -217,5 +343,8 @@ func fatalf(format string, v ...any) {
 }
 
 func die() {
+	if pid := atomic.LoadInt32(&ttyPgrp); pid != 0 {
+		_ = osutil.KillGroup(int(pid))
+	}
 	_ = osutil.KillGroup(pgrp)
 }
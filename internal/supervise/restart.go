@@ -0,0 +1,106 @@
+package supervise
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/natefinch/atomic"
+)
+
+type RestartPolicy string
+
+const (
+	RestartNever     RestartPolicy = "never"
+	RestartOnFailure RestartPolicy = "on-failure"
+	RestartAlways    RestartPolicy = "always"
+)
+
+func (policy RestartPolicy) Validate() error {
+	switch policy {
+	case "", RestartNever, RestartOnFailure, RestartAlways:
+		return nil
+	default:
+		return fmt.Errorf("invalid restart policy: %q", policy)
+	}
+}
+
+// ShouldRestart reports whether a child that exited with the given status
+// should be restarted.
+func (policy RestartPolicy) ShouldRestart(exitCode int) bool {
+	switch policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return exitCode != 0
+	default:
+		return false
+	}
+}
+
+const MinRestartDelay = 1 * time.Second
+const MaxRestartDelay = 1 * time.Minute
+
+// A child that stays up for at least this long is considered to have
+// recovered, so the next restart delay starts over from the minimum.
+const restartResetPeriod = MaxRestartDelay
+
+type backoff struct {
+	delay time.Duration
+}
+
+func (b *backoff) next(uptime time.Duration) time.Duration {
+	if b.delay == 0 || uptime >= restartResetPeriod {
+		b.delay = MinRestartDelay
+	} else {
+		b.delay *= 2
+		if b.delay > MaxRestartDelay {
+			b.delay = MaxRestartDelay
+		}
+	}
+	return b.delay
+}
+
+// Status is written by the supervisor to Config.StatusFile each time the child
+// starts or exits, so that the daemon can track the current child across
+// restarts.
+type Status struct {
+	Pid      int  `json:"pid"`
+	Restarts int  `json:"restarts"`
+	ExitCode *int `json:"exitCode,omitempty"`
+}
+
+// Returns the path of the status file of a component's supervisor within the
+// daemon's var directory.
+func StatusPath(varDir string, componentID string) string {
+	return filepath.Join(varDir, "supervise", componentID+".json")
+}
+
+func ReadStatus(path string) (*Status, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var status Status
+	if err := json.Unmarshal(bs, &status); err != nil {
+		return nil, fmt.Errorf("unmarshalling status: %w", err)
+	}
+	return &status, nil
+}
+
+func writeStatus(path string, status Status) error {
+	if path == "" {
+		return nil
+	}
+	bs, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return atomic.WriteFile(path, bytes.NewReader(bs))
+}
@@ -0,0 +1,51 @@
+package supervise
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartPolicy(t *testing.T) {
+	assert.NoError(t, RestartPolicy("").Validate())
+	assert.NoError(t, RestartOnFailure.Validate())
+	assert.Error(t, RestartPolicy("sometimes").Validate())
+
+	assert.False(t, RestartPolicy("").ShouldRestart(1))
+	assert.False(t, RestartNever.ShouldRestart(1))
+	assert.False(t, RestartOnFailure.ShouldRestart(0))
+	assert.True(t, RestartOnFailure.ShouldRestart(1))
+	assert.True(t, RestartAlways.ShouldRestart(0))
+}
+
+func TestBackoff(t *testing.T) {
+	var b backoff
+	assert.Equal(t, MinRestartDelay, b.next(0))
+	assert.Equal(t, 2*MinRestartDelay, b.next(0))
+	assert.Equal(t, 4*MinRestartDelay, b.next(time.Second))
+	for i := 0; i < 10; i++ {
+		b.next(0)
+	}
+	assert.Equal(t, MaxRestartDelay, b.next(0))
+
+	// Recovered children start over.
+	assert.Equal(t, MinRestartDelay, b.next(restartResetPeriod))
+}
+
+func TestStatusRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status", "process.json")
+	exitCode := 3
+	status := Status{Pid: 123, Restarts: 2, ExitCode: &exitCode}
+	if !assert.NoError(t, writeStatus(path, status)) {
+		return
+	}
+	read, err := ReadStatus(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, status, *read)
+	}
+
+	// No status file configured.
+	assert.NoError(t, writeStatus("", status))
+}
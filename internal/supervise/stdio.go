@@ -0,0 +1,114 @@
+package supervise
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/deref/exo/internal/util/sysutil"
+)
+
+// childStdio holds the files connecting a child to its supervisor.
+type childStdio struct {
+	// Output of the child, keyed by syslog MSGID.
+	outputs map[string]io.Reader
+	// Ends held by the child, which the supervisor closes once the child has
+	// started.
+	childEnds []*os.File
+	// Ends held by the supervisor. These are managed here, rather than with
+	// cmd.StdoutPipe and friends, so that they remain open for log collection
+	// after the child exits.
+	parentEnds []io.Closer
+	onClose    func()
+}
+
+func (stdio *childStdio) started() {
+	for _, f := range stdio.childEnds {
+		_ = f.Close()
+	}
+	stdio.childEnds = nil
+}
+
+func (stdio *childStdio) close() {
+	stdio.started()
+	if stdio.onClose != nil {
+		stdio.onClose()
+	}
+	for _, c := range stdio.parentEnds {
+		_ = c.Close()
+	}
+}
+
+func connectPipes(cmd *exec.Cmd) (*childStdio, error) {
+	stdio := &childStdio{
+		outputs: make(map[string]io.Reader),
+	}
+	for _, name := range []string{"out", "err"} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			stdio.close()
+			return nil, fmt.Errorf("creating std%s pipe: %w", name, err)
+		}
+		stdio.outputs[name] = r
+		stdio.parentEnds = append(stdio.parentEnds, r)
+		stdio.childEnds = append(stdio.childEnds, w)
+	}
+	cmd.Stdout = stdio.childEnds[0]
+	cmd.Stderr = stdio.childEnds[1]
+	return stdio, nil
+}
+
+// Runs the child in a new session, with a pseudo-terminal as its controlling
+// terminal and stdio. Terminal output is relayed to attach, if non-nil.
+func connectTTY(cmd *exec.Cmd, attach *attachServer) (*childStdio, error) {
+	master, slave, err := sysutil.OpenPty()
+	if err != nil {
+		return nil, fmt.Errorf("opening pty: %w", err)
+	}
+	cmd.Stdin = slave
+	cmd.Stdout = slave
+	cmd.Stderr = slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid:  true,
+		Setctty: true,
+		Ctty:    0, // Stdin.
+	}
+
+	logR, logW := io.Pipe()
+	stdio := &childStdio{
+		outputs:    map[string]io.Reader{"out": logR},
+		childEnds:  []*os.File{slave},
+		parentEnds: []io.Closer{master, logR},
+	}
+	if attach != nil {
+		attach.setPty(master)
+		stdio.onClose = func() {
+			attach.setPty(nil)
+		}
+	}
+
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			// Once the child and any of its descendants have closed the terminal,
+			// reads fail with EIO on Linux, or return EOF on Darwin.
+			n, err := master.Read(buf)
+			if n > 0 {
+				output := buf[:n]
+				if attach != nil {
+					_, _ = attach.Write(output)
+				}
+				// Terminals translate newlines to CRLF, which is unwanted in logs.
+				_, _ = logW.Write(bytes.ReplaceAll(output, []byte("\r\n"), []byte("\n")))
+			}
+			if err != nil {
+				_ = logW.Close()
+				return
+			}
+		}
+	}()
+	return stdio, nil
+}
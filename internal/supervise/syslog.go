@@ -0,0 +1,123 @@
+package supervise
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/deref/exo/internal/chrono"
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/logparse"
+	"github.com/deref/exo/internal/syslogd"
+	"github.com/influxdata/go-syslog/v3/rfc5424"
+)
+
+// syslogWriter sends messages to the log collector. Messages are numbered
+// per MSGID, so that the collector can count messages lost in transit.
+//
+// Over stream transports, messages are framed with octet counting, as
+// described in RFC 6587. Writes block while the collector is behind, which
+// in turn blocks the child once its stdio pipes are full.
+type syslogWriter struct {
+	network string
+	address string
+
+	mu   sync.Mutex
+	conn net.Conn
+	seqs map[string]uint64
+}
+
+func dialSyslog(cfg *Config) (*syslogWriter, error) {
+	w := &syslogWriter{
+		network: cfg.syslogNetwork(),
+		seqs:    make(map[string]uint64),
+	}
+	if w.network == "unix" {
+		w.address = cfg.SyslogSocket
+	} else {
+		w.address = syslogAddress(cfg.SyslogPort)
+	}
+	err := w.dial()
+	if err != nil && w.isStream() && cfg.SyslogPort != 0 {
+		// Losing messages is preferable to not running the child at all.
+		log.Printf("%v; falling back to udp", err)
+		w.network = "udp"
+		w.address = syslogAddress(cfg.SyslogPort)
+		err = w.dial()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func syslogAddress(port uint) string {
+	return net.JoinHostPort(syslogd.LoopbackHost, strconv.FormatUint(uint64(port), 10))
+}
+
+func (w *syslogWriter) dial() error {
+	conn, err := net.Dial(w.network, w.address)
+	if err != nil {
+		return fmt.Errorf("dialing %s: %w", w.network, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.Close()
+}
+
+func (w *syslogWriter) isStream() bool {
+	return w.network != "udp"
+}
+
+// Sends a message. If partial is true, the message is continued by the next
+// message sent with the same msgID.
+func (w *syslogWriter) Send(ctx context.Context, componentID string, msgID string, procID string, message string, format logparse.Format, partial bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seqs[msgID]++
+	sm := &rfc5424.SyslogMessage{}
+	sm.SetVersion(1)
+	sm.SetPriority(syslogPriority)
+	sm.SetTimestamp(chrono.Now(ctx).Format(chrono.RFC3339MicroUTC))
+	sm.SetAppname(componentID)
+	sm.SetProcID(procID)
+	sm.SetMsgID(msgID) // See note: [SYSLOG_MSG_ID].
+	sm.SetParameter(api.LogSDID, api.LogSeqSDParam, strconv.FormatUint(w.seqs[msgID], 10))
+	if partial {
+		sm.SetParameter(api.LogSDID, api.LogPartialSDParam, "true")
+	}
+	if format != logparse.FormatPlain {
+		sm.SetParameter(api.LogSDID, api.LogFormatSDParam, string(format))
+	}
+	sm.SetMessage(message)
+	packet, err := sm.String()
+	if err != nil {
+		fatalf("building syslog message: %v", err)
+	}
+	if w.isStream() {
+		packet = fmt.Sprintf("%d %s", len(packet), packet)
+	}
+
+	_, err = io.WriteString(w.conn, packet)
+	if err == nil || !w.isStream() {
+		return err
+	}
+	// The collector may have restarted. Reconnect and try once more. A partially
+	// written frame is abandoned along with the old connection.
+	_ = w.conn.Close()
+	if err := w.dial(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w.conn, packet)
+	return err
+}
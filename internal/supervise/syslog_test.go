@@ -0,0 +1,94 @@
+package supervise
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/logparse"
+	"github.com/influxdata/go-syslog/v3/rfc5424"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeToSyslogLongLines(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	w := &syslogWriter{
+		network: "tcp",
+		address: listener.Addr().String(),
+		seqs:    make(map[string]uint64),
+	}
+	require.NoError(t, w.dial())
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	long := strings.Repeat("x", api.MaxMessageSize+10)
+	input := "short\n\n" + long + "\nlast"
+	go func() {
+		pipeToSyslog(context.Background(), w, "component", "out", "123", logparse.FormatPlain, strings.NewReader(input))
+		w.Close()
+	}()
+
+	type message struct {
+		Seq     string
+		Partial string
+		Length  int
+	}
+	var messages []message
+	r := bufio.NewReader(conn)
+	machine := rfc5424.NewMachine()
+	for {
+		var size int
+		if _, err := fmt.Fscanf(r, "%d ", &size); err == io.EOF {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+		frame := make([]byte, size)
+		_, err := io.ReadFull(r, frame)
+		require.NoError(t, err)
+		parsed, err := machine.Parse(frame)
+		require.NoError(t, err)
+		sm := parsed.(*rfc5424.SyslogMessage)
+		params := (*sm.StructuredData)[api.LogSDID]
+		length := 0
+		if sm.Message != nil {
+			length = len(*sm.Message)
+		}
+		messages = append(messages, message{params[api.LogSeqSDParam], params[api.LogPartialSDParam], length})
+	}
+
+	assert.Equal(t, []message{
+		{"1", "", 5},
+		{"2", "true", api.MaxMessageSize},
+		{"3", "", 10},
+		{"4", "", 4},
+	}, messages)
+}
+
+func TestDialSyslogFallback(t *testing.T) {
+	cfg := &Config{
+		SyslogNetwork: "unix",
+		SyslogSocket:  filepath.Join(t.TempDir(), "missing.sock"),
+		SyslogPort:    43550,
+	}
+	w, err := dialSyslog(cfg)
+	require.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, "udp", w.network)
+	assert.Equal(t, "127.0.0.1:43550", w.address)
+
+	cfg.SyslogPort = 0
+	_, err = dialSyslog(cfg)
+	assert.Error(t, err)
+}
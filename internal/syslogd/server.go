@@ -1,37 +1,102 @@
 package syslogd
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/deref/exo/internal/chrono"
 	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/logparse"
 	"github.com/deref/exo/internal/util/logging"
 	"github.com/influxdata/go-syslog/v3"
 	"github.com/influxdata/go-syslog/v3/rfc5424"
 )
 
-// Server implements a UDP-based Syslog server.
+// Server implements a Syslog server. Messages are received as UDP packets, or
+// over TCP or Unix domain socket connections with octet-counting framing, as
+// described in RFC 6587. Stream connections are read no faster than events can
+// be stored, which applies backpressure to senders.
 type Server struct {
 	Logger     logging.Logger
 	SyslogPort uint
+	// If non-empty, path of a Unix domain socket to listen on, in addition to
+	// the syslog port.
+	SocketPath string
 	api.Store
+
+	mu      sync.Mutex
+	streams map[streamKey]*streamState
+}
+
+// Identifies a sequence of messages from a single sender. See NOTE
+// [SYSLOG_MSG_ID].
+type streamKey struct {
+	AppName string
+	MsgID   string
 }
 
+type streamState struct {
+	// Sequence number of the last message received, or 0 if messages of this
+	// stream are not numbered.
+	LastSeq uint64
+	// Number of messages lost since the last event was added.
+	Dropped int
+	// Message accumulating partial messages, if any.
+	Partial *rfc5424.SyslogMessage
+	// Connection over which the last message was received, or nil for UDP.
+	Conn net.Conn
+}
+
+// Address of the loopback interface on which the server listens, since all
+// senders are local. Senders must use the same address, rather than
+// "localhost", which may resolve to the IPv6 loopback address instead.
+const LoopbackHost = "127.0.0.1"
+
 func (svr *Server) Run(ctx context.Context) error {
-	addr := fmt.Sprintf(":%d", svr.SyslogPort)
+	addr := net.JoinHostPort(LoopbackHost, strconv.FormatUint(uint64(svr.SyslogPort), 10))
 	conn, err := net.ListenPacket("udp", addr)
 	if err != nil {
 		return fmt.Errorf("listening: %w", err)
 	}
+	defer conn.Close()
 	svr.Logger.Infof("listening for syslog at udp %s", addr)
 
-	errC := make(chan error, 1)
+	listeners := make([]net.Listener, 0, 2)
+	defer func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+	tcpListener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listening: %w", err)
+	}
+	listeners = append(listeners, tcpListener)
+	svr.Logger.Infof("listening for syslog at tcp %s", addr)
+	if svr.SocketPath != "" {
+		// Remove any socket left behind by a previous server.
+		if err := os.Remove(svr.SocketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing stale socket: %w", err)
+		}
+		unixListener, err := net.Listen("unix", svr.SocketPath)
+		if err != nil {
+			return fmt.Errorf("listening: %w", err)
+		}
+		listeners = append(listeners, unixListener)
+		svr.Logger.Infof("listening for syslog at unix %s", svr.SocketPath)
+	}
+
+	errC := make(chan error, 1+len(listeners))
 	go func() {
-		maxPacketSize := 8192 // RFC5425#section-4.3.1
+		maxPacketSize := 64 * 1024 // Max UDP payload.
 		buffer := make([]byte, maxPacketSize)
 		syslogMachine := rfc5424.NewMachine()
 		for {
@@ -40,22 +105,30 @@ func (svr *Server) Run(ctx context.Context) error {
 				errC <- err
 				return
 			}
-			syslogMessage, err := syslogMachine.Parse(buffer[:packetSize])
-			if err != nil {
-				svr.Logger.Infof("parsing syslog message: %v", err)
-				continue
-			}
-			event, err := syslogToEvent(syslogMessage)
-			if err != nil {
-				svr.Logger.Infof("interpreting syslog message: %v", err)
-				continue
-			}
-			if _, err := svr.AddEvent(ctx, event); err != nil {
-				errC <- fmt.Errorf("adding event: %w", err)
+			if err := svr.handleMessage(ctx, syslogMachine, nil, buffer[:packetSize]); err != nil {
+				errC <- err
 				return
 			}
 		}
 	}()
+	for _, listener := range listeners {
+		listener := listener
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					errC <- err
+					return
+				}
+				go func() {
+					defer conn.Close()
+					if err := svr.serveStream(ctx, conn); err != nil {
+						errC <- err
+					}
+				}()
+			}
+		}()
+	}
 
 	select {
 	case <-ctx.Done():
@@ -65,6 +138,182 @@ func (svr *Server) Run(ctx context.Context) error {
 	}
 }
 
+// Reads octet-counted messages from a stream connection until it is closed.
+// Only errors storing events are returned. Framing errors end the connection,
+// since the stream can not be resynchronized.
+func (svr *Server) serveStream(ctx context.Context, conn net.Conn) error {
+	defer svr.evictStreams(conn)
+	r := bufio.NewReader(conn)
+	syslogMachine := rfc5424.NewMachine()
+	for {
+		frame, err := readFrame(r)
+		if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			svr.Logger.Infof("reading syslog stream: %v", err)
+			return nil
+		}
+		if err := svr.handleMessage(ctx, syslogMachine, conn, frame); err != nil {
+			return err
+		}
+	}
+}
+
+// Maximum size of a framed message. Messages from the supervisor are at most
+// api.MaxMessageSize, plus headers.
+const maxFrameSize = 64 * 1024
+
+// Reads a frame of the form MSG-LEN SP SYSLOG-MSG.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	size := 0
+	for digits := 0; ; digits++ {
+		c, err := r.ReadByte()
+		if err == io.EOF && digits > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c == ' ' && digits > 0 {
+			break
+		}
+		if c < '0' || '9' < c {
+			return nil, fmt.Errorf("invalid frame length: unexpected %q", c)
+		}
+		size = size*10 + int(c-'0')
+		if size > maxFrameSize {
+			return nil, fmt.Errorf("frame exceeds max size of %d bytes", maxFrameSize)
+		}
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+// Parses a message and adds it as an event. Messages that can not be
+// interpreted are logged and skipped. Skipped messages are reflected in the
+// dropped count of their stream by the next message of the stream, if the
+// sender numbers its messages. The connection is nil for UDP packets.
+func (svr *Server) handleMessage(ctx context.Context, syslogMachine syslog.Machine, conn net.Conn, packet []byte) error {
+	syslogMessage, err := syslogMachine.Parse(packet)
+	if err != nil {
+		svr.Logger.Infof("parsing syslog message: %v", err)
+		return nil
+	}
+	rfc5424Message, ok := syslogMessage.(*rfc5424.SyslogMessage)
+	if !ok {
+		panic("unexpected syslog message type")
+	}
+	rfc5424Message, dropped := svr.sequence(conn, rfc5424Message)
+	if rfc5424Message == nil {
+		return nil
+	}
+	event, err := syslogToEvent(rfc5424Message)
+	if err != nil {
+		svr.Logger.Infof("interpreting syslog message: %v", err)
+		return nil
+	}
+	event.DroppedCount = dropped
+	if _, err := svr.AddEvent(ctx, event); err != nil {
+		return fmt.Errorf("adding event: %w", err)
+	}
+	return nil
+}
+
+// Tracks message sequence numbers and reassembles partial messages. Returns
+// the complete message, if any, along with the number of messages dropped
+// from the stream before it.
+func (svr *Server) sequence(conn net.Conn, message *rfc5424.SyslogMessage) (*rfc5424.SyslogMessage, int) {
+	if message.Appname == nil || message.MsgID == nil {
+		return message, 0
+	}
+	key := streamKey{
+		AppName: *message.Appname,
+		MsgID:   *message.MsgID,
+	}
+	seq, _ := strconv.ParseUint(sdParam(message, api.LogSeqSDParam), 10, 64)
+	partial := sdParam(message, api.LogPartialSDParam) == "true"
+
+	svr.mu.Lock()
+	defer svr.mu.Unlock()
+	if svr.streams == nil {
+		svr.streams = make(map[streamKey]*streamState)
+	}
+	state := svr.streams[key]
+	if state == nil {
+		state = &streamState{}
+		svr.streams[key] = state
+	}
+	state.Conn = conn
+
+	if seq != 0 {
+		switch {
+		case state.LastSeq == 0:
+			// First message seen from this sender.
+		case seq <= state.LastSeq:
+			// The sender has restarted. Any incomplete message is lost.
+			if state.Partial != nil {
+				state.Partial = nil
+				state.Dropped++
+			}
+		case seq > state.LastSeq+1:
+			state.Dropped += int(seq - state.LastSeq - 1)
+			// The remainder of an incomplete message may have been lost.
+			state.Partial = nil
+		}
+		state.LastSeq = seq
+	}
+
+	if state.Partial != nil {
+		combined := *state.Partial.Message
+		if message.Message != nil {
+			combined += *message.Message
+		}
+		if len(combined) > api.MaxLineSize {
+			combined = combined[:api.MaxLineSize]
+		}
+		state.Partial.Message = &combined
+		message = state.Partial
+	}
+	if partial {
+		if message.Message == nil {
+			message.SetMessage("")
+		}
+		state.Partial = message
+		return nil, 0
+	}
+	state.Partial = nil
+	dropped := state.Dropped
+	state.Dropped = 0
+	return message, dropped
+}
+
+// Forgets the state of streams whose messages were last received over a
+// closed connection. Senders reconnect after restarting, so the state would
+// otherwise accumulate for the lifetime of the server.
+func (svr *Server) evictStreams(conn net.Conn) {
+	svr.mu.Lock()
+	defer svr.mu.Unlock()
+	for key, state := range svr.streams {
+		if state.Conn == conn {
+			delete(svr.streams, key)
+		}
+	}
+}
+
+func sdParam(message *rfc5424.SyslogMessage, param string) string {
+	if message.StructuredData == nil {
+		return ""
+	}
+	return (*message.StructuredData)[api.LogSDID][param]
+}
+
 // See supervise implementation for details on Syslog field usage.
 func syslogToEvent(syslogMessage syslog.Message) (*api.AddEventInput, error) {
 	rfc5425Message, ok := syslogMessage.(*rfc5424.SyslogMessage)
@@ -89,10 +338,13 @@ func syslogToEvent(syslogMessage syslog.Message) (*api.AddEventInput, error) {
 	// expect the MsgId field to signify which stdio stream the message comes
 	// from.  Docker, on the other hand, simply provides the appname again, which
 	// should be a random component ID that will be disjoint from any keywords we
-	// use here.
+	// use here. The "sys" MsgId marks events that the supervisor itself reports,
+	// such as restarts.
 	switch msgID {
 	case "out", "err":
 		tags["stdio"] = msgID
+	case "sys":
+		tags["source"] = "supervisor"
 	default:
 		if msgID != streamName {
 			return nil, fmt.Errorf("unexpected MSGID: %q", msgID)
@@ -104,10 +356,32 @@ func syslogToEvent(syslogMessage syslog.Message) (*api.AddEventInput, error) {
 		message = strings.TrimSuffix(*rfc5425Message.Message, "\n")
 	}
 
+	timestamp := *rfc5425Message.Timestamp
+	if format := logFormat(rfc5425Message); format != logparse.FormatPlain {
+		entry := logparse.Parse(format, message)
+		if !entry.Timestamp.IsZero() {
+			timestamp = entry.Timestamp
+		}
+		// Parsed fields must not override the tags assigned above.
+		for key, value := range entry.Fields {
+			if _, exists := tags[key]; !exists {
+				tags[key] = value
+			}
+		}
+	}
+
 	return &api.AddEventInput{
 		Stream:    streamName,
-		Timestamp: rfc5425Message.Timestamp.Format(chrono.RFC3339MicroUTC),
+		Timestamp: timestamp.UTC().Format(chrono.RFC3339MicroUTC),
 		Message:   message,
 		Tags:      tags,
 	}, nil
 }
+
+func logFormat(message *rfc5424.SyslogMessage) logparse.Format {
+	format, err := logparse.ParseFormat(sdParam(message, api.LogFormatSDParam))
+	if err != nil {
+		return logparse.FormatPlain
+	}
+	return format
+}
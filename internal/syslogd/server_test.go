@@ -0,0 +1,178 @@
+package syslogd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/deref/exo/internal/eventd/api"
+	"github.com/deref/exo/internal/util/logging"
+	"github.com/influxdata/go-syslog/v3/rfc5424"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseSyslog(t *testing.T, format string, message string) *api.AddEventInput {
+	sm := &rfc5424.SyslogMessage{}
+	sm.SetVersion(1)
+	sm.SetPriority(14)
+	sm.SetTimestamp("2021-10-01T12:00:00.000001Z")
+	sm.SetAppname("component")
+	sm.SetProcID("123")
+	sm.SetMsgID("err")
+	if format != "" {
+		sm.SetParameter(api.LogSDID, api.LogFormatSDParam, format)
+	}
+	sm.SetMessage(message)
+	packet, err := sm.String()
+	require.NoError(t, err)
+
+	parsed, err := rfc5424.NewMachine().Parse([]byte(packet))
+	require.NoError(t, err)
+	event, err := syslogToEvent(parsed)
+	require.NoError(t, err)
+	return event
+}
+
+func TestSyslogToEventStructured(t *testing.T) {
+	message := `{"level":"WARN","ts":"2021-10-01T11:59:59Z","msg":"héllo","path":"/a \"b\"","stdio":"spoofed"}`
+	event := parseSyslog(t, "json", message)
+	assert.Equal(t, "component", event.Stream)
+	assert.Equal(t, message, event.Message)
+	assert.Equal(t, "2021-10-01T11:59:59Z", event.Timestamp)
+	assert.Equal(t, map[string]string{
+		"level": "warn",
+		"path":  `/a "b"`,
+		"stdio": "err",
+	}, event.Tags)
+}
+
+func TestSyslogToEventPlain(t *testing.T) {
+	for _, format := range []string{"", "plain", "unknown"} {
+		event := parseSyslog(t, format, `level=warn`)
+		assert.Equal(t, "2021-10-01T12:00:00.000001Z", event.Timestamp)
+		assert.Equal(t, map[string]string{"stdio": "err"}, event.Tags)
+	}
+}
+
+type recordingStore struct {
+	api.Store
+	Events []*api.AddEventInput
+}
+
+func (sto *recordingStore) AddEvent(ctx context.Context, input *api.AddEventInput) (*api.AddEventOutput, error) {
+	sto.Events = append(sto.Events, input)
+	return &api.AddEventOutput{}, nil
+}
+
+func buildSyslog(t *testing.T, msgID string, seq int, partial bool, message string) []byte {
+	sm := &rfc5424.SyslogMessage{}
+	sm.SetVersion(1)
+	sm.SetPriority(14)
+	sm.SetTimestamp("2021-10-01T12:00:00.000001Z")
+	sm.SetAppname("component")
+	sm.SetProcID("123")
+	sm.SetMsgID(msgID)
+	sm.SetParameter(api.LogSDID, api.LogSeqSDParam, strconv.Itoa(seq))
+	if partial {
+		sm.SetParameter(api.LogSDID, api.LogPartialSDParam, "true")
+	}
+	sm.SetMessage(message)
+	packet, err := sm.String()
+	require.NoError(t, err)
+	return []byte(packet)
+}
+
+func TestHandleMessageSequence(t *testing.T) {
+	ctx := context.Background()
+	sto := &recordingStore{}
+	svr := &Server{
+		Logger: &logging.NopLogger{},
+		Store:  sto,
+	}
+	machine := rfc5424.NewMachine()
+	for _, packet := range [][]byte{
+		buildSyslog(t, "out", 1, false, "one"),
+		buildSyslog(t, "out", 2, true, "two "),
+		buildSyslog(t, "err", 1, false, "interleaved"),
+		buildSyslog(t, "out", 3, true, "long "),
+		buildSyslog(t, "out", 4, false, "line"),
+		// Lost: 5, 6.
+		buildSyslog(t, "out", 7, true, "lost "),
+		// Lost: 8, the remainder of the partial message.
+		buildSyslog(t, "out", 9, false, "nine"),
+		// Sender restarted.
+		buildSyslog(t, "out", 1, false, "restarted"),
+	} {
+		require.NoError(t, svr.handleMessage(ctx, machine, nil, packet))
+	}
+
+	type result struct {
+		Stdio   string
+		Message string
+		Dropped int
+	}
+	var actual []result
+	for _, event := range sto.Events {
+		actual = append(actual, result{event.Tags["stdio"], event.Message, event.DroppedCount})
+	}
+	assert.Equal(t, []result{
+		{"out", "one", 0},
+		{"err", "interleaved", 0},
+		{"out", "two long line", 0},
+		{"out", "nine", 3},
+		{"out", "restarted", 0},
+	}, actual)
+}
+
+func TestServeStreamEvictsStreams(t *testing.T) {
+	ctx := context.Background()
+	sto := &recordingStore{}
+	svr := &Server{
+		Logger: &logging.NopLogger{},
+		Store:  sto,
+	}
+	client, server := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- svr.serveStream(ctx, server)
+	}()
+	for _, packet := range [][]byte{
+		buildSyslog(t, "out", 1, false, "one"),
+		buildSyslog(t, "err", 1, true, "partial"),
+	} {
+		_, err := fmt.Fprintf(client, "%d %s", len(packet), packet)
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.Close())
+	require.NoError(t, <-done)
+
+	assert.Len(t, sto.Events, 1)
+	assert.Empty(t, svr.streams)
+}
+
+func TestReadFrame(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("5 hello11 hello world0 "))
+	for _, expected := range []string{"hello", "hello world", ""} {
+		frame, err := readFrame(r)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, string(frame))
+		}
+	}
+	_, err := readFrame(r)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = readFrame(bufio.NewReader(strings.NewReader("5 hel")))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = readFrame(bufio.NewReader(strings.NewReader("<14>1 ...")))
+	assert.Error(t, err)
+
+	_, err = readFrame(bufio.NewReader(strings.NewReader("99999999 ")))
+	assert.Error(t, err)
+}
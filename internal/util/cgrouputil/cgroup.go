@@ -0,0 +1,250 @@
+// Package cgrouputil manages Linux cgroup v2 control groups, which limit and
+// account for the resources used by groups of processes.
+package cgrouputil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mount point of the unified cgroup v2 hierarchy.
+const Mount = "/sys/fs/cgroup"
+
+// Controllers enabled for groups created by this package.
+var controllers = []string{"cpu", "memory", "pids"}
+
+// Length of the period over which CPU quotas are enforced, in microseconds.
+const cpuPeriod = 100000
+
+// Smallest CPU quota accepted by the kernel, in microseconds.
+const minCPUQuota = 1000
+
+// Supported reports whether the host has a unified cgroup v2 hierarchy.
+func Supported() bool {
+	_, err := os.Stat(filepath.Join(Mount, "cgroup.controllers"))
+	return err == nil
+}
+
+// DefaultParent returns the directory in which to create cgroups for processes
+// started by the calling process. Cgroups that contain processes may not
+// delegate controllers to children, so groups are created alongside the
+// calling process's own cgroup, rather than within it.
+func DefaultParent() (string, error) {
+	bs, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	self, err := parseProcCgroup(bs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(Mount, path.Dir(self), "exo"), nil
+}
+
+// Returns the cgroup v2 path from the contents of /proc/<pid>/cgroup.
+func parseProcCgroup(bs []byte) (string, error) {
+	for _, line := range strings.Split(string(bs), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return strings.TrimPrefix(line, "0::"), nil
+		}
+	}
+	return "", errors.New("not a member of a cgroup v2 hierarchy")
+}
+
+// Limits of zero are unlimited.
+type Limits struct {
+	// Number of CPUs worth of time, which may be fractional.
+	CPUs float64 `json:"cpus,omitempty"`
+	// Bytes of memory, beyond which processes in the group are killed.
+	MemoryBytes int64 `json:"memoryBytes,omitempty"`
+	// Maximum number of processes and threads.
+	Pids int64 `json:"pids,omitempty"`
+}
+
+func (limits Limits) IsZero() bool {
+	return limits == Limits{}
+}
+
+type Group struct {
+	Dir string
+}
+
+// Create makes the cgroup at dir, and any missing ancestors, enabling the
+// required controllers along the way. Limits are then applied to the group.
+func Create(dir string, limits Limits) (*Group, error) {
+	if err := mkdirEnabled(dir); err != nil {
+		return nil, err
+	}
+	g := &Group{Dir: dir}
+	if err := g.SetLimits(limits); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func mkdirEnabled(dir string) error {
+	parent := filepath.Dir(dir)
+	if _, err := os.Stat(parent); os.IsNotExist(err) {
+		if err := mkdirEnabled(parent); err != nil {
+			return err
+		}
+	}
+	if err := enableControllers(parent); err != nil {
+		return err
+	}
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("creating cgroup: %w", err)
+	}
+	return nil
+}
+
+// Enables controllers for the children of the cgroup at dir. Writes are
+// avoided when the controllers are already enabled, since the parents of
+// delegated cgroups are usually not writable.
+func enableControllers(dir string) error {
+	subtreeControl := filepath.Join(dir, "cgroup.subtree_control")
+	bs, err := os.ReadFile(subtreeControl)
+	if err != nil {
+		return fmt.Errorf("reading enabled controllers: %w", err)
+	}
+	enabled := strings.Fields(string(bs))
+	var missing []string
+	for _, controller := range controllers {
+		if !contains(enabled, controller) {
+			missing = append(missing, "+"+controller)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(subtreeControl, []byte(strings.Join(missing, " ")), 0644); err != nil {
+		return fmt.Errorf("enabling controllers in %s: %w", dir, err)
+	}
+	return nil
+}
+
+func contains(xs []string, x string) bool {
+	for _, y := range xs {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Group) SetLimits(limits Limits) error {
+	cpuMax := "max"
+	if limits.CPUs > 0 {
+		quota := int(limits.CPUs * cpuPeriod)
+		if quota < minCPUQuota {
+			quota = minCPUQuota
+		}
+		cpuMax = strconv.Itoa(quota)
+	}
+	if err := g.write("cpu.max", fmt.Sprintf("%s %d", cpuMax, cpuPeriod)); err != nil {
+		return err
+	}
+	if err := g.write("memory.max", formatLimit(limits.MemoryBytes)); err != nil {
+		return err
+	}
+	// Like a container, the group is killed as a whole when it runs out of
+	// memory, rather than leaving it in a partially working state.
+	if err := g.write("memory.oom.group", "1"); err != nil {
+		return err
+	}
+	return g.write("pids.max", formatLimit(limits.Pids))
+}
+
+func formatLimit(n int64) string {
+	if n <= 0 {
+		return "max"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// AddProcess moves a process in to the group. Processes subsequently started
+// by that process are also members of the group.
+func (g *Group) AddProcess(pid int) error {
+	return g.write("cgroup.procs", strconv.Itoa(pid))
+}
+
+// Remove deletes the group, which fails if it still contains processes.
+func (g *Group) Remove() error {
+	return os.Remove(g.Dir)
+}
+
+type Stats struct {
+	MemoryBytes uint64
+	CPUUsage    time.Duration
+	Pids        uint64
+	// Number of times processes in the group were killed by the OOM killer.
+	OOMKills uint64
+}
+
+func (g *Group) Stats() (Stats, error) {
+	var stats Stats
+	var err error
+	if stats.MemoryBytes, err = g.readUint("memory.current"); err != nil {
+		return stats, err
+	}
+	if stats.Pids, err = g.readUint("pids.current"); err != nil {
+		return stats, err
+	}
+	cpuStat, err := g.readKeyed("cpu.stat")
+	if err != nil {
+		return stats, err
+	}
+	stats.CPUUsage = time.Duration(cpuStat["usage_usec"]) * time.Microsecond
+	memoryEvents, err := g.readKeyed("memory.events")
+	if err != nil {
+		return stats, err
+	}
+	stats.OOMKills = memoryEvents["oom_kill"]
+	return stats, nil
+}
+
+func (g *Group) write(name string, value string) error {
+	if err := os.WriteFile(filepath.Join(g.Dir, name), []byte(value), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+func (g *Group) readUint(name string) (uint64, error) {
+	bs, err := os.ReadFile(filepath.Join(g.Dir, name))
+	if err != nil {
+		return 0, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return strconv.ParseUint(string(bytes.TrimSpace(bs)), 10, 64)
+}
+
+// Reads a file of space-separated key value pairs, one per line.
+func (g *Group) readKeyed(name string) (map[string]uint64, error) {
+	f, err := os.Open(filepath.Join(g.Dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	defer f.Close()
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		values[fields[0]] = n
+	}
+	return values, scanner.Err()
+}
@@ -0,0 +1,73 @@
+package cgrouputil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcCgroup(t *testing.T) {
+	self, err := parseProcCgroup([]byte("0::/user.slice/user-1000.slice/session-2.scope\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "/user.slice/user-1000.slice/session-2.scope", self)
+
+	_, err = parseProcCgroup([]byte("4:memory:/\n1:cpu:/\n"))
+	assert.Error(t, err)
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	bs, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(bs)
+}
+
+func TestCreate(t *testing.T) {
+	root := t.TempDir()
+	subtreeControl := filepath.Join(root, "cgroup.subtree_control")
+	require.NoError(t, os.WriteFile(subtreeControl, []byte("memory io\n"), 0644))
+
+	g, err := Create(filepath.Join(root, "c1"), Limits{
+		CPUs:        1.5,
+		MemoryBytes: 512 * 1024 * 1024,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "+cpu +pids", readFile(t, subtreeControl))
+	assert.Equal(t, "150000 100000", readFile(t, filepath.Join(g.Dir, "cpu.max")))
+	assert.Equal(t, "536870912", readFile(t, filepath.Join(g.Dir, "memory.max")))
+	assert.Equal(t, "max", readFile(t, filepath.Join(g.Dir, "pids.max")))
+
+	require.NoError(t, g.AddProcess(123))
+	assert.Equal(t, "123", readFile(t, filepath.Join(g.Dir, "cgroup.procs")))
+}
+
+func TestSetLimitsMinCPUQuota(t *testing.T) {
+	g := &Group{Dir: t.TempDir()}
+	require.NoError(t, g.SetLimits(Limits{CPUs: 0.001}))
+	assert.Equal(t, "1000 100000", readFile(t, filepath.Join(g.Dir, "cpu.max")))
+}
+
+func TestStats(t *testing.T) {
+	g := &Group{Dir: t.TempDir()}
+	files := map[string]string{
+		"memory.current": "4096\n",
+		"pids.current":   "3\n",
+		"cpu.stat":       "usage_usec 2500000\nuser_usec 2000000\nsystem_usec 500000\n",
+		"memory.events":  "low 0\nhigh 0\nmax 4\noom 1\noom_kill 1\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(g.Dir, name), []byte(content), 0644))
+	}
+	stats, err := g.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, Stats{
+		MemoryBytes: 4096,
+		CPUUsage:    2500 * time.Millisecond,
+		Pids:        3,
+		OOMKills:    1,
+	}, stats)
+}
@@ -0,0 +1,16 @@
+package sysutil
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// SetPtySize sets the window size of the terminal f, which signals SIGWINCH
+// to the terminal's foreground process group.
+func SetPtySize(f *os.File, rows, cols uint16) error {
+	return unix.IoctlSetWinsize(int(f.Fd()), unix.TIOCSWINSZ, &unix.Winsize{
+		Row: rows,
+		Col: cols,
+	})
+}
@@ -0,0 +1,34 @@
+package term
+
+import (
+	"math"
+	"strings"
+)
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a row of bars, scaled so that the largest value
+// is a full bar. The line is scaled from zero, rather than from the smallest
+// value, so that steady values are not exaggerated in to large swings. NaN
+// values are rendered as gaps.
+func Sparkline(values []float64) string {
+	var max float64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			sb.WriteRune(' ')
+		case max <= 0 || v <= 0:
+			sb.WriteRune(sparkBars[0])
+		default:
+			i := int(math.Round(v / max * float64(len(sparkBars)-1)))
+			sb.WriteRune(sparkBars[i])
+		}
+	}
+	return sb.String()
+}
@@ -3,6 +3,6 @@
 set -ex
 
 which exo && exo exit || true
-go build -o ./bin/exo
+go build -tags sqlite_fts5 -o ./bin/exo
 mkdir -p ~/.exo/bin
 cp ./bin/exo ~/.exo/bin/exo
@@ -96,6 +96,17 @@ func (ctrl *ResourceControllerAdapater[Model]) DeleteResource(ctx context.Contex
 	return err
 }
 
+// Returns a not-implemented error if the adapted controller is not a
+// ResourceLocator.
+func (ctrl *ResourceControllerAdapater[Model]) LocateResource(ctx context.Context, cfg *ResourceConfig, iri string, model *RawJSON) error {
+	locator, ok := ctrl.impl.(ResourceLocator[Model])
+	if !ok {
+		return NewNotImplementedErrorf("%s resources cannot be adopted", cfg.Type)
+	}
+	_, err := callAdapted[Model](ctx, locator.LocateResource, cfg, iri, modelAdapter{"model", model})
+	return err
+}
+
 type modelAdapter struct {
 	Label string
 	Model *RawJSON
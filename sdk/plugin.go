@@ -0,0 +1,248 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/errutil"
+	"github.com/deref/exo/internal/util/httputil"
+	"github.com/deref/exo/internal/util/logging"
+)
+
+// Plugins are executables that serve component and resource controllers to
+// exo over a unix socket. Exo starts each configured plugin with the path of
+// the socket to listen on in this environment variable.
+const PluginSocketEnvVar = "EXO_PLUGIN_SOCKET"
+
+// Plugin serves controllers keyed by qualified type names, such as
+// "example.com/widgets/widget". Resource controllers are also made available
+// as component controllers by exo.
+type Plugin struct {
+	Components map[string]AComponentController
+	Resources  map[string]AResourceController
+}
+
+// ServePlugin is the main entrypoint of a plugin executable. It serves the
+// plugin's controllers until the process is killed.
+func ServePlugin(p *Plugin) {
+	if err := p.ListenAndServe(os.Getenv(PluginSocketEnvVar)); err != nil {
+		fmt.Fprintf(os.Stderr, "plugin error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (p *Plugin) ListenAndServe(socketPath string) error {
+	if socketPath == "" {
+		return fmt.Errorf("%s not set", PluginSocketEnvVar)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listening: %w", err)
+	}
+	srv := &http.Server{
+		Handler: p,
+		BaseContext: func(net.Listener) context.Context {
+			return logging.ContextWithLogger(context.Background(), logging.Default())
+		},
+	}
+	return srv.Serve(listener)
+}
+
+func (p *Plugin) Describe() PluginDescription {
+	var desc PluginDescription
+	for typ := range p.Components {
+		desc.ComponentTypes = append(desc.ComponentTypes, typ)
+	}
+	for typ := range p.Resources {
+		desc.ResourceTypes = append(desc.ResourceTypes, typ)
+	}
+	sort.Strings(desc.ComponentTypes)
+	sort.Strings(desc.ResourceTypes)
+	return desc
+}
+
+func (p *Plugin) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		httputil.WriteError(w, req, errutil.HTTPErrorf(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	method := strings.TrimPrefix(req.URL.Path, "/")
+	if method == "describe" {
+		httputil.WriteJSON(w, req, http.StatusOK, p.Describe())
+		return
+	}
+
+	var input PluginInput
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		httputil.WriteError(w, req, errutil.HTTPErrorf(http.StatusBadRequest, "decoding input: %v", err))
+		return
+	}
+	output, err := p.invoke(req, method, &input)
+	if err != nil {
+		var httpErr errutil.HTTPError
+		if !errors.As(err, &httpErr) {
+			// Preserve the message, which would otherwise be hidden.
+			err = errutil.WithHTTPStatus(http.StatusInternalServerError, err)
+		}
+		httputil.WriteError(w, req, err)
+		return
+	}
+	httputil.WriteJSON(w, req, http.StatusOK, output)
+}
+
+func (p *Plugin) invoke(req *http.Request, method string, input *PluginInput) (*PluginOutput, error) {
+	ctx := req.Context()
+	output := &PluginOutput{
+		Model: input.Model,
+	}
+	if strings.HasSuffix(method, "-resource") {
+		ctrl := p.Resources[input.Type]
+		if ctrl == nil {
+			return nil, errutil.HTTPErrorf(http.StatusNotFound, "no resource controller for type: %q", input.Type)
+		}
+		cfg := input.ResourceConfig
+		if cfg == nil {
+			return nil, errutil.HTTPErrorf(http.StatusBadRequest, "resource config required")
+		}
+		var err error
+		switch method {
+		case "identify-resource":
+			output.IRI, err = ctrl.IdentifyResource(ctx, cfg, &output.Model)
+		case "locate-resource":
+			locator, ok := ctrl.(AResourceLocator)
+			if !ok {
+				return nil, NewNotImplementedErrorf("%s resources cannot be adopted", input.Type)
+			}
+			err = locator.LocateResource(ctx, cfg, input.IRI, &output.Model)
+		case "create-resource":
+			err = ctrl.CreateResource(ctx, cfg, &output.Model)
+		case "read-resource":
+			err = ctrl.ReadResource(ctx, cfg, &output.Model)
+		case "update-resource":
+			output.Previous = input.Previous
+			err = ctrl.UpdateResource(ctx, cfg, &output.Previous, &output.Model)
+		case "shutdown-resource":
+			err = ctrl.ShutdownResource(ctx, cfg, &output.Model)
+		case "delete-resource":
+			err = ctrl.DeleteResource(ctx, cfg, &output.Model)
+		default:
+			return nil, errutil.HTTPErrorf(http.StatusNotFound, "unknown method: %q", method)
+		}
+		return output, err
+	}
+
+	ctrl := p.Components[input.Type]
+	if ctrl == nil {
+		return nil, errutil.HTTPErrorf(http.StatusNotFound, "no component controller for type: %q", input.Type)
+	}
+	if input.ComponentConfig == nil {
+		return nil, errutil.HTTPErrorf(http.StatusBadRequest, "component config required")
+	}
+	cfg, err := input.ComponentConfig.decode()
+	if err != nil {
+		return nil, errutil.HTTPErrorf(http.StatusBadRequest, "decoding component config: %v", err)
+	}
+	switch method {
+	case "render-component":
+		output.Children, err = ctrl.RenderComponent(ctx, cfg, &output.Model)
+	case "refresh-component":
+		err = ctrl.RefreshComponent(ctx, cfg, &output.Model)
+	case "component-updated":
+		err = ctrl.ComponentUpdated(ctx, cfg, &output.Model)
+	case "children-updated":
+		err = ctrl.ChildrenUpdated(ctx, cfg, &output.Model)
+	case "shutdown-component":
+		err = ctrl.ShutdownComponent(ctx, cfg, &output.Model)
+	case "delete-component":
+		err = ctrl.DeleteComponent(ctx, cfg, &output.Model)
+	default:
+		return nil, errutil.HTTPErrorf(http.StatusNotFound, "unknown method: %q", method)
+	}
+	return output, err
+}
+
+type PluginDescription struct {
+	ComponentTypes []string `json:"componentTypes"`
+	ResourceTypes  []string `json:"resourceTypes"`
+}
+
+// Input to all plugin controller methods. The method is identified by the
+// request path, such as "/render-component".
+type PluginInput struct {
+	Type            string                 `json:"type"`
+	ComponentConfig *PluginComponentConfig `json:"componentConfig,omitempty"`
+	ResourceConfig  *ResourceConfig        `json:"resourceConfig,omitempty"`
+	Model           RawJSON                `json:"model"`
+	// Only used by update-resource.
+	Previous RawJSON `json:"previous,omitempty"`
+	// Only used by locate-resource.
+	IRI string `json:"iri,omitempty"`
+}
+
+// Controllers may mutate their models, so updated models are always returned.
+type PluginOutput struct {
+	Model    RawJSON             `json:"model"`
+	Previous RawJSON             `json:"previous,omitempty"`
+	Children []RenderedComponent `json:"children,omitempty"`
+	IRI      string              `json:"iri,omitempty"`
+}
+
+// Wire representation of ComponentConfig. Cue values do not survive a JSON
+// round trip, so the spec is transmitted as JSON.
+type PluginComponentConfig struct {
+	ID          string                             `json:"id"`
+	Type        string                             `json:"type"`
+	Name        string                             `json:"name"`
+	Spec        RawJSON                            `json:"spec"`
+	Model       RawJSON                            `json:"model"`
+	Run         bool                               `json:"run"`
+	Environment map[string]string                  `json:"environment"`
+	Resources   map[string]ComponentConfigResource `json:"resources"`
+}
+
+func EncodePluginComponentConfig(cfg *ComponentConfig) (*PluginComponentConfig, error) {
+	spec, err := cfg.RawSpec.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshalling spec: %w", err)
+	}
+	return &PluginComponentConfig{
+		ID:          cfg.ID,
+		Type:        cfg.Type,
+		Name:        cfg.Name,
+		Spec:        spec,
+		Model:       cfg.RawModel,
+		Run:         cfg.Run,
+		Environment: cfg.Environment,
+		Resources:   cfg.Resources,
+	}, nil
+}
+
+func (cfg *PluginComponentConfig) decode() (*ComponentConfig, error) {
+	var spec cue.Value
+	if len(cfg.Spec) > 0 {
+		spec = cuecontext.New().CompileBytes(cfg.Spec)
+		if err := spec.Err(); err != nil {
+			return nil, fmt.Errorf("compiling spec: %w", err)
+		}
+	}
+	return &ComponentConfig{
+		ID:          cfg.ID,
+		Type:        cfg.Type,
+		Name:        cfg.Name,
+		RawSpec:     spec,
+		RawModel:    cfg.Model,
+		Run:         cfg.Run,
+		Environment: cfg.Environment,
+		Resources:   cfg.Resources,
+	}, nil
+}
@@ -2,10 +2,15 @@ package sdk
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/deref/exo/internal/api"
 	. "github.com/deref/exo/internal/scalars"
+	"github.com/deref/exo/internal/util/errutil"
 )
 
 type AResourceController = ResourceController[RawJSON]
@@ -14,11 +19,25 @@ type ResourceController[Model any] interface {
 	IdentifyResource(context.Context, *ResourceConfig, *Model) (string, error)
 	CreateResource(context.Context, *ResourceConfig, *Model) error
 	ReadResource(context.Context, *ResourceConfig, *Model) error
+	// Also starts a resource that has been shut down, even if the model is
+	// unchanged.
 	UpdateResource(ctx context.Context, cfg *ResourceConfig, prev *Model, next *Model) error
+	// Stops a resource without deleting it, so that it may be started again by
+	// a later update.
 	ShutdownResource(context.Context, *ResourceConfig, *Model) error
 	DeleteResource(context.Context, *ResourceConfig, *Model) error
 }
 
+// Optionally implemented by resource controllers that support adoption of
+// pre-existing external resources. Given an IRI, or a controller-specific
+// shorthand such as a name or ID, LocateResource initializes a model that is
+// sufficient for IdentifyResource and ReadResource.
+type ResourceLocator[Model any] interface {
+	LocateResource(ctx context.Context, cfg *ResourceConfig, iri string, model *Model) error
+}
+
+type AResourceLocator = ResourceLocator[RawJSON]
+
 type ResourceConfig struct {
 	ID   string  `json:"id"`
 	Type string  `json:"type"`
@@ -38,10 +57,43 @@ func NewResourceComponentController[Model any](svc api.Service, impl ResourceCon
 	}
 }
 
+func (ctrl *ResourceComponentController) LocateResource(ctx context.Context, cfg *ResourceConfig, iri string, model *RawJSON) error {
+	locator, ok := ctrl.AResourceController.(AResourceLocator)
+	if !ok {
+		return NewNotImplementedErrorf("%s resources cannot be adopted", cfg.Type)
+	}
+	return locator.LocateResource(ctx, cfg, iri, model)
+}
+
+// Creates or updates the resource owned by the component, starting it if it
+// had been shut down. The desired resource model is the component's spec. If the component owns no resource,
+// but the desired resource is known to exo and was owned by a component that
+// has since been disposed, the resource is transferred to this component
+// instead of being created anew.
 func (ctrl *ResourceComponentController) ComponentUpdated(ctx context.Context, cfg *ComponentConfig, model *RawJSON) error {
+	desired, err := cfg.RawSpec.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("marshalling spec: %w", err)
+	}
+	*model = desired
+
 	switch len(cfg.Resources) {
 
 	case 0:
+		ref, err := ctrl.findTransferableResource(ctx, cfg, desired)
+		if err != nil {
+			return err
+		}
+		if ref != "" {
+			if err := ctrl.transferResource(ctx, ref, cfg.ID); err != nil {
+				return fmt.Errorf("transferring resource: %w", err)
+			}
+			return ctrl.updateResource(ctx, ref, desired)
+		}
+		desiredObj, err := unmarshalModel(desired)
+		if err != nil {
+			return err
+		}
 		var m struct {
 			Resource struct {
 				ID string
@@ -49,17 +101,152 @@ func (ctrl *ResourceComponentController) ComponentUpdated(ctx context.Context, c
 		}
 		return api.Mutate(ctx, ctrl.service, &m, map[string]any{
 			"type":      cfg.Type,
-			"model":     model,
+			"model":     desiredObj,
 			"component": cfg.ID,
 		})
 
 	case 1:
-		panic("TODO: update component resource")
+		for _, resource := range cfg.Resources {
+			return ctrl.updateResource(ctx, resource.ID, desired)
+		}
+		panic("unreachable")
 
 	default:
-		panic("TODO: handle transitions, report conflicts")
+		ids := make([]string, 0, len(cfg.Resources))
+		for id := range cfg.Resources {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return errutil.HTTPErrorf(http.StatusConflict,
+			"component %q owns %d resources, expected at most one: %s",
+			cfg.Name, len(ids), strings.Join(ids, ", "))
+
+	}
+}
+
+// Returns the ID of an existing resource with the same IRI as the desired
+// model, if that resource is not owned by a live component. Returns an error
+// if another live component owns it.
+func (ctrl *ResourceComponentController) findTransferableResource(ctx context.Context, cfg *ComponentConfig, desired RawJSON) (string, error) {
+	// Identification may mutate the model, so use a copy.
+	model := append(RawJSON(nil), desired...)
+	iri, err := ctrl.IdentifyResource(ctx, &ResourceConfig{Type: cfg.Type}, &model)
+	iri = strings.TrimSpace(iri)
+	if err != nil || iri == "" {
+		// Not identifiable until created.
+		return "", nil
+	}
+
+	var q struct {
+		Resource *struct {
+			ID        string
+			Component *struct {
+				ID       string
+				Name     string
+				Disposed *string
+			}
+		} `graphql:"resourceByRef(ref: $ref)"`
+	}
+	if err := api.Query(ctx, ctrl.service, &q, map[string]any{
+		"ref": iri,
+	}); err != nil {
+		return "", fmt.Errorf("querying resource %q: %w", iri, err)
+	}
+	resource := q.Resource
+	if resource == nil {
+		return "", nil
+	}
+	if owner := resource.Component; owner != nil && owner.ID != cfg.ID && owner.Disposed == nil {
+		return "", errutil.HTTPErrorf(http.StatusConflict,
+			"resource %q is already owned by component %q", iri, owner.Name)
+	}
+	return resource.ID, nil
+}
+
+func (ctrl *ResourceComponentController) transferResource(ctx context.Context, ref string, component string) error {
+	var m struct {
+		Resource struct {
+			ID string
+		} `graphql:"transferResource(ref: $ref, component: $component)"`
+	}
+	return api.Mutate(ctx, ctrl.service, &m, map[string]any{
+		"ref":       ref,
+		"component": component,
+	})
+}
+
+func (ctrl *ResourceComponentController) updateResource(ctx context.Context, ref string, model RawJSON) error {
+	modelObj, err := unmarshalModel(model)
+	if err != nil {
+		return err
+	}
+	var m struct {
+		Resource struct {
+			ID string
+		} `graphql:"updateResource(ref: $ref, model: $model)"`
+	}
+	return api.Mutate(ctx, ctrl.service, &m, map[string]any{
+		"ref":   ref,
+		"model": modelObj,
+	})
+}
+
+// Models are transmitted to GraphQL as JSONObject scalars.
+func unmarshalModel(model RawJSON) (JSONObject, error) {
+	var obj JSONObject
+	if err := json.Unmarshal(model, &obj); err != nil {
+		return nil, fmt.Errorf("unmarshalling model: %w", err)
+	}
+	return obj, nil
+}
 
+func (ctrl *ResourceComponentController) refreshResource(ctx context.Context, ref string) error {
+	var m struct {
+		Resource struct {
+			ID string
+		} `graphql:"refreshResource(ref: $ref)"`
 	}
+	return api.Mutate(ctx, ctrl.service, &m, map[string]any{
+		"ref": ref,
+	})
+}
+
+func (ctrl *ResourceComponentController) shutdownResource(ctx context.Context, ref string) error {
+	var m struct {
+		Resource struct {
+			ID string
+		} `graphql:"shutdownResource(ref: $ref)"`
+	}
+	return api.Mutate(ctx, ctrl.service, &m, map[string]any{
+		"ref": ref,
+	})
+}
+
+// Shuts down and deletes a resource, then forgets it.
+func (ctrl *ResourceComponentController) disposeResource(ctx context.Context, ref string) error {
+	var m struct {
+		Void struct {
+			Typename string `graphql:"__typename"`
+		} `graphql:"disposeResource(ref: $ref)"`
+	}
+	return api.Mutate(ctx, ctrl.service, &m, map[string]any{
+		"ref": ref,
+	})
+}
+
+// Invokes f on each of the component's resources, in a stable order.
+func (ctrl *ResourceComponentController) eachResource(cfg *ComponentConfig, f func(ref string) error) error {
+	ids := make([]string, 0, len(cfg.Resources))
+	for id := range cfg.Resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := f(id); err != nil {
+			return fmt.Errorf("resource %s: %w", id, err)
+		}
+	}
+	return nil
 }
 
 func (c *ResourceComponentController) RenderComponent(ctx context.Context, cfg *ComponentConfig, model *RawJSON) (children []RenderedComponent, err error) {
@@ -68,7 +255,9 @@ func (c *ResourceComponentController) RenderComponent(ctx context.Context, cfg *
 }
 
 func (ctrl *ResourceComponentController) RefreshComponent(ctx context.Context, cfg *ComponentConfig, model *RawJSON) error {
-	return errors.New("TODO: Trigger refresh of resources")
+	return ctrl.eachResource(cfg, func(ref string) error {
+		return ctrl.refreshResource(ctx, ref)
+	})
 }
 
 func (ctrl *ResourceComponentController) ChildrenUpdated(ctx context.Context, cfg *ComponentConfig, model *RawJSON) error {
@@ -76,10 +265,18 @@ func (ctrl *ResourceComponentController) ChildrenUpdated(ctx context.Context, cf
 	return nil
 }
 
-func (c *ResourceComponentController) ShutdownComponent(ctx context.Context, cfg *ComponentConfig, model *RawJSON) (err error) {
-	return errors.New("TODO: delegate to shutdown of resources")
+// Resources are retained by shutdown, so that reconciling the component
+// starts them again.
+func (ctrl *ResourceComponentController) ShutdownComponent(ctx context.Context, cfg *ComponentConfig, model *RawJSON) error {
+	return ctrl.eachResource(cfg, func(ref string) error {
+		return ctrl.shutdownResource(ctx, ref)
+	})
 }
 
+// Disposes of the component's resources. The disposeResource mutation
+// performs both a graceful shutdown and a deletion.
 func (ctrl *ResourceComponentController) DeleteComponent(ctx context.Context, cfg *ComponentConfig, model *RawJSON) error {
-	return errors.New("TODO: delegate to deletion of resources")
+	return ctrl.eachResource(cfg, func(ref string) error {
+		return ctrl.disposeResource(ctx, ref)
+	})
 }